@@ -16,20 +16,25 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"text/tabwriter"
 	"text/template"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api"
+	"github.com/docker/docker/api/stats"
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/graph"
 	"github.com/docker/docker/nat"
 	"github.com/docker/docker/opts"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/fileutils"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/parsers/filters"
@@ -46,10 +51,16 @@ import (
 
 const (
 	tarHeaderSize = 512
+	// maxSymlinks is the number of symlinks followed by cp, as by the kernel
+	maxSymlinks = 40
 )
 
 var (
-	acceptedImageFilterTags = map[string]struct{}{"dangling": {}}
+	acceptedImageFilterTags = map[string]struct{}{
+		"dangling": {},
+		"label":    {},
+	}
+	acceptedVolumeFilterTags = map[string]struct{}{"dangling": {}}
 )
 
 func (cli *DockerCli) CmdHelp(args ...string) error {
@@ -83,6 +94,12 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	rm := cmd.Bool([]string{"#rm", "-rm"}, true, "Remove intermediate containers after a successful build")
 	forceRm := cmd.Bool([]string{"-force-rm"}, false, "Always remove intermediate containers, even after unsuccessful builds")
 	pull := cmd.Bool([]string{"-pull"}, false, "Always attempt to pull a newer version of the image")
+	squash := cmd.Bool([]string{"-squash"}, false, "Squash the layers of the build into a single new layer")
+	dockerfileName := cmd.String([]string{"f", "-file"}, "", "Name of the Dockerfile (Default is 'PATH/Dockerfile')")
+	flBuildArgs := opts.NewListOpts(nil)
+	cmd.Var(&flBuildArgs, []string{"-build-arg"}, "Set build-time variables")
+	flCacheFrom := opts.NewListOpts(nil)
+	cmd.Var(&flCacheFrom, []string{"-cache-from"}, "Images to consider as cache sources (image[,image])")
 	if err := cmd.Parse(args); err != nil {
 		return nil
 	}
@@ -112,7 +129,9 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 			if err != nil {
 				return fmt.Errorf("failed to read Dockerfile from STDIN: %v", err)
 			}
-			context, err = archive.Generate("Dockerfile", string(dockerfile))
+			// The Dockerfile read from STDIN is the only file of the context
+			*dockerfileName = ""
+			context, err = archive.Generate(api.DefaultDockerfileName, string(dockerfile))
 		} else {
 			context = ioutil.NopCloser(buf)
 		}
@@ -139,29 +158,44 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 		if _, err := os.Stat(root); err != nil {
 			return err
 		}
-		filename := path.Join(root, "Dockerfile")
-		if _, err = os.Stat(filename); os.IsNotExist(err) {
-			return fmt.Errorf("no Dockerfile found in %s", cmd.Arg(0))
-		}
-		var excludes []string
-		ignore, err := ioutil.ReadFile(path.Join(root, ".dockerignore"))
-		if err != nil && !os.IsNotExist(err) {
-			return fmt.Errorf("Error reading .dockerignore: '%s'", err)
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			return err
 		}
-		for _, pattern := range strings.Split(string(ignore), "\n") {
-			pattern = strings.TrimSpace(pattern)
-			if pattern == "" {
-				continue
+
+		// The Dockerfile given with -f is relative to the current directory,
+		// or to the root of the repository for git contexts
+		filename := path.Join(absRoot, api.DefaultDockerfileName)
+		if *dockerfileName != "" {
+			filename = *dockerfileName
+			if urlutil.IsGitURL(cmd.Arg(0)) && !filepath.IsAbs(filename) {
+				filename = filepath.Join(absRoot, filename)
 			}
-			pattern = filepath.Clean(pattern)
-			ok, err := filepath.Match(pattern, "Dockerfile")
-			if err != nil {
-				return fmt.Errorf("Bad .dockerignore pattern: '%s', error: %s", pattern, err)
+			if filename, err = filepath.Abs(filename); err != nil {
+				return err
 			}
-			if ok {
-				return fmt.Errorf("Dockerfile was excluded by .dockerignore pattern '%s'", pattern)
+		}
+		relDockerfile, err := filepath.Rel(absRoot, filename)
+		if err != nil || relDockerfile == ".." || strings.HasPrefix(relDockerfile, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("The Dockerfile (%s) must be within the build context (%s)", *dockerfileName, cmd.Arg(0))
+		}
+		if _, err = os.Stat(filename); os.IsNotExist(err) {
+			if *dockerfileName != "" {
+				return fmt.Errorf("Cannot locate Dockerfile: %s", *dockerfileName)
 			}
-			excludes = append(excludes, pattern)
+			return fmt.Errorf("no Dockerfile found in %s", cmd.Arg(0))
+		}
+		if *dockerfileName != "" {
+			*dockerfileName = filepath.ToSlash(relDockerfile)
+		}
+		excludes, err := utils.ReadDockerIgnore(path.Join(root, ".dockerignore"))
+		if err != nil {
+			return err
+		}
+		// Neither the Dockerfile nor one of its parent directories can be
+		// excluded
+		if excluded, _ := fileutils.Matches(relDockerfile, excludes); excluded {
+			return fmt.Errorf("Dockerfile was excluded by .dockerignore")
 		}
 		if err = utils.ValidateContextDirectory(root, excludes); err != nil {
 			return fmt.Errorf("Error checking context is accessible: '%s'. Please check permissions and try again.", err)
@@ -200,6 +234,10 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 
 	v.Set("t", *tag)
 
+	if *dockerfileName != "" {
+		v.Set("dockerfile", *dockerfileName)
+	}
+
 	if *suppressOutput {
 		v.Set("q", "1")
 	}
@@ -222,6 +260,48 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	if *pull {
 		v.Set("pull", "1")
 	}
+
+	if *squash {
+		v.Set("squash", "1")
+	}
+
+	if buildArgs := flBuildArgs.GetAll(); len(buildArgs) > 0 {
+		args := make(map[string]string, len(buildArgs))
+		for _, arg := range buildArgs {
+			parts := strings.SplitN(arg, "=", 2)
+			if len(parts) == 1 {
+				// The value is taken from the environment, the default
+				// of the ARG applies when it is not set there
+				value, found := syscall.Getenv(arg)
+				if !found {
+					continue
+				}
+				parts = append(parts, value)
+			}
+			args[parts[0]] = parts[1]
+		}
+		buf, err := json.Marshal(args)
+		if err != nil {
+			return err
+		}
+		v.Set("buildargs", string(buf))
+	}
+
+	if cacheFrom := flCacheFrom.GetAll(); len(cacheFrom) > 0 {
+		var images []string
+		for _, value := range cacheFrom {
+			for _, image := range strings.Split(value, ",") {
+				if image = strings.TrimSpace(image); image != "" {
+					images = append(images, image)
+				}
+			}
+		}
+		buf, err := json.Marshal(images)
+		if err != nil {
+			return err
+		}
+		v.Set("cachefrom", string(buf))
+	}
 	cli.LoadConfigFile()
 
 	headers := http.Header(make(map[string][]string))
@@ -627,6 +707,26 @@ func (cli *DockerCli) CmdRestart(args ...string) error {
 	return encounteredError
 }
 
+func (cli *DockerCli) CmdRename(args ...string) error {
+	cmd := cli.Subcmd("rename", "OLD_NAME NEW_NAME", "Rename a container")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 2 {
+		cmd.Usage()
+		return nil
+	}
+
+	oldName, newName := cmd.Arg(0), cmd.Arg(1)
+	v := url.Values{}
+	v.Set("name", newName)
+	if _, _, err := readBody(cli.call("POST", fmt.Sprintf("/containers/%s/rename?%s", oldName, v.Encode()), nil, false)); err != nil {
+		fmt.Fprintf(cli.err, "%s\n", err)
+		return fmt.Errorf("Error: failed to rename container named %s", oldName)
+	}
+	return nil
+}
+
 func (cli *DockerCli) forwardAllSignals(cid string) chan os.Signal {
 	sigc := make(chan os.Signal, 128)
 	signal.CatchAll(sigc)
@@ -793,6 +893,88 @@ func (cli *DockerCli) CmdUnpause(args ...string) error {
 	return encounteredError
 }
 
+// 'docker update': change the resource limits and the restart policy of containers
+func (cli *DockerCli) CmdUpdate(args ...string) error {
+	cmd := cli.Subcmd("update", "CONTAINER [CONTAINER...]", "Update the resource limits of one or more containers")
+	var (
+		flMemory            = cmd.String([]string{"m", "-memory"}, "", "Memory limit (format: <number><optional unit>, where unit = b, k, m or g)")
+		flMemoryReservation = cmd.String([]string{"-memory-reservation"}, "", "Memory soft limit (format: <number><optional unit>, where unit = b, k, m or g)")
+		flKernelMemory      = cmd.String([]string{"-kernel-memory"}, "", "Kernel memory limit (format: <number><optional unit>, where unit = b, k, m or g)")
+		flCpuShares         = cmd.Int64([]string{"c", "-cpu-shares"}, 0, "CPU shares (relative weight)")
+		flCpuPeriod         = cmd.Int64([]string{"-cpu-period"}, 0, "Limit the CPU CFS (Completely Fair Scheduler) period, in microseconds")
+		flCpuQuota          = cmd.Int64([]string{"-cpu-quota"}, 0, "Limit the CPU time in each CFS (Completely Fair Scheduler) period, in microseconds")
+		flCpuset            = cmd.String([]string{"-cpuset"}, "", "CPUs in which to allow execution (0-3, 0,1)")
+		flBlkioWeight       = cmd.Int64([]string{"-blkio-weight"}, 0, "Block IO weight (relative weight), between 10 and 1000")
+		flRestartPolicy     = cmd.String([]string{"-restart"}, "", "Restart policy to apply when a container exits (no, on-failure[:max-retry], always)")
+	)
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	update := map[string]interface{}{}
+	for key, value := range map[string]string{
+		"Memory":            *flMemory,
+		"MemoryReservation": *flMemoryReservation,
+		"KernelMemory":      *flKernelMemory,
+	} {
+		if value != "" {
+			bytes, err := units.RAMInBytes(value)
+			if err != nil {
+				return err
+			}
+			update[key] = bytes
+		}
+	}
+	for key, value := range map[string]int64{
+		"CpuShares":   *flCpuShares,
+		"CpuPeriod":   *flCpuPeriod,
+		"CpuQuota":    *flCpuQuota,
+		"BlkioWeight": *flBlkioWeight,
+	} {
+		if value != 0 {
+			update[key] = value
+		}
+	}
+	if *flCpuset != "" {
+		update["Cpuset"] = *flCpuset
+	}
+	if *flRestartPolicy != "" {
+		restartPolicy, err := runconfig.ParseRestartPolicy(*flRestartPolicy)
+		if err != nil {
+			return err
+		}
+		update["RestartPolicy"] = restartPolicy
+	}
+	if len(update) == 0 {
+		return fmt.Errorf("You must provide one or more flags when using this command.")
+	}
+
+	var encounteredError error
+	for _, name := range cmd.Args() {
+		stream, _, err := cli.call("POST", fmt.Sprintf("/containers/%s/update", name), update, false)
+		if err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			encounteredError = fmt.Errorf("Error: failed to update container named %s", name)
+			continue
+		}
+		var result engine.Env
+		err = result.Decode(stream)
+		stream.Close()
+		if err != nil {
+			return err
+		}
+		for _, warning := range result.GetList("Warnings") {
+			fmt.Fprintf(cli.err, "WARNING: %s\n", warning)
+		}
+		fmt.Fprintf(cli.out, "%s\n", name)
+	}
+	return encounteredError
+}
+
 func (cli *DockerCli) CmdPause(args ...string) error {
 	cmd := cli.Subcmd("pause", "CONTAINER", "Pause all processes within a container")
 	if err := cmd.Parse(args); err != nil {
@@ -1156,6 +1338,8 @@ func (cli *DockerCli) CmdKill(args ...string) error {
 
 func (cli *DockerCli) CmdImport(args ...string) error {
 	cmd := cli.Subcmd("import", "URL|- [REPOSITORY[:TAG]]", "Create an empty filesystem image and import the contents of the tarball (.tar, .tar.gz, .tgz, .bzip, .tar.xz, .txz) into it, then optionally tag it.")
+	flChanges := opts.NewListOpts(nil)
+	cmd.Var(&flChanges, []string{"c", "-change"}, "Apply Dockerfile instruction to the created image")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil
@@ -1173,6 +1357,9 @@ func (cli *DockerCli) CmdImport(args ...string) error {
 
 	v.Set("fromSrc", src)
 	v.Set("repo", repository)
+	for _, change := range flChanges.GetAll() {
+		v.Add("changes", change)
+	}
 
 	if cmd.NArg() == 3 {
 		fmt.Fprintf(cli.err, "[DEPRECATED] The format 'URL|- [REPOSITORY [TAG]]' as been deprecated. Please use URL|- [REPOSITORY[:TAG]]\n")
@@ -1262,7 +1449,7 @@ func (cli *DockerCli) CmdPush(args ...string) error {
 }
 
 func (cli *DockerCli) CmdPull(args ...string) error {
-	cmd := cli.Subcmd("pull", "NAME[:TAG]", "Pull an image or a repository from the registry")
+	cmd := cli.Subcmd("pull", "NAME[:TAG|@DIGEST]", "Pull an image or a repository from the registry")
 	allTags := cmd.Bool([]string{"a", "-all-tags"}, false, "Download all tagged images in the repository")
 	if err := cmd.Parse(args); err != nil {
 		return nil
@@ -1332,12 +1519,13 @@ func (cli *DockerCli) CmdImages(args ...string) error {
 	quiet := cmd.Bool([]string{"q", "-quiet"}, false, "Only show numeric IDs")
 	all := cmd.Bool([]string{"a", "-all"}, false, "Show all images (by default filter out the intermediate image layers)")
 	noTrunc := cmd.Bool([]string{"#notrunc", "-no-trunc"}, false, "Don't truncate output")
+	showDigests := cmd.Bool([]string{"-digests"}, false, "Show digests")
 	// FIXME: --viz and --tree are deprecated. Remove them in a future version.
 	flViz := cmd.Bool([]string{"#v", "#viz", "#-viz"}, false, "Output graph in graphviz format")
 	flTree := cmd.Bool([]string{"#t", "#tree", "#-tree"}, false, "Output graph in tree format")
 
 	flFilter := opts.NewListOpts(nil)
-	cmd.Var(&flFilter, []string{"f", "-filter"}, "Provide filter values (i.e. 'dangling=true')")
+	cmd.Var(&flFilter, []string{"f", "-filter"}, "Provide filter values (i.e. 'dangling=true', 'label=key=value')")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil
@@ -1469,22 +1657,26 @@ func (cli *DockerCli) CmdImages(args ...string) error {
 
 		w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
 		if !*quiet {
-			fmt.Fprintln(w, "REPOSITORY\tTAG\tIMAGE ID\tCREATED\tVIRTUAL SIZE")
+			if *showDigests {
+				fmt.Fprintln(w, "REPOSITORY\tTAG\tDIGEST\tIMAGE ID\tCREATED\tVIRTUAL SIZE")
+			} else {
+				fmt.Fprintln(w, "REPOSITORY\tTAG\tIMAGE ID\tCREATED\tVIRTUAL SIZE")
+			}
 		}
 
 		for _, out := range outs.Data {
-			for _, repotag := range out.GetList("RepoTags") {
-
-				repo, tag := parsers.ParseRepositoryTag(repotag)
-				outID := out.Get("Id")
-				if !*noTrunc {
-					outID = utils.TruncateID(outID)
-				}
+			outID := out.Get("Id")
+			if !*noTrunc {
+				outID = utils.TruncateID(outID)
+			}
 
-				if !*quiet {
-					fmt.Fprintf(w, "%s\t%s\t%s\t%s ago\t%s\n", repo, tag, outID, units.HumanDuration(time.Now().UTC().Sub(time.Unix(out.GetInt64("Created"), 0))), units.HumanSize(out.GetInt64("VirtualSize")))
-				} else {
+			for _, ref := range imageRefs(out.GetList("RepoTags"), out.GetList("RepoDigests"), *showDigests) {
+				if *quiet {
 					fmt.Fprintln(w, outID)
+				} else if *showDigests {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s ago\t%s\n", ref.repo, ref.tag, ref.digest, outID, units.HumanDuration(time.Now().UTC().Sub(time.Unix(out.GetInt64("Created"), 0))), units.HumanSize(out.GetInt64("VirtualSize")))
+				} else {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s ago\t%s\n", ref.repo, ref.tag, outID, units.HumanDuration(time.Now().UTC().Sub(time.Unix(out.GetInt64("Created"), 0))), units.HumanSize(out.GetInt64("VirtualSize")))
 				}
 			}
 		}
@@ -1496,6 +1688,54 @@ func (cli *DockerCli) CmdImages(args ...string) error {
 	return nil
 }
 
+// imageRef is a line of `docker images`.
+type imageRef struct {
+	repo, tag, digest string
+}
+
+// imageRefs returns the lines of an image in `docker images`: one for each
+// tag, and one for each repository in which the image only has digests. With
+// showDigests, a tag shows the digest of the image when it is the only one in
+// the repository, and the other digests have their own lines.
+func imageRefs(repoTags, repoDigests []string, showDigests bool) []imageRef {
+	var (
+		refs    []imageRef
+		repos   []string
+		digests = make(map[string][]string)
+		tagged  = make(map[string]bool)
+	)
+	for _, repoDigest := range repoDigests {
+		repo, digest := parsers.ParseRepositoryTag(repoDigest)
+		if _, exists := digests[repo]; !exists {
+			repos = append(repos, repo)
+		}
+		digests[repo] = append(digests[repo], digest)
+	}
+	for _, repoTag := range repoTags {
+		if repoTag == "<none>:<none>" && len(repoDigests) > 0 {
+			continue
+		}
+		repo, tag := parsers.ParseRepositoryTag(repoTag)
+		tagged[repo] = true
+		ref := imageRef{repo: repo, tag: tag, digest: "<none>"}
+		if len(digests[repo]) == 1 {
+			ref.digest = digests[repo][0]
+		}
+		refs = append(refs, ref)
+	}
+	for _, repo := range repos {
+		switch {
+		case !showDigests && !tagged[repo]:
+			refs = append(refs, imageRef{repo: repo, tag: "<none>", digest: "<none>"})
+		case showDigests && (!tagged[repo] || len(digests[repo]) > 1):
+			for _, digest := range digests[repo] {
+				refs = append(refs, imageRef{repo: repo, tag: "<none>", digest: digest})
+			}
+		}
+	}
+	return refs
+}
+
 // FIXME: --viz and --tree are deprecated. Remove them in a future version.
 func (cli *DockerCli) WalkTree(noTrunc bool, images *engine.Table, byParent map[string]*engine.Table, prefix string, printNode func(cli *DockerCli, noTrunc bool, image *engine.Env, prefix string)) {
 	length := images.Len()
@@ -1583,7 +1823,7 @@ func (cli *DockerCli) CmdPs(args ...string) error {
 		flFilter = opts.NewListOpts(nil)
 	)
 
-	cmd.Var(&flFilter, []string{"f", "-filter"}, "Provide filter values. Valid filters:\nexited=<int> - containers with exit code of <int>\nstatus=(restarting|running|paused|exited)")
+	cmd.Var(&flFilter, []string{"f", "-filter"}, "Provide filter values. Valid filters:\nexited=<int> - containers with exit code of <int>\nstatus=(restarting|running|paused|exited)\nlabel=<key> or label=<key>=<value> - containers with the given label")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil
@@ -1722,7 +1962,9 @@ func (cli *DockerCli) CmdCommit(args ...string) error {
 	flPause := cmd.Bool([]string{"p", "-pause"}, true, "Pause container during commit")
 	flComment := cmd.String([]string{"m", "-message"}, "", "Commit message")
 	flAuthor := cmd.String([]string{"a", "#author", "-author"}, "", "Author (e.g., \"John Hannibal Smith <hannibal@a-team.com>\")")
-	// FIXME: --run is deprecated, it will be replaced with inline Dockerfile commands.
+	flChanges := opts.NewListOpts(nil)
+	cmd.Var(&flChanges, []string{"c", "-change"}, "Apply Dockerfile instruction to the created image")
+	// FIXME: --run is deprecated, it is replaced by --change.
 	flConfig := cmd.String([]string{"#run", "#-run"}, "", "This option is deprecated and will be removed in a future version in favor of inline Dockerfile-compatible commands")
 	if err := cmd.Parse(args); err != nil {
 		return nil
@@ -1751,6 +1993,9 @@ func (cli *DockerCli) CmdCommit(args ...string) error {
 	v.Set("tag", tag)
 	v.Set("comment", *flComment)
 	v.Set("author", *flAuthor)
+	for _, change := range flChanges.GetAll() {
+		v.Add("changes", change)
+	}
 
 	if *flPause != true {
 		v.Set("pause", "0")
@@ -2199,7 +2444,7 @@ func (cli *DockerCli) createContainer(config *runconfig.Config, hostConfig *runc
 		if tag == "" {
 			tag = graph.DEFAULTTAG
 		}
-		fmt.Fprintf(cli.err, "Unable to find image '%s:%s' locally\n", repo, tag)
+		fmt.Fprintf(cli.err, "Unable to find image '%s' locally\n", utils.ImageReference(repo, tag))
 
 		// we don't want to write to stdout anything apart from container.ID
 		if err = cli.pullImageCustomOut(config.Image, cli.err); err != nil {
@@ -2457,7 +2702,7 @@ func (cli *DockerCli) CmdRun(args ...string) error {
 }
 
 func (cli *DockerCli) CmdCp(args ...string) error {
-	cmd := cli.Subcmd("cp", "CONTAINER:PATH HOSTPATH", "Copy files/folders from the PATH to the HOSTPATH")
+	cmd := cli.Subcmd("cp", "CONTAINER:PATH HOSTDIR|-\n       docker cp HOSTPATH|- CONTAINER:PATH", "Copy files/folders between a container and the host")
 	if err := cmd.Parse(args); err != nil {
 		return nil
 	}
@@ -2467,33 +2712,154 @@ func (cli *DockerCli) CmdCp(args ...string) error {
 		return nil
 	}
 
-	var copyData engine.Env
-	info := strings.Split(cmd.Arg(0), ":")
+	srcContainer, srcPath := splitCpArg(cmd.Arg(0))
+	dstContainer, dstPath := splitCpArg(cmd.Arg(1))
+	switch {
+	case srcContainer != "" && dstContainer != "":
+		return fmt.Errorf("Error: Copying between containers is not supported")
+	case srcContainer != "":
+		return cli.copyFromContainer(srcContainer, srcPath, dstPath)
+	case dstContainer != "":
+		return cli.copyToContainer(srcPath, dstContainer, dstPath)
+	}
+	return fmt.Errorf("Error: The source or the destination must be a path in a container (CONTAINER:PATH)")
+}
+
+// splitCpArg splits a CONTAINER:PATH argument of cp. A path of the host
+// containing a colon can be given as an absolute path or starting with './'.
+func splitCpArg(arg string) (container, path string) {
+	if filepath.IsAbs(arg) || strings.HasPrefix(arg, "./") {
+		return "", arg
+	}
+	parts := strings.SplitN(arg, ":", 2)
+	if len(parts) == 1 {
+		return "", arg
+	}
+	return parts[0], parts[1]
+}
+
+// containerPathStat is the information about a path in a container sent in
+// the X-Docker-Container-Path-Stat header.
+type containerPathStat struct {
+	Name       string
+	Size       int64
+	Mode       os.FileMode
+	Mtime      time.Time
+	LinkTarget string
+}
 
-	if len(info) != 2 {
-		return fmt.Errorf("Error: Path not specified")
+func (cli *DockerCli) copyFromContainer(container, srcPath, dstPath string) error {
+	var stream io.ReadCloser
+	// Like cp, copy the target of a symlink at the end of the path
+	for links := 0; ; links++ {
+		v := url.Values{}
+		v.Set("path", srcPath)
+		body, header, _, err := cli.clientRequest("GET", "/containers/"+container+"/archive?"+v.Encode(), nil, nil)
+		if err != nil {
+			return err
+		}
+		var stat containerPathStat
+		data, err := base64.URLEncoding.DecodeString(header.Get("X-Docker-Container-Path-Stat"))
+		if err == nil {
+			err = json.Unmarshal(data, &stat)
+		}
+		if err != nil {
+			body.Close()
+			return fmt.Errorf("Error: Invalid information about %s:%s: %s", container, srcPath, err)
+		}
+		if stat.Mode&os.ModeSymlink == 0 {
+			stream = body
+			break
+		}
+		body.Close()
+		if links == maxSymlinks {
+			return fmt.Errorf("Error: Too many levels of symbolic links in %s:%s", container, srcPath)
+		}
+		if filepath.IsAbs(stat.LinkTarget) {
+			srcPath = stat.LinkTarget
+		} else {
+			srcPath = filepath.Join(filepath.Dir(filepath.Join("/", srcPath)), stat.LinkTarget)
+		}
 	}
+	defer stream.Close()
 
-	copyData.Set("Resource", info[1])
-	copyData.Set("HostPath", cmd.Arg(1))
+	if dstPath == "-" {
+		_, err := io.Copy(cli.out, stream)
+		return err
+	}
+	return archive.Untar(stream, dstPath, &archive.TarOptions{NoLchown: true})
+}
 
-	stream, statusCode, err := cli.call("POST", "/containers/"+info[0]+"/copy", copyData, false)
-	if stream != nil {
-		defer stream.Close()
+// statContainerPath returns the information about a path in a container,
+// and the status code of the request.
+func (cli *DockerCli) statContainerPath(container, path string) (*containerPathStat, int, error) {
+	v := url.Values{}
+	v.Set("path", path)
+	body, header, statusCode, err := cli.clientRequest("HEAD", "/containers/"+container+"/archive?"+v.Encode(), nil, nil)
+	if err != nil {
+		return nil, statusCode, err
 	}
-	if statusCode == 404 {
-		return fmt.Errorf("No such container: %v", info[0])
+	body.Close()
+	var stat containerPathStat
+	data, err := base64.URLEncoding.DecodeString(header.Get("X-Docker-Container-Path-Stat"))
+	if err == nil {
+		err = json.Unmarshal(data, &stat)
 	}
 	if err != nil {
-		return err
+		return nil, statusCode, fmt.Errorf("Error: Invalid information about %s:%s: %s", container, path, err)
 	}
+	return &stat, statusCode, nil
+}
 
-	if statusCode == 200 {
-		if err := archive.Untar(stream, copyData.Get("HostPath"), &archive.TarOptions{NoLchown: true}); err != nil {
+func (cli *DockerCli) copyToContainer(srcPath, container, dstPath string) error {
+	var content io.Reader
+	if srcPath == "-" {
+		content = cli.in
+	} else {
+		srcPath, err := filepath.Abs(srcPath)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Lstat(srcPath); err != nil {
 			return err
 		}
+		// Like cp, a destination which does not exist or is not a
+		// directory is the new name of the source, extracted in the
+		// parent directory
+		var name string
+		stat, statusCode, err := cli.statContainerPath(container, dstPath)
+		switch {
+		case statusCode == http.StatusNotFound:
+			name = filepath.Base(filepath.Join("/", dstPath))
+		case err != nil:
+			return err
+		case !stat.Mode.IsDir() && stat.Mode&os.ModeSymlink == 0:
+			name = stat.Name
+		}
+		if name != "" {
+			dstPath = filepath.Dir(filepath.Join("/", dstPath))
+		}
+
+		dir, base := filepath.Split(srcPath)
+		tar, err := archive.TarWithOptions(filepath.Clean(dir), &archive.TarOptions{
+			Compression: archive.Uncompressed,
+			Includes:    []string{base},
+			Name:        name,
+		})
+		if err != nil {
+			return err
+		}
+		defer tar.Close()
+		content = tar
 	}
-	return nil
+
+	v := url.Values{}
+	v.Set("path", dstPath)
+	// Do not replace a directory of the container with a file of the
+	// archive, or the opposite
+	v.Set("noOverwriteDirNonDir", "true")
+	headers := map[string][]string{"Content-Type": {"application/x-tar"}}
+	return cli.stream("PUT", "/containers/"+container+"/archive?"+v.Encode(), content, nil, headers)
 }
 
 func (cli *DockerCli) CmdSave(args ...string) error {
@@ -2676,3 +3042,569 @@ func (cli *DockerCli) CmdExec(args ...string) error {
 
 	return nil
 }
+
+type containerStats struct {
+	Name             string
+	CpuPercentage    float64
+	Memory           float64
+	MemoryLimit      float64
+	MemoryPercentage float64
+	NetworkRx        float64
+	NetworkTx        float64
+	mu               sync.RWMutex
+	err              error
+}
+
+func (s *containerStats) Collect(cli *DockerCli) {
+	stream, _, err := cli.call("GET", "/containers/"+s.Name+"/stats", nil, false)
+	if err != nil {
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+		return
+	}
+	defer stream.Close()
+	var (
+		previousCpu    uint64
+		previousSystem uint64
+		start          = true
+		dec            = json.NewDecoder(stream)
+		u              = make(chan error, 1)
+	)
+	go func() {
+		for {
+			var v *stats.Stats
+			if err := dec.Decode(&v); err != nil {
+				u <- err
+				return
+			}
+			var (
+				memPercent = 0.0
+				cpuPercent = 0.0
+			)
+			if v.MemoryStats.Limit != 0 {
+				memPercent = float64(v.MemoryStats.Usage) / float64(v.MemoryStats.Limit) * 100.0
+			}
+			if !start {
+				cpuPercent = calculateCpuPercent(previousCpu, previousSystem, v)
+			}
+			start = false
+			s.mu.Lock()
+			s.CpuPercentage = cpuPercent
+			s.Memory = float64(v.MemoryStats.Usage)
+			s.MemoryLimit = float64(v.MemoryStats.Limit)
+			s.MemoryPercentage = memPercent
+			s.NetworkRx = float64(v.Network.RxBytes)
+			s.NetworkTx = float64(v.Network.TxBytes)
+			s.mu.Unlock()
+			previousCpu = v.CpuStats.CpuUsage.TotalUsage
+			previousSystem = v.CpuStats.SystemUsage
+			u <- nil
+		}
+	}()
+	for {
+		select {
+		case <-time.After(2 * time.Second):
+			// zero out the values if we have not received an update within
+			// the specified duration.
+			s.mu.Lock()
+			s.CpuPercentage = 0
+			s.Memory = 0
+			s.MemoryPercentage = 0
+			s.mu.Unlock()
+		case err := <-u:
+			if err != nil {
+				if err == io.EOF {
+					err = fmt.Errorf("container %s is not running", s.Name)
+				}
+				s.mu.Lock()
+				s.err = err
+				s.mu.Unlock()
+				return
+			}
+		}
+	}
+}
+
+func (s *containerStats) Display(w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.err != nil {
+		return s.err
+	}
+	fmt.Fprintf(w, "%s\t%.2f%%\t%s/%s\t%.2f%%\t%s/%s\n",
+		s.Name,
+		s.CpuPercentage,
+		units.BytesSize(s.Memory), units.BytesSize(s.MemoryLimit),
+		s.MemoryPercentage,
+		units.BytesSize(s.NetworkRx), units.BytesSize(s.NetworkTx))
+	return nil
+}
+
+func (cli *DockerCli) CmdStats(args ...string) error {
+	cmd := cli.Subcmd("stats", "CONTAINER [CONTAINER...]", "Display a live stream of one or more containers' resource usage statistics")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	names := cmd.Args()
+	sort.Strings(names)
+	var (
+		cStats []*containerStats
+		w      = tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	)
+	printHeader := func() {
+		fmt.Fprint(cli.out, "\033[2J")
+		fmt.Fprint(cli.out, "\033[H")
+		fmt.Fprintln(w, "CONTAINER\tCPU %\tMEM USAGE/LIMIT\tMEM %\tNET I/O")
+	}
+	for _, n := range names {
+		s := &containerStats{Name: n}
+		cStats = append(cStats, s)
+		go s.Collect(cli)
+	}
+	// do a quick pause so that any failed connections for containers that do
+	// not exist are able to be evicted before we display the initial values.
+	time.Sleep(500 * time.Millisecond)
+	var errs []string
+	for _, c := range cStats {
+		c.mu.RLock()
+		if c.err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", c.Name, c.err))
+		}
+		c.mu.RUnlock()
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, ", "))
+	}
+	for _ = range time.Tick(500 * time.Millisecond) {
+		printHeader()
+		toRemove := []int{}
+		for i, s := range cStats {
+			if err := s.Display(w); err != nil {
+				toRemove = append(toRemove, i)
+			}
+		}
+		for j := len(toRemove) - 1; j >= 0; j-- {
+			i := toRemove[j]
+			cStats = append(cStats[:i], cStats[i+1:]...)
+		}
+		if len(cStats) == 0 {
+			return nil
+		}
+		w.Flush()
+	}
+	return nil
+}
+
+func calculateCpuPercent(previousCpu, previousSystem uint64, v *stats.Stats) float64 {
+	var (
+		cpuPercent = 0.0
+		// calculate the change for the cpu usage of the container in between readings
+		cpuDelta = float64(v.CpuStats.CpuUsage.TotalUsage - previousCpu)
+		// calculate the change for the entire system between readings
+		systemDelta = float64(v.CpuStats.SystemUsage - previousSystem)
+	)
+
+	if systemDelta > 0.0 && cpuDelta > 0.0 {
+		cpuPercent = (cpuDelta / systemDelta) * float64(len(v.CpuStats.CpuUsage.PercpuUsage)) * 100.0
+	}
+	return cpuPercent
+}
+
+func (cli *DockerCli) CmdVolume(args ...string) error {
+	description := "Manage Docker volumes\n\nCommands:\n"
+	commands := [][]string{
+		{"create", "Create a volume"},
+		{"inspect", "Return low-level information on a volume"},
+		{"ls", "List volumes"},
+		{"rm", "Remove a volume"},
+	}
+	for _, command := range commands {
+		description += fmt.Sprintf("    %-10.10s%s\n", command[0], command[1])
+	}
+	description += "\nRun 'docker volume COMMAND --help' for more information on a command."
+
+	cmd := cli.Subcmd("volume", "COMMAND", description)
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() > 0 {
+		fmt.Fprintf(cli.err, "Error: Command not found: volume %s\n", cmd.Arg(0))
+	}
+	cmd.Usage()
+	return nil
+}
+
+func (cli *DockerCli) CmdVolumeCreate(args ...string) error {
+	cmd := cli.Subcmd("volume create", "", "Create a volume")
+	flName := cmd.String([]string{"-name"}, "", "Specify volume name")
+	flDriver := cmd.String([]string{"d", "-driver"}, "", "Specify volume driver name")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	config := map[string]string{"Name": *flName, "Driver": *flDriver}
+	stream, statusCode, err := cli.call("POST", "/volumes/create", config, false)
+	if err != nil {
+		return err
+	}
+	if statusCode != 201 {
+		return fmt.Errorf("Unexpected status code %d", statusCode)
+	}
+
+	var result engine.Env
+	if err := result.Decode(stream); err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.out, "%s\n", result.Get("Name"))
+	return nil
+}
+
+func (cli *DockerCli) CmdVolumeInspect(args ...string) error {
+	cmd := cli.Subcmd("volume inspect", "VOLUME [VOLUME...]", "Return low-level information on a volume")
+	tmplStr := cmd.String([]string{"f", "-format"}, "", "Format the output using the given go template.")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
+	}
+	return cli.inspectObjects("/volumes/", cmd.Args(), *tmplStr)
+}
+
+// inspectObjects prints the description of the objects names, which the
+// daemon returns at prefix followed by their name, as a json list or
+// formatted with the go template tmplStr.
+func (cli *DockerCli) inspectObjects(prefix string, names []string, tmplStr string) error {
+	var tmpl *template.Template
+	if tmplStr != "" {
+		var err error
+		if tmpl, err = template.New("").Funcs(funcMap).Parse(tmplStr); err != nil {
+			fmt.Fprintf(cli.err, "Template parsing error: %v\n", err)
+			return &utils.StatusError{StatusCode: 64,
+				Status: "Template parsing error: " + err.Error()}
+		}
+	}
+
+	indented := new(bytes.Buffer)
+	indented.WriteByte('[')
+	status := 0
+
+	for _, name := range names {
+		obj, _, err := readBody(cli.call("GET", prefix+name, nil, false))
+		if err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			status = 1
+			continue
+		}
+
+		if tmpl == nil {
+			if err = json.Indent(indented, obj, "", "    "); err != nil {
+				fmt.Fprintf(cli.err, "%s\n", err)
+				status = 1
+				continue
+			}
+		} else {
+			var value interface{}
+			if err := json.Unmarshal(obj, &value); err != nil {
+				fmt.Fprintf(cli.err, "%s\n", err)
+				status = 1
+				continue
+			}
+			if err := tmpl.Execute(cli.out, value); err != nil {
+				return err
+			}
+			cli.out.Write([]byte{'\n'})
+		}
+		indented.WriteString(",")
+	}
+
+	if indented.Len() > 1 {
+		// Remove trailing ','
+		indented.Truncate(indented.Len() - 1)
+	}
+	indented.WriteByte(']')
+
+	if tmpl == nil {
+		if _, err := io.Copy(cli.out, indented); err != nil {
+			return err
+		}
+	}
+
+	if status != 0 {
+		return &utils.StatusError{StatusCode: status}
+	}
+	return nil
+}
+
+func (cli *DockerCli) CmdVolumeLs(args ...string) error {
+	cmd := cli.Subcmd("volume ls", "", "List volumes")
+	quiet := cmd.Bool([]string{"q", "-quiet"}, false, "Only display volume names")
+
+	flFilter := opts.NewListOpts(nil)
+	cmd.Var(&flFilter, []string{"f", "-filter"}, "Provide filter values (i.e. 'dangling=true')")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	volFilterArgs := filters.Args{}
+	for _, f := range flFilter.GetAll() {
+		var err error
+		volFilterArgs, err = filters.ParseFlag(f, volFilterArgs)
+		if err != nil {
+			return err
+		}
+	}
+	for name := range volFilterArgs {
+		if _, ok := acceptedVolumeFilterTags[name]; !ok {
+			return fmt.Errorf("Invalid filter '%s'", name)
+		}
+	}
+
+	v := url.Values{}
+	if len(volFilterArgs) > 0 {
+		filterJson, err := filters.ToParam(volFilterArgs)
+		if err != nil {
+			return err
+		}
+		v.Set("filters", filterJson)
+	}
+
+	body, _, err := readBody(cli.call("GET", "/volumes?"+v.Encode(), nil, false))
+	if err != nil {
+		return err
+	}
+
+	outs := engine.NewTable("Name", 0)
+	if _, err := outs.ReadListFrom(body); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	if !*quiet {
+		fmt.Fprintln(w, "DRIVER\tVOLUME NAME")
+	}
+	for _, out := range outs.Data {
+		if *quiet {
+			fmt.Fprintln(w, out.Get("Name"))
+		} else {
+			fmt.Fprintf(w, "%s\t%s\n", out.Get("Driver"), out.Get("Name"))
+		}
+	}
+	w.Flush()
+	return nil
+}
+
+func (cli *DockerCli) CmdVolumeRm(args ...string) error {
+	cmd := cli.Subcmd("volume rm", "VOLUME [VOLUME...]", "Remove one or more volumes")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	var encounteredError error
+	for _, name := range cmd.Args() {
+		_, _, err := readBody(cli.call("DELETE", "/volumes/"+name, nil, false))
+		if err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			encounteredError = fmt.Errorf("Error: failed to remove one or more volumes")
+		} else {
+			fmt.Fprintf(cli.out, "%s\n", name)
+		}
+	}
+	return encounteredError
+}
+
+func (cli *DockerCli) CmdNetwork(args ...string) error {
+	description := "Manage Docker networks\n\nCommands:\n"
+	commands := [][]string{
+		{"connect", "Connect a container to a network"},
+		{"create", "Create a network"},
+		{"disconnect", "Disconnect a container from a network"},
+		{"inspect", "Return low-level information on a network"},
+		{"ls", "List networks"},
+		{"rm", "Remove a network"},
+	}
+	for _, command := range commands {
+		description += fmt.Sprintf("    %-12.12s%s\n", command[0], command[1])
+	}
+	description += "\nRun 'docker network COMMAND --help' for more information on a command."
+
+	cmd := cli.Subcmd("network", "COMMAND", description)
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() > 0 {
+		fmt.Fprintf(cli.err, "Error: Command not found: network %s\n", cmd.Arg(0))
+	}
+	cmd.Usage()
+	return nil
+}
+
+func (cli *DockerCli) CmdNetworkCreate(args ...string) error {
+	cmd := cli.Subcmd("network create", "NETWORK", "Create a network")
+	flDriver := cmd.String([]string{"d", "-driver"}, "bridge", "Driver providing the network")
+	flSubnet := cmd.String([]string{"-subnet"}, "", "Subnet of the network in CIDR format (e.g. 10.1.0.0/16)")
+	flOpts := opts.NewListOpts(nil)
+	cmd.Var(&flOpts, []string{"o", "-opt"}, "Set driver specific options (e.g. 'Gateway=10.1.0.254')")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	options := make(map[string]string)
+	for _, opt := range flOpts.GetAll() {
+		k, v, err := parsers.ParseKeyValueOpt(opt)
+		if err != nil {
+			return err
+		}
+		options[k] = v
+	}
+	if *flSubnet != "" {
+		options["Subnet"] = *flSubnet
+	}
+
+	config := map[string]interface{}{"Name": cmd.Arg(0), "Driver": *flDriver, "Options": options}
+	stream, statusCode, err := cli.call("POST", "/networks/create", config, false)
+	if err != nil {
+		return err
+	}
+	if statusCode != 201 {
+		return fmt.Errorf("Unexpected status code %d", statusCode)
+	}
+
+	var result engine.Env
+	if err := result.Decode(stream); err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.out, "%s\n", result.Get("Id"))
+	return nil
+}
+
+func (cli *DockerCli) CmdNetworkInspect(args ...string) error {
+	cmd := cli.Subcmd("network inspect", "NETWORK [NETWORK...]", "Return low-level information on a network")
+	tmplStr := cmd.String([]string{"f", "-format"}, "", "Format the output using the given go template.")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
+	}
+	return cli.inspectObjects("/networks/", cmd.Args(), *tmplStr)
+}
+
+func (cli *DockerCli) CmdNetworkLs(args ...string) error {
+	cmd := cli.Subcmd("network ls", "", "List networks")
+	quiet := cmd.Bool([]string{"q", "-quiet"}, false, "Only display network IDs")
+	noTrunc := cmd.Bool([]string{"-no-trunc"}, false, "Don't truncate output")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	body, _, err := readBody(cli.call("GET", "/networks", nil, false))
+	if err != nil {
+		return err
+	}
+
+	outs := engine.NewTable("Name", 0)
+	if _, err := outs.ReadListFrom(body); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	if !*quiet {
+		fmt.Fprintln(w, "NETWORK ID\tNAME\tDRIVER\tSUBNET")
+	}
+	for _, out := range outs.Data {
+		id := out.Get("Id")
+		if !*noTrunc {
+			id = utils.TruncateID(id)
+		}
+		if *quiet {
+			fmt.Fprintln(w, id)
+		} else {
+			options := map[string]string{}
+			out.GetJson("Options", &options)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", id, out.Get("Name"), out.Get("Driver"), options["Subnet"])
+		}
+	}
+	w.Flush()
+	return nil
+}
+
+func (cli *DockerCli) CmdNetworkRm(args ...string) error {
+	cmd := cli.Subcmd("network rm", "NETWORK [NETWORK...]", "Remove one or more networks")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	var encounteredError error
+	for _, name := range cmd.Args() {
+		_, _, err := readBody(cli.call("DELETE", "/networks/"+name, nil, false))
+		if err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			encounteredError = fmt.Errorf("Error: failed to remove one or more networks")
+		} else {
+			fmt.Fprintf(cli.out, "%s\n", name)
+		}
+	}
+	return encounteredError
+}
+
+func (cli *DockerCli) CmdNetworkConnect(args ...string) error {
+	cmd := cli.Subcmd("network connect", "NETWORK CONTAINER", "Connect a container to a network")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 2 {
+		cmd.Usage()
+		return nil
+	}
+	config := map[string]string{"Container": cmd.Arg(1)}
+	_, _, err := readBody(cli.call("POST", "/networks/"+cmd.Arg(0)+"/connect", config, false))
+	return err
+}
+
+func (cli *DockerCli) CmdNetworkDisconnect(args ...string) error {
+	cmd := cli.Subcmd("network disconnect", "NETWORK CONTAINER", "Disconnect a container from a network")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 2 {
+		cmd.Usage()
+		return nil
+	}
+	config := map[string]string{"Container": cmd.Arg(1)}
+	_, _, err := readBody(cli.call("POST", "/networks/"+cmd.Arg(0)+"/disconnect", config, false))
+	return err
+}
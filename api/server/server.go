@@ -215,6 +215,25 @@ func postContainersPause(eng *engine.Engine, version version.Version, w http.Res
 	return nil
 }
 
+func postContainersRename(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	newName := r.Form.Get("name")
+	if newName == "" {
+		return fmt.Errorf("bad parameter: the new name of the container is missing")
+	}
+	job := eng.Job("rename", vars["name"], newName)
+	if err := job.Run(); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
 func postContainersUnpause(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if vars == nil {
 		return fmt.Errorf("Missing parameter")
@@ -230,6 +249,36 @@ func postContainersUnpause(eng *engine.Engine, version version.Version, w http.R
 	return nil
 }
 
+func postContainersUpdate(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	if err := checkForJson(r); err != nil {
+		return err
+	}
+	var (
+		out         engine.Env
+		job         = eng.Job("update", vars["name"])
+		outWarnings []string
+		warnings    = bytes.NewBuffer(nil)
+	)
+	if err := job.DecodeEnv(r.Body); err != nil {
+		return err
+	}
+	// Read warnings from stderr
+	job.Stderr.Add(warnings)
+	if err := job.Run(); err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(warnings)
+	for scanner.Scan() {
+		outWarnings = append(outWarnings, scanner.Text())
+	}
+	out.SetList("Warnings", outWarnings)
+
+	return writeJSON(w, http.StatusOK, out)
+}
+
 func getContainersExport(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if vars == nil {
 		return fmt.Errorf("Missing parameter")
@@ -398,6 +447,19 @@ func getContainersJSON(eng *engine.Engine, version version.Version, w http.Respo
 	return nil
 }
 
+func getContainersStats(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	name := vars["name"]
+	job := eng.Job("stats", name)
+	streamJSON(job, w, true)
+	return job.Run()
+}
+
 func getContainersLogs(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := parseForm(r); err != nil {
 		return err
@@ -427,6 +489,15 @@ func getContainersLogs(eng *engine.Engine, version version.Version, w http.Respo
 	if err = inspectJob.Run(); err != nil {
 		return err
 	}
+	// Only the json-file driver can be read back, fail before the response
+	// starts streaming so the client gets a proper error
+	if hostConfig := c.GetSubEnv("HostConfig"); hostConfig != nil {
+		if logConfig := hostConfig.GetSubEnv("LogConfig"); logConfig != nil {
+			if logType := logConfig.Get("Type"); logType != "" && logType != "json-file" {
+				return fmt.Errorf("\"logs\" is not supported for containers using the %q logging driver, only \"json-file\" can be read back", logType)
+			}
+		}
+	}
 
 	var outStream, errStream io.Writer
 	outStream = utils.NewWriteFlusher(w)
@@ -492,6 +563,7 @@ func postCommit(eng *engine.Engine, version version.Version, w http.ResponseWrit
 	job.Setenv("tag", r.Form.Get("tag"))
 	job.Setenv("author", r.Form.Get("author"))
 	job.Setenv("comment", r.Form.Get("comment"))
+	job.SetenvList("changes", r.Form["changes"])
 	job.SetenvSubEnv("config", &config)
 
 	job.Stdout.Add(stdoutBuffer)
@@ -544,6 +616,7 @@ func postImagesCreate(eng *engine.Engine, version version.Version, w http.Respon
 		}
 		job = eng.Job("import", r.Form.Get("fromSrc"), repo, tag)
 		job.Stdin.Add(r.Body)
+		job.SetenvList("changes", r.Form["changes"])
 	}
 
 	if version.GreaterThan("1.0") {
@@ -945,6 +1018,150 @@ func wsContainersAttach(eng *engine.Engine, version version.Version, w http.Resp
 	return nil
 }
 
+func getVolumesJSON(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	var job = eng.Job("volumes")
+	job.Setenv("filters", r.Form.Get("filters"))
+	streamJSON(job, w, false)
+	return job.Run()
+}
+
+func getVolumeByName(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	var job = eng.Job("volume_inspect", vars["name"])
+	streamJSON(job, w, false)
+	return job.Run()
+}
+
+func postVolumesCreate(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	if err := checkForJson(r); err != nil {
+		return err
+	}
+	var (
+		config       engine.Env
+		out          engine.Env
+		stdoutBuffer = bytes.NewBuffer(nil)
+	)
+	if err := config.Decode(r.Body); err != nil {
+		return err
+	}
+	var job *engine.Job
+	if name := config.Get("Name"); name != "" {
+		job = eng.Job("volume_create", name)
+	} else {
+		job = eng.Job("volume_create")
+	}
+	job.Setenv("Driver", config.Get("Driver"))
+	job.Stdout.Add(stdoutBuffer)
+	if err := job.Run(); err != nil {
+		return err
+	}
+	out.Set("Name", engine.Tail(stdoutBuffer, 1))
+	return writeJSON(w, http.StatusCreated, out)
+}
+
+func deleteVolumes(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	if err := eng.Job("volume_rm", vars["name"]).Run(); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func getNetworksJSON(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	var job = eng.Job("networks")
+	streamJSON(job, w, false)
+	return job.Run()
+}
+
+func getNetworkByName(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	var job = eng.Job("network_inspect", vars["name"])
+	streamJSON(job, w, false)
+	return job.Run()
+}
+
+func postNetworksCreate(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	if err := checkForJson(r); err != nil {
+		return err
+	}
+	var (
+		config       engine.Env
+		out          engine.Env
+		stdoutBuffer = bytes.NewBuffer(nil)
+	)
+	if err := config.Decode(r.Body); err != nil {
+		return err
+	}
+	job := eng.Job("network_create", config.Get("Name"))
+	job.Setenv("Driver", config.Get("Driver"))
+	job.Setenv("Options", config.Get("Options"))
+	job.Stdout.Add(stdoutBuffer)
+	if err := job.Run(); err != nil {
+		return err
+	}
+	out.Set("Id", engine.Tail(stdoutBuffer, 1))
+	return writeJSON(w, http.StatusCreated, out)
+}
+
+func postNetworkConnect(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	return connectNetwork(eng, "network_connect", w, r, vars)
+}
+
+func postNetworkDisconnect(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	return connectNetwork(eng, "network_disconnect", w, r, vars)
+}
+
+// connectNetwork runs the job connecting or disconnecting the container given
+// in the body of the request to the network name.
+func connectNetwork(eng *engine.Engine, jobName string, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	if err := checkForJson(r); err != nil {
+		return err
+	}
+	var config engine.Env
+	if err := config.Decode(r.Body); err != nil {
+		return err
+	}
+	container := config.Get("Container")
+	if container == "" {
+		return fmt.Errorf("Bad parameter: missing container")
+	}
+	if err := eng.Job(jobName, vars["name"], container).Run(); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func deleteNetworks(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	if err := eng.Job("network_rm", vars["name"]).Run(); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
 func getContainersByName(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if vars == nil {
 		return fmt.Errorf("Missing parameter")
@@ -1032,9 +1249,25 @@ func postBuild(eng *engine.Engine, version version.Version, w http.ResponseWrite
 	job.Stdin.Add(r.Body)
 	job.Setenv("remote", r.FormValue("remote"))
 	job.Setenv("t", r.FormValue("t"))
+	job.Setenv("dockerfile", r.FormValue("dockerfile"))
 	job.Setenv("q", r.FormValue("q"))
 	job.Setenv("nocache", r.FormValue("nocache"))
 	job.Setenv("forcerm", r.FormValue("forcerm"))
+	job.Setenv("squash", r.FormValue("squash"))
+	if buildArgs := r.FormValue("buildargs"); buildArgs != "" {
+		var args map[string]string
+		if err := json.Unmarshal([]byte(buildArgs), &args); err != nil {
+			return fmt.Errorf("Bad parameter: invalid buildargs: %s", err)
+		}
+		job.SetenvJson("buildargs", args)
+	}
+	if cacheFrom := r.FormValue("cachefrom"); cacheFrom != "" {
+		var images []string
+		if err := json.Unmarshal([]byte(cacheFrom), &images); err != nil {
+			return fmt.Errorf("Bad parameter: invalid cachefrom: %s", err)
+		}
+		job.SetenvJson("cachefrom", images)
+	}
 	job.SetenvJson("authConfig", authConfig)
 	job.SetenvJson("configFile", configFile)
 
@@ -1087,6 +1320,84 @@ func postContainersCopy(eng *engine.Engine, version version.Version, w http.Resp
 	return nil
 }
 
+// setContainerPathStatHeader sets the information about the path in the
+// container in the X-Docker-Container-Path-Stat header, encoded in base64.
+func setContainerPathStatHeader(eng *engine.Engine, w http.ResponseWriter, name, path string) error {
+	var stat bytes.Buffer
+	job := eng.Job("container_stat", name, path)
+	job.Stdout.Add(&stat)
+	if err := job.Run(); err != nil {
+		return err
+	}
+	w.Header().Set("X-Docker-Container-Path-Stat", base64.URLEncoding.EncodeToString(bytes.TrimSpace(stat.Bytes())))
+	return nil
+}
+
+func headContainersArchive(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	path := r.Form.Get("path")
+	if path == "" {
+		return fmt.Errorf("Bad parameter: path cannot be empty")
+	}
+	if err := setContainerPathStatHeader(eng, w, vars["name"], path); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func getContainersArchive(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	path := r.Form.Get("path")
+	if path == "" {
+		return fmt.Errorf("Bad parameter: path cannot be empty")
+	}
+	if err := setContainerPathStatHeader(eng, w, vars["name"], path); err != nil {
+		return err
+	}
+
+	job := eng.Job("container_archive", vars["name"], path)
+	job.Stdout.Add(w)
+	w.Header().Set("Content-Type", "application/x-tar")
+	if err := job.Run(); err != nil {
+		// The archive is already partially sent
+		log.Errorf("%s", err)
+	}
+	return nil
+}
+
+func putContainersArchive(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	path := r.Form.Get("path")
+	if path == "" {
+		return fmt.Errorf("Bad parameter: path cannot be empty")
+	}
+
+	job := eng.Job("container_extract", vars["name"], path)
+	job.Setenv("NoOverwriteDirNonDir", r.Form.Get("noOverwriteDirNonDir"))
+	job.Stdin.Add(r.Body)
+	if err := job.Run(); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
 func postContainerExecCreate(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := parseForm(r); err != nil {
 		return nil
@@ -1288,36 +1599,56 @@ func createRouter(eng *engine.Engine, logging, enableCors bool, dockerVersion st
 			"/containers/{name:.*}/changes":   getContainersChanges,
 			"/containers/{name:.*}/json":      getContainersByName,
 			"/containers/{name:.*}/top":       getContainersTop,
+			"/containers/{name:.*}/stats":     getContainersStats,
 			"/containers/{name:.*}/logs":      getContainersLogs,
 			"/containers/{name:.*}/attach/ws": wsContainersAttach,
+			"/containers/{name:.*}/archive":   getContainersArchive,
 			"/exec/{id:.*}/json":              getExecByID,
+			"/volumes":                        getVolumesJSON,
+			"/volumes/{name:.*}":              getVolumeByName,
+			"/networks":                       getNetworksJSON,
+			"/networks/{name:.*}":             getNetworkByName,
 		},
 		"POST": {
-			"/auth":                         postAuth,
-			"/commit":                       postCommit,
-			"/build":                        postBuild,
-			"/images/create":                postImagesCreate,
-			"/images/load":                  postImagesLoad,
-			"/images/{name:.*}/push":        postImagesPush,
-			"/images/{name:.*}/tag":         postImagesTag,
-			"/containers/create":            postContainersCreate,
-			"/containers/{name:.*}/kill":    postContainersKill,
-			"/containers/{name:.*}/pause":   postContainersPause,
-			"/containers/{name:.*}/unpause": postContainersUnpause,
-			"/containers/{name:.*}/restart": postContainersRestart,
-			"/containers/{name:.*}/start":   postContainersStart,
-			"/containers/{name:.*}/stop":    postContainersStop,
-			"/containers/{name:.*}/wait":    postContainersWait,
-			"/containers/{name:.*}/resize":  postContainersResize,
-			"/containers/{name:.*}/attach":  postContainersAttach,
-			"/containers/{name:.*}/copy":    postContainersCopy,
-			"/containers/{name:.*}/exec":    postContainerExecCreate,
-			"/exec/{name:.*}/start":         postContainerExecStart,
-			"/exec/{name:.*}/resize":        postContainerExecResize,
+			"/auth":                          postAuth,
+			"/commit":                        postCommit,
+			"/build":                         postBuild,
+			"/images/create":                 postImagesCreate,
+			"/images/load":                   postImagesLoad,
+			"/images/{name:.*}/push":         postImagesPush,
+			"/images/{name:.*}/tag":          postImagesTag,
+			"/containers/create":             postContainersCreate,
+			"/containers/{name:.*}/kill":     postContainersKill,
+			"/containers/{name:.*}/pause":    postContainersPause,
+			"/containers/{name:.*}/unpause":  postContainersUnpause,
+			"/containers/{name:.*}/update":   postContainersUpdate,
+			"/containers/{name:.*}/rename":   postContainersRename,
+			"/containers/{name:.*}/restart":  postContainersRestart,
+			"/containers/{name:.*}/start":    postContainersStart,
+			"/containers/{name:.*}/stop":     postContainersStop,
+			"/containers/{name:.*}/wait":     postContainersWait,
+			"/containers/{name:.*}/resize":   postContainersResize,
+			"/containers/{name:.*}/attach":   postContainersAttach,
+			"/containers/{name:.*}/copy":     postContainersCopy,
+			"/containers/{name:.*}/exec":     postContainerExecCreate,
+			"/exec/{name:.*}/start":          postContainerExecStart,
+			"/exec/{name:.*}/resize":         postContainerExecResize,
+			"/volumes/create":                postVolumesCreate,
+			"/networks/create":               postNetworksCreate,
+			"/networks/{name:.*}/connect":    postNetworkConnect,
+			"/networks/{name:.*}/disconnect": postNetworkDisconnect,
 		},
 		"DELETE": {
 			"/containers/{name:.*}": deleteContainers,
 			"/images/{name:.*}":     deleteImages,
+			"/volumes/{name:.*}":    deleteVolumes,
+			"/networks/{name:.*}":   deleteNetworks,
+		},
+		"HEAD": {
+			"/containers/{name:.*}/archive": headContainersArchive,
+		},
+		"PUT": {
+			"/containers/{name:.*}/archive": putContainersArchive,
 		},
 		"OPTIONS": {
 			"": optionsHandler,
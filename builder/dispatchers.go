@@ -12,11 +12,14 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/docker/docker/nat"
 	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/runconfig"
 )
 
@@ -66,6 +69,37 @@ func env(b *Builder, args []string, attributes map[string]bool, original string)
 	return b.commit("", b.Config.Cmd, commitStr)
 }
 
+// LABEL some json data describing the image
+//
+// Sets the Label variable foo to bar,
+//
+func label(b *Builder, args []string, attributes map[string]bool, original string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("LABEL is missing arguments")
+	}
+	if len(args)%2 != 0 {
+		// should never get here, but just in case
+		return fmt.Errorf("Bad input to LABEL, too many args")
+	}
+
+	commitStr := "LABEL"
+
+	if b.Config.Labels == nil {
+		b.Config.Labels = map[string]string{}
+	}
+
+	for j := 0; j < len(args); j++ {
+		// name  ==> args[j]
+		// value ==> args[j+1]
+		newVar := args[j] + "=" + args[j+1] + ""
+		commitStr += " " + newVar
+
+		b.Config.Labels[args[j]] = args[j+1]
+		j++
+	}
+	return b.commit("", b.Config.Cmd, commitStr)
+}
+
 // MAINTAINER some text <maybe@an.email.address>
 //
 // Sets the maintainer metadata.
@@ -78,6 +112,8 @@ func maintainer(b *Builder, args []string, attributes map[string]bool, original
 	return b.commit("", b.Config.Cmd, fmt.Sprintf("MAINTAINER %s", b.maintainer))
 }
 
+var validStageName = regexp.MustCompile(`^[a-z][a-z0-9_.-]*$`)
+
 // ADD foo /path
 //
 // Add the file 'foo' to '/path'. Tarball and Remote URL (git, http) handling
@@ -88,31 +124,74 @@ func add(b *Builder, args []string, attributes map[string]bool, original string)
 		return fmt.Errorf("ADD requires at least two arguments")
 	}
 
-	return b.runContextCommand(args, true, true, "ADD")
+	return b.runContextCommand(args, true, true, "ADD", "")
 }
 
-// COPY foo /path
+// COPY [--from=<stage|image>] foo /path
 //
-// Same as 'ADD' but without the tar and remote url handling.
+// Same as 'ADD' but without the tar and remote url handling. With --from,
+// the files are copied from the rootfs of an earlier build stage or of an
+// image instead of the context.
 //
 func dispatchCopy(b *Builder, args []string, attributes map[string]bool, original string) error {
+	var from string
+	if len(args) > 0 && strings.HasPrefix(args[0], "--from=") {
+		from = strings.TrimPrefix(args[0], "--from=")
+		if from == "" {
+			return fmt.Errorf("COPY --from requires the name of a build stage or of an image")
+		}
+		args = args[1:]
+	}
 	if len(args) < 2 {
 		return fmt.Errorf("COPY requires at least two arguments")
 	}
 
-	return b.runContextCommand(args, false, false, "COPY")
+	return b.runContextCommand(args, false, false, "COPY", from)
 }
 
-// FROM imagename
+// FROM imagename [AS name]
 //
-// This sets the image the dockerfile will build on top of.
+// This sets the image the dockerfile will build on top of. Each FROM starts
+// a new build stage, which can be named so that later stages can build on
+// top of it or copy files out of it with COPY --from. Only the last stage
+// is tagged.
 //
 func from(b *Builder, args []string, attributes map[string]bool, original string) error {
 	if len(args) != 1 {
 		return fmt.Errorf("FROM requires one argument")
 	}
 
-	name := args[0]
+	fields := strings.Fields(args[0])
+	stageName := ""
+	switch {
+	case len(fields) == 1:
+	case len(fields) == 3 && strings.EqualFold(fields[1], "AS"):
+		stageName = strings.ToLower(fields[2])
+		if !validStageName.MatchString(stageName) {
+			return fmt.Errorf("Invalid name for build stage: %q, the name must start with a letter and contain only letters, digits, '_', '.' and '-'", fields[2])
+		}
+		if _, exists := b.stages[stageName]; exists || stageName == b.stageName {
+			return fmt.Errorf("Duplicate name for build stage: %q", fields[2])
+		}
+	default:
+		return fmt.Errorf("FROM requires either one argument, or three: FROM <image> AS <name>")
+	}
+	name := fields[0]
+
+	if b.image != "" {
+		b.finishStage()
+	}
+	b.stageName = stageName
+
+	// Build on top of an earlier stage. Unlike COPY --from, FROM does not
+	// refer to stages by index: FROM 0 is an image called 0
+	if id, exists := b.stages[strings.ToLower(name)]; exists {
+		image, err := b.Daemon.Graph().Get(id)
+		if err != nil {
+			return err
+		}
+		return b.processImageFrom(image)
+	}
 
 	image, err := b.Daemon.Repositories().LookupImage(name)
 	if b.Pull {
@@ -219,6 +298,15 @@ func run(b *Builder, args []string, attributes map[string]bool, original string)
 
 	defer func(cmd []string) { b.Config.Cmd = cmd }(cmd)
 
+	// The build-time arguments are set in the environment of the command,
+	// which makes them part of the cache key, but they must not be
+	// committed in the config of the image.
+	env := b.Config.Env
+	if buildEnv := b.buildArgsEnv(); len(buildEnv) > 0 {
+		b.Config.Env = append(append([]string{}, env...), buildEnv...)
+	}
+	defer func() { b.Config.Env = env }()
+
 	log.Debugf("[BUILDER] Command to be executed: %v", b.Config.Cmd)
 
 	hit, err := b.probeCache()
@@ -233,6 +321,10 @@ func run(b *Builder, args []string, attributes map[string]bool, original string)
 	if err != nil {
 		return err
 	}
+	// The container keeps the arguments in its own copy of the config
+	containerConfig := *b.Config
+	c.Config = &containerConfig
+	b.Config.Env = env
 
 	// Ensure that we keep the container mounted until the commit
 	// to avoid unmounting and then mounting directly again
@@ -371,6 +463,124 @@ func volume(b *Builder, args []string, attributes map[string]bool, original stri
 	return nil
 }
 
+// ARG name[=default]
+//
+// Declares the build-time argument name, which can then be given with
+// `docker build --build-arg name=value`. Arguments are available like ENV
+// variables from the next statement on and in the environment of RUN, but
+// are not committed in the config of the image.
+//
+func arg(b *Builder, args []string, attributes map[string]bool, original string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("ARG requires exactly one argument definition")
+	}
+
+	var (
+		name       string
+		value      string
+		hasDefault bool
+	)
+	if strings.Contains(args[0], "=") {
+		parts := strings.SplitN(args[0], "=", 2)
+		name, value, hasDefault = parts[0], parts[1], true
+	} else {
+		name = args[0]
+	}
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return fmt.Errorf("ARG names can not be empty or contain whitespace: %q", args[0])
+	}
+
+	b.allowedBuildArgs[name] = true
+	b.usedBuildArgs[name] = true
+	if hasDefault {
+		b.buildArgDefaults[name] = value
+	}
+
+	return b.commit("", b.Config.Cmd, fmt.Sprintf("ARG %s", args[0]))
+}
+
+// HEALTHCHECK [--interval=30s] [--timeout=30s] [--retries=3] CMD command
+//
+// Set the probe run in the containers of the image to check that they are
+// healthy. The command is handled like the one of CMD. HEALTHCHECK NONE
+// disables the probe of the base image.
+//
+func healthcheck(b *Builder, args []string, attributes map[string]bool, original string) error {
+	healthcheck := &runconfig.HealthConfig{}
+
+	i := 0
+	for ; i < len(args) && strings.HasPrefix(args[i], "--"); i++ {
+		parts := strings.SplitN(strings.TrimPrefix(args[i], "--"), "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("HEALTHCHECK options must be given as --name=value: %s", args[i])
+		}
+		switch parts[0] {
+		case "interval", "timeout":
+			d, err := time.ParseDuration(parts[1])
+			if err != nil || d <= 0 {
+				return fmt.Errorf("Invalid duration for HEALTHCHECK --%s: %s", parts[0], parts[1])
+			}
+			if parts[0] == "interval" {
+				healthcheck.Interval = d
+			} else {
+				healthcheck.Timeout = d
+			}
+		case "retries":
+			n, err := strconv.Atoi(parts[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("Invalid number for HEALTHCHECK --retries: %s", parts[1])
+			}
+			healthcheck.Retries = n
+		default:
+			return fmt.Errorf("Unknown HEALTHCHECK option: --%s", parts[0])
+		}
+	}
+	if i == len(args) {
+		return fmt.Errorf("HEALTHCHECK requires either NONE or CMD followed by a command")
+	}
+
+	switch args[i] {
+	case "NONE":
+		if i > 0 {
+			return fmt.Errorf("HEALTHCHECK NONE takes no options")
+		}
+		healthcheck.Test = []string{"NONE"}
+	case "CMD":
+		cmd := handleJsonArgs(args[i+1:], attributes)
+		if len(cmd) == 0 || cmd[0] == "" {
+			return fmt.Errorf("HEALTHCHECK CMD requires a command")
+		}
+		if attributes["json"] {
+			healthcheck.Test = append([]string{"CMD"}, cmd...)
+		} else {
+			healthcheck.Test = []string{"CMD-SHELL", cmd[0]}
+		}
+	default:
+		return fmt.Errorf("Unknown type %q in HEALTHCHECK (try CMD)", args[i])
+	}
+
+	b.Config.Healthcheck = healthcheck
+	return b.commit("", b.Config.Cmd, fmt.Sprintf("HEALTHCHECK %v", healthcheck.Test))
+}
+
+// STOPSIGNAL signal
+//
+// Set the signal sent to the containers of the image to stop them.
+//
+func stopsignal(b *Builder, args []string, attributes map[string]bool, original string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("STOPSIGNAL requires exactly one argument")
+	}
+
+	sig := args[0]
+	if _, err := signal.ParseSignal(sig); err != nil {
+		return err
+	}
+
+	b.Config.StopSignal = sig
+	return b.commit("", b.Config.Cmd, fmt.Sprintf("STOPSIGNAL %v", sig))
+}
+
 // INSERT is no longer accepted, but we still parse it.
 func insert(b *Builder, args []string, attributes map[string]bool, original string) error {
 	return fmt.Errorf("INSERT has been deprecated. Please use ADD instead")
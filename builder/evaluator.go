@@ -25,12 +25,15 @@ import (
 	"io"
 	"os"
 	"path"
+	"sort"
 	"strings"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api"
 	"github.com/docker/docker/builder/parser"
 	"github.com/docker/docker/daemon"
 	"github.com/docker/docker/engine"
+	"github.com/docker/docker/pkg/symlink"
 	"github.com/docker/docker/pkg/tarsum"
 	"github.com/docker/docker/registry"
 	"github.com/docker/docker/runconfig"
@@ -43,33 +46,40 @@ var (
 
 // Environment variable interpolation will happen on these statements only.
 var replaceEnvAllowed = map[string]struct{}{
-	"env":     {},
-	"add":     {},
-	"copy":    {},
-	"workdir": {},
-	"expose":  {},
-	"volume":  {},
-	"user":    {},
+	"env":        {},
+	"label":      {},
+	"add":        {},
+	"copy":       {},
+	"workdir":    {},
+	"expose":     {},
+	"volume":     {},
+	"user":       {},
+	"arg":        {},
+	"stopsignal": {},
 }
 
 var evaluateTable map[string]func(*Builder, []string, map[string]bool, string) error
 
 func init() {
 	evaluateTable = map[string]func(*Builder, []string, map[string]bool, string) error{
-		"env":        env,
-		"maintainer": maintainer,
-		"add":        add,
-		"copy":       dispatchCopy, // copy() is a go builtin
-		"from":       from,
-		"onbuild":    onbuild,
-		"workdir":    workdir,
-		"run":        run,
-		"cmd":        cmd,
-		"entrypoint": entrypoint,
-		"expose":     expose,
-		"volume":     volume,
-		"user":       user,
-		"insert":     insert,
+		"env":         env,
+		"label":       label,
+		"maintainer":  maintainer,
+		"add":         add,
+		"copy":        dispatchCopy, // copy() is a go builtin
+		"from":        from,
+		"onbuild":     onbuild,
+		"workdir":     workdir,
+		"run":         run,
+		"cmd":         cmd,
+		"entrypoint":  entrypoint,
+		"expose":      expose,
+		"volume":      volume,
+		"user":        user,
+		"insert":      insert,
+		"arg":         arg,
+		"healthcheck": healthcheck,
+		"stopsignal":  stopsignal,
 	}
 }
 
@@ -92,9 +102,22 @@ type Builder struct {
 	ForceRemove bool
 	Pull        bool
 
+	// squash the layers built on top of the last FROM into a single one
+	Squash bool
+
+	// images given with --cache-from, whose layers can be used as cache
+	CacheFrom []string
+
 	AuthConfig     *registry.AuthConfig
 	AuthConfigFile *registry.ConfigFile
 
+	// path of the Dockerfile in the context
+	DockerfileName string
+
+	// build-time arguments given with --build-arg, only the ones declared
+	// with ARG are used.
+	BuildArgs map[string]string
+
 	// Deprecated, original writer used for ImagePull. To be removed.
 	OutOld          io.Writer
 	StreamFormatter *utils.StreamFormatter
@@ -104,13 +127,25 @@ type Builder struct {
 	// both of these are controlled by the Remove and ForceRemove options in BuildOpts
 	TmpContainers map[string]struct{} // a map of containers used for removes
 
-	dockerfile  *parser.Node  // the syntax tree of the dockerfile
-	image       string        // image name for commit processing
-	maintainer  string        // maintainer name. could probably be removed.
-	cmdSet      bool          // indicates is CMD was set in current Dockerfile
-	context     tarsum.TarSum // the context is a tarball that is uploaded by the client
-	contextPath string        // the path of the temporary directory the local context is unpacked to (server side)
+	dockerfile  *parser.Node          // the syntax tree of the dockerfile
+	image       string                // image name for commit processing
+	fromImage   string                // image the last FROM builds on
+	maintainer  string                // maintainer name. could probably be removed.
+	cmdSet      bool                  // indicates is CMD was set in current Dockerfile
+	context     tarsum.BuilderContext // the context is a tarball that is uploaded by the client
+	contextPath string                // the path of the temporary directory the local context is unpacked to (server side)
+
+	allowedBuildArgs map[string]bool   // the build-time arguments declared with ARG in the current build stage
+	buildArgDefaults map[string]string // the defaults of the arguments declared in the current build stage
+	usedBuildArgs    map[string]bool   // the build-time arguments declared in any build stage
+
+	cacheSources []string // the IDs of the images of CacheFrom, nil to use the whole graph as cache
 
+	stageName   string            // the name of the current build stage, given with FROM ... AS
+	stageImages []string          // the images of the finished build stages, by index
+	stages      map[string]string // the images of the finished named build stages, by name
+
+	disableCommit bool // only the config is changed, as with `docker commit --change`
 }
 
 // Run the builder with the context. This is the lynchpin of this package. This
@@ -126,6 +161,9 @@ type Builder struct {
 // * Print a happy message and return the image ID.
 //
 func (b *Builder) Run(context io.Reader) (string, error) {
+	if b.DockerfileName == "" {
+		b.DockerfileName = api.DefaultDockerfileName
+	}
 	if err := b.readContext(context); err != nil {
 		return "", err
 	}
@@ -136,11 +174,19 @@ func (b *Builder) Run(context io.Reader) (string, error) {
 		}
 	}()
 
-	filename := path.Join(b.contextPath, "Dockerfile")
+	filename, err := symlink.FollowSymlinkInScope(path.Join(b.contextPath, b.DockerfileName), b.contextPath)
+	if err != nil {
+		return "", fmt.Errorf("The Dockerfile (%s) must be within the build context", b.DockerfileName)
+	}
 
 	fi, err := os.Stat(filename)
 	if os.IsNotExist(err) {
+		if b.DockerfileName != api.DefaultDockerfileName {
+			return "", fmt.Errorf("Cannot locate specified Dockerfile: %s", b.DockerfileName)
+		}
 		return "", fmt.Errorf("Cannot build a directory without a Dockerfile")
+	} else if err != nil {
+		return "", err
 	}
 	if fi.Size() == 0 {
 		return "", ErrDockerfileEmpty
@@ -163,6 +209,25 @@ func (b *Builder) Run(context io.Reader) (string, error) {
 	// some initializations that would not have been supplied by the caller.
 	b.Config = &runconfig.Config{}
 	b.TmpContainers = map[string]struct{}{}
+	b.allowedBuildArgs = map[string]bool{}
+	b.buildArgDefaults = map[string]string{}
+	b.usedBuildArgs = map[string]bool{}
+	b.stages = map[string]string{}
+	if b.BuildArgs == nil {
+		b.BuildArgs = map[string]string{}
+	}
+	if len(b.CacheFrom) > 0 {
+		// Only the given images are used as cache, even if none exists
+		b.cacheSources = []string{}
+	}
+	for _, name := range b.CacheFrom {
+		img, err := b.Daemon.Repositories().LookupImage(name)
+		if err != nil {
+			fmt.Fprintf(b.OutStream, " ---> [Warning] Cannot use %s as a cache source: %s\n", name, err)
+			continue
+		}
+		b.cacheSources = append(b.cacheSources, img.ID)
+	}
 
 	for i, n := range b.dockerfile.Children {
 		if err := b.dispatch(i, n); err != nil {
@@ -181,6 +246,23 @@ func (b *Builder) Run(context io.Reader) (string, error) {
 		return "", fmt.Errorf("No image was generated. Is your Dockerfile empty?\n")
 	}
 
+	var unused []string
+	for name := range b.BuildArgs {
+		if !b.usedBuildArgs[name] {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return "", fmt.Errorf("One or more build-args %v were not consumed, failing build.", unused)
+	}
+
+	if b.Squash {
+		if err := b.squash(); err != nil {
+			return "", err
+		}
+	}
+
 	fmt.Fprintf(b.OutStream, "Successfully built %s\n", utils.TruncateID(b.image))
 	return b.image, nil
 }
@@ -15,6 +15,7 @@ import (
 	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -22,15 +23,18 @@ import (
 	log "github.com/Sirupsen/logrus"
 	"github.com/docker/docker/builder/parser"
 	"github.com/docker/docker/daemon"
+	"github.com/docker/docker/dockerversion"
 	imagepkg "github.com/docker/docker/image"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/chrootarchive"
+	"github.com/docker/docker/pkg/fileutils"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/symlink"
 	"github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/pkg/tarsum"
 	"github.com/docker/docker/pkg/urlutil"
 	"github.com/docker/docker/registry"
+	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
 )
 
@@ -45,19 +49,75 @@ func (b *Builder) readContext(context io.Reader) error {
 		return err
 	}
 
-	if b.context, err = tarsum.NewTarSum(decompressedStream, true, tarsum.Version0); err != nil {
+	ts, err := tarsum.NewTarSum(decompressedStream, true, tarsum.Version0)
+	if err != nil {
 		return err
 	}
+	b.context = ts.(tarsum.BuilderContext)
 
 	if err := chrootarchive.Untar(b.context, tmpdirPath, nil); err != nil {
 		return err
 	}
 
 	b.contextPath = tmpdirPath
+	return b.excludeIgnoredFiles()
+}
+
+// excludeIgnoredFiles removes the files matched by the .dockerignore file
+// from the context. Clients may already have left them out, but this is not
+// the case of every caller of the API nor of remote contexts. Excluded files
+// can neither be added nor change the checksums of the build cache.
+func (b *Builder) excludeIgnoredFiles() error {
+	excludes, err := utils.ReadDockerIgnore(path.Join(b.contextPath, ".dockerignore"))
+	if err != nil || len(excludes) == 0 {
+		return err
+	}
+	pm, err := fileutils.NewPatternMatcher(excludes)
+	if err != nil {
+		return err
+	}
+	if pm.Matches(b.DockerfileName) {
+		return fmt.Errorf("Dockerfile was excluded by .dockerignore")
+	}
+
+	// Directories are removed once empty as some of their files may be
+	// included back by exceptions
+	var dirs []string
+	err = filepath.Walk(b.contextPath, func(filePath string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relFilePath, err := filepath.Rel(b.contextPath, filePath)
+		if err != nil {
+			return err
+		}
+		if !pm.Matches(relFilePath) {
+			return nil
+		}
+		b.context.Remove(relFilePath)
+		if f.IsDir() {
+			dirs = append(dirs, filePath)
+			return nil
+		}
+		return os.Remove(filePath)
+	})
+	if err != nil {
+		return err
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Remove(dirs[i]); err != nil {
+			if pe, ok := err.(*os.PathError); !ok || pe.Err != syscall.ENOTEMPTY {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
 func (b *Builder) commit(id string, autoCmd []string, comment string) error {
+	if b.disableCommit {
+		return nil
+	}
 	if b.image == "" {
 		return fmt.Errorf("Please provide a source image with `from` prior to commit")
 	}
@@ -104,6 +164,74 @@ func (b *Builder) commit(id string, autoCmd []string, comment string) error {
 	return nil
 }
 
+// squash replaces the image built so far by an image with a single layer on
+// top of the image of the last FROM. The layer holds all the changes of the
+// build, and the image keeps the config and the metadata of the last step.
+// The intermediate images are kept for the build cache.
+func (b *Builder) squash() error {
+	if b.image == b.fromImage {
+		// Nothing was built on top of FROM
+		return nil
+	}
+	graph := b.Daemon.Graph()
+	img, err := graph.Get(b.image)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(b.OutStream, "Squashing the layers on top of %s\n", utils.TruncateID(b.fromImage))
+
+	var (
+		driver = graph.Driver()
+		layer  archive.Archive
+	)
+	if img.Parent == b.fromImage {
+		if layer, err = driver.Diff(img.ID, b.fromImage); err != nil {
+			return err
+		}
+	} else {
+		// The graph drivers can only diff a layer with its parent, so
+		// compare the whole filesystems of the images.
+		root, err := driver.Get(img.ID, "")
+		if err != nil {
+			return err
+		}
+		defer driver.Put(img.ID)
+		fromRoot, err := driver.Get(b.fromImage, "")
+		if err != nil {
+			return err
+		}
+		defer driver.Put(b.fromImage)
+
+		changes, err := archive.ChangesDirs(root, fromRoot)
+		if err != nil {
+			return err
+		}
+		if layer, err = archive.ExportChanges(root, changes); err != nil {
+			return err
+		}
+	}
+	defer layer.Close()
+
+	squashed := &imagepkg.Image{
+		ID:              utils.GenerateRandomID(),
+		Parent:          b.fromImage,
+		Comment:         fmt.Sprintf("merge %s to %s", img.ID, b.fromImage),
+		Created:         time.Now().UTC(),
+		Container:       img.Container,
+		ContainerConfig: img.ContainerConfig,
+		DockerVersion:   dockerversion.VERSION,
+		Author:          img.Author,
+		Config:          img.Config,
+		Architecture:    img.Architecture,
+		OS:              img.OS,
+	}
+	if err := graph.Register(squashed, layer); err != nil {
+		return err
+	}
+	b.image = squashed.ID
+	return nil
+}
+
 type copyInfo struct {
 	origPath   string
 	destPath   string
@@ -112,8 +240,23 @@ type copyInfo struct {
 	tmpDir     string
 }
 
-func (b *Builder) runContextCommand(args []string, allowRemote bool, allowDecompression bool, cmdName string) error {
-	if b.context == nil {
+// runContextCommand copies files from the context, or from the image of a
+// build stage or the image called from if it is not empty.
+func (b *Builder) runContextCommand(args []string, allowRemote bool, allowDecompression bool, cmdName string, from string) error {
+	root := b.contextPath
+	var imageID string
+	if from != "" {
+		img, err := b.stageImage(from)
+		if err != nil {
+			return err
+		}
+		driver := b.Daemon.Graph().Driver()
+		if root, err = driver.Get(img.ID, ""); err != nil {
+			return err
+		}
+		defer driver.Put(img.ID)
+		imageID = img.ID
+	} else if b.context == nil {
 		return fmt.Errorf("No context given. Impossible to use %s", cmdName)
 	}
 
@@ -139,7 +282,12 @@ func (b *Builder) runContextCommand(args []string, allowRemote bool, allowDecomp
 	// do the copy (e.g. hash value if cached).  Don't actually do
 	// the copy until we've looked at all src files
 	for _, orig := range args[0 : len(args)-1] {
-		err := calcCopyInfo(b, cmdName, &copyInfos, orig, dest, allowRemote, allowDecompression)
+		var err error
+		if imageID != "" {
+			err = calcImageCopyInfo(&copyInfos, root, imageID, orig, dest)
+		} else {
+			err = calcCopyInfo(b, cmdName, &copyInfos, orig, dest, allowRemote, allowDecompression)
+		}
 		if err != nil {
 			return err
 		}
@@ -199,7 +347,7 @@ func (b *Builder) runContextCommand(args []string, allowRemote bool, allowDecomp
 	defer container.Unmount()
 
 	for _, ci := range copyInfos {
-		if err := b.addContext(container, ci.origPath, ci.destPath, ci.decompress); err != nil {
+		if err := b.addContext(container, root, ci.origPath, ci.destPath, ci.decompress); err != nil {
 			return err
 		}
 	}
@@ -390,6 +538,52 @@ func calcCopyInfo(b *Builder, cmdName string, cInfos *[]*copyInfo, origPath stri
 	return nil
 }
 
+// calcImageCopyInfo adds the files matching origPath in the rootfs of the
+// image imageID mounted at root. Images do not change, so the path of a file
+// in the image is enough for the cache.
+func calcImageCopyInfo(cInfos *[]*copyInfo, root, imageID, origPath, destPath string) error {
+	origPath = strings.TrimPrefix(path.Clean("/"+origPath), "/")
+
+	paths := []string{origPath}
+	if ContainsWildcards(origPath) {
+		matches, err := filepath.Glob(path.Join(root, origPath))
+		if err != nil {
+			return err
+		}
+		paths = paths[:0]
+		for _, match := range matches {
+			rel, err := filepath.Rel(root, match)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, rel)
+		}
+	}
+
+	for _, p := range paths {
+		resolved, err := symlink.FollowSymlinkInScope(path.Join(root, p), root)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Lstat(resolved); err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("%s: no such file or directory in %s", p, utils.TruncateID(imageID))
+			}
+			return err
+		}
+		rel, err := filepath.Rel(root, resolved)
+		if err != nil {
+			return err
+		}
+		*cInfos = append(*cInfos, &copyInfo{
+			origPath: rel,
+			destPath: destPath,
+			hash:     "image:" + imageID + ":" + rel,
+		})
+	}
+	return nil
+}
+
 func ContainsWildcards(name string) bool {
 	for i := 0; i < len(name); i++ {
 		ch := name[i]
@@ -433,8 +627,47 @@ func (b *Builder) pullImage(name string) (*imagepkg.Image, error) {
 	return image, nil
 }
 
+// finishStage records the image of the current build stage and resets the
+// state of the builder for the next one.
+func (b *Builder) finishStage() {
+	b.stageImages = append(b.stageImages, b.image)
+	if b.stageName != "" {
+		b.stages[b.stageName] = b.image
+	}
+
+	b.image = ""
+	b.Config = &runconfig.Config{}
+	b.maintainer = ""
+	b.cmdSet = false
+	b.allowedBuildArgs = map[string]bool{}
+	b.buildArgDefaults = map[string]string{}
+}
+
+// stageImage returns the image of the build stage called name or with the
+// index name, or the image name if there is no such stage.
+func (b *Builder) stageImage(name string) (*imagepkg.Image, error) {
+	if id, exists := b.stages[strings.ToLower(name)]; exists {
+		return b.Daemon.Graph().Get(id)
+	}
+	if index, err := strconv.Atoi(name); err == nil {
+		if index < 0 || index >= len(b.stageImages) {
+			return nil, fmt.Errorf("%s does not refer to an earlier build stage", name)
+		}
+		return b.Daemon.Graph().Get(b.stageImages[index])
+	}
+	if b.stageName != "" && strings.ToLower(name) == b.stageName {
+		return nil, fmt.Errorf("%s does not refer to an earlier build stage", name)
+	}
+	image, err := b.Daemon.Repositories().LookupImage(name)
+	if err != nil && b.Daemon.Graph().IsNotExist(err) {
+		image, err = b.pullImage(name)
+	}
+	return image, err
+}
+
 func (b *Builder) processImageFrom(img *imagepkg.Image) error {
 	b.image = img.ID
+	b.fromImage = img.ID
 
 	if img.Config != nil {
 		b.Config = img.Config
@@ -486,7 +719,7 @@ func (b *Builder) processImageFrom(img *imagepkg.Image) error {
 // is any error, it returns `(false, err)`.
 func (b *Builder) probeCache() (bool, error) {
 	if b.UtilizeCache {
-		if cache, err := b.Daemon.ImageGetCached(b.image, b.Config); err != nil {
+		if cache, err := b.Daemon.ImageGetCached(b.image, b.Config, b.cacheSources); err != nil {
 			return false, err
 		} else if cache != nil {
 			fmt.Fprintf(b.OutStream, " ---> Using cache\n")
@@ -578,11 +811,11 @@ func (b *Builder) checkPathForAddition(orig string) error {
 	return nil
 }
 
-func (b *Builder) addContext(container *daemon.Container, orig, dest string, decompress bool) error {
+func (b *Builder) addContext(container *daemon.Container, root, orig, dest string, decompress bool) error {
 	var (
 		err        error
 		destExists = true
-		origPath   = path.Join(b.contextPath, orig)
+		origPath   = path.Join(root, orig)
 		destPath   = path.Join(container.RootfsPath(), dest)
 	)
 
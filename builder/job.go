@@ -1,21 +1,46 @@
 package builder
 
 import (
+	"bufio"
+	"encoding/json"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"strings"
 
+	"github.com/docker/docker/api"
+	"github.com/docker/docker/builder/parser"
 	"github.com/docker/docker/daemon"
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/graph"
+	"github.com/docker/docker/nat"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/urlutil"
 	"github.com/docker/docker/registry"
+	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
 )
 
+// tarHeaderSize is the number of bytes of a context read to detect whether
+// it is an archive.
+const tarHeaderSize = 512
+
+// validCommitCommands are the instructions which can be given with
+// `docker commit --change` and `docker import --change`.
+var validCommitCommands = map[string]bool{
+	"cmd":        true,
+	"entrypoint": true,
+	"env":        true,
+	"expose":     true,
+	"onbuild":    true,
+	"user":       true,
+	"volume":     true,
+	"workdir":    true,
+}
+
 type BuilderJob struct {
 	Engine *engine.Engine
 	Daemon *daemon.Daemon
@@ -23,6 +48,7 @@ type BuilderJob struct {
 
 func (b *BuilderJob) Install() {
 	b.Engine.Register("build", b.CmdBuild)
+	b.Engine.Register("build_config", b.CmdBuildConfig)
 }
 
 func (b *BuilderJob) CmdBuild(job *engine.Job) engine.Status {
@@ -32,18 +58,28 @@ func (b *BuilderJob) CmdBuild(job *engine.Job) engine.Status {
 	var (
 		remoteURL      = job.Getenv("remote")
 		repoName       = job.Getenv("t")
+		dockerfileName = job.Getenv("dockerfile")
 		suppressOutput = job.GetenvBool("q")
 		noCache        = job.GetenvBool("nocache")
 		rm             = job.GetenvBool("rm")
 		forceRm        = job.GetenvBool("forcerm")
 		pull           = job.GetenvBool("pull")
+		squash         = job.GetenvBool("squash")
 		authConfig     = &registry.AuthConfig{}
 		configFile     = &registry.ConfigFile{}
+		buildArgs      = map[string]string{}
+		cacheFrom      []string
 		tag            string
 		context        io.ReadCloser
 	)
 	job.GetenvJson("authConfig", authConfig)
 	job.GetenvJson("configFile", configFile)
+	job.GetenvJson("buildargs", &buildArgs)
+	job.GetenvJson("cachefrom", &cacheFrom)
+
+	if dockerfileName == "" {
+		dockerfileName = api.DefaultDockerfileName
+	}
 
 	repoName, tag = parsers.ParseRepositoryTag(repoName)
 	if repoName != "" {
@@ -84,15 +120,25 @@ func (b *BuilderJob) CmdBuild(job *engine.Job) engine.Status {
 			return job.Error(err)
 		}
 		defer f.Body.Close()
-		dockerFile, err := ioutil.ReadAll(f.Body)
-		if err != nil {
+		// The URL is either a tarball of the context or a Dockerfile
+		body := bufio.NewReader(f.Body)
+		magic, err := body.Peek(tarHeaderSize)
+		if err != nil && err != io.EOF {
 			return job.Error(err)
 		}
-		c, err := archive.Generate("Dockerfile", string(dockerFile))
-		if err != nil {
-			return job.Error(err)
+		if archive.IsArchive(magic) {
+			context = ioutil.NopCloser(body)
+		} else {
+			dockerFile, err := ioutil.ReadAll(body)
+			if err != nil {
+				return job.Error(err)
+			}
+			c, err := archive.Generate(dockerfileName, string(dockerFile))
+			if err != nil {
+				return job.Error(err)
+			}
+			context = c
 		}
-		context = c
 	}
 	defer context.Close()
 
@@ -114,10 +160,14 @@ func (b *BuilderJob) CmdBuild(job *engine.Job) engine.Status {
 		Remove:          rm,
 		ForceRemove:     forceRm,
 		Pull:            pull,
+		Squash:          squash,
 		OutOld:          job.Stdout,
 		StreamFormatter: sf,
 		AuthConfig:      authConfig,
 		AuthConfigFile:  configFile,
+		BuildArgs:       buildArgs,
+		CacheFrom:       cacheFrom,
+		DockerfileName:  dockerfileName,
 	}
 
 	id, err := builder.Run(context)
@@ -130,3 +180,72 @@ func (b *BuilderJob) CmdBuild(job *engine.Job) engine.Status {
 	}
 	return engine.StatusOK
 }
+
+// CmdBuildConfig applies the Dockerfile instructions of the "changes" list
+// to the "config" and writes the resulting config.
+func (b *BuilderJob) CmdBuildConfig(job *engine.Job) engine.Status {
+	if len(job.Args) != 0 {
+		return job.Errorf("Usage: %s\n", job.Name)
+	}
+	config := &runconfig.Config{}
+	if err := job.GetenvJson("config", config); err != nil {
+		return job.Error(err)
+	}
+	config, err := BuildFromConfig(b.Daemon, config, job.GetenvList("changes"))
+	if err != nil {
+		return job.Error(err)
+	}
+	if err := json.NewEncoder(job.Stdout).Encode(config); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// BuildFromConfig returns a copy of config with the Dockerfile instructions
+// of changes applied to it. No image is created.
+func BuildFromConfig(d *daemon.Daemon, config *runconfig.Config, changes []string) (*runconfig.Config, error) {
+	var nodes []*parser.Node
+	for _, change := range changes {
+		ast, err := parser.Parse(strings.NewReader(change))
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range ast.Children {
+			if !validCommitCommands[n.Value] {
+				return nil, fmt.Errorf("Bad parameter: %s is not a valid change command", strings.ToUpper(n.Value))
+			}
+			nodes = append(nodes, n)
+		}
+	}
+
+	// The dispatchers change the slices and maps of the config in place
+	newConfig := *config
+	newConfig.Env = append([]string(nil), config.Env...)
+	newConfig.OnBuild = append([]string(nil), config.OnBuild...)
+	if config.ExposedPorts != nil {
+		newConfig.ExposedPorts = nat.PortSet{}
+		for port := range config.ExposedPorts {
+			newConfig.ExposedPorts[port] = struct{}{}
+		}
+	}
+	if config.Volumes != nil {
+		newConfig.Volumes = map[string]struct{}{}
+		for volume := range config.Volumes {
+			newConfig.Volumes[volume] = struct{}{}
+		}
+	}
+
+	builder := &Builder{
+		Daemon:        d,
+		OutStream:     ioutil.Discard,
+		ErrStream:     ioutil.Discard,
+		Config:        &newConfig,
+		disableCommit: true,
+	}
+	for i, n := range nodes {
+		if err := builder.dispatch(i, n); err != nil {
+			return nil, err
+		}
+	}
+	return builder.Config, nil
+}
@@ -41,7 +41,7 @@ func parseSubCommand(rest string) (*Node, map[string]bool, error) {
 
 // parse environment like statements. Note that this does *not* handle
 // variable interpolation, which will be handled in the evaluator.
-func parseEnv(rest string) (*Node, map[string]bool, error) {
+func parseNameVal(rest string, key string) (*Node, map[string]bool, error) {
 	// This is kind of tricky because we need to support the old
 	// variant:   ENV name value
 	// as well as the new one:    ENV name=value ...
@@ -134,7 +134,7 @@ func parseEnv(rest string) (*Node, map[string]bool, error) {
 	}
 
 	if len(words) == 0 {
-		return nil, nil, fmt.Errorf("ENV must have some arguments")
+		return nil, nil, fmt.Errorf("%s must have some arguments", key)
 	}
 
 	// Old format (ENV name value)
@@ -146,7 +146,7 @@ func parseEnv(rest string) (*Node, map[string]bool, error) {
 		strs := TOKEN_WHITESPACE.Split(rest, 2)
 
 		if len(strs) < 2 {
-			return nil, nil, fmt.Errorf("ENV must have two arguments")
+			return nil, nil, fmt.Errorf("%s must have two arguments", key)
 		}
 
 		node.Value = strs[0]
@@ -179,6 +179,14 @@ func parseEnv(rest string) (*Node, map[string]bool, error) {
 	return rootnode, nil, nil
 }
 
+func parseEnv(rest string) (*Node, map[string]bool, error) {
+	return parseNameVal(rest, "ENV")
+}
+
+func parseLabel(rest string) (*Node, map[string]bool, error) {
+	return parseNameVal(rest, "LABEL")
+}
+
 // parses a whitespace-delimited set of arguments. The result is effectively a
 // linked list of string arguments.
 func parseStringsWhitespaceDelimited(rest string) (*Node, map[string]bool, error) {
@@ -276,3 +284,45 @@ func parseMaybeJSONToList(rest string) (*Node, map[string]bool, error) {
 
 	return parseStringsWhitespaceDelimited(rest)
 }
+
+// parseHealthcheck parses the options and the type of a HEALTHCHECK, then
+// its command like CMD.
+//
+// HEALTHCHECK --retries=3 CMD check -> (healthcheck "--retries=3" "CMD" "check")
+//
+func parseHealthcheck(rest string) (*Node, map[string]bool, error) {
+	rootnode := &Node{}
+	node := rootnode
+	rest = strings.TrimSpace(rest)
+	for {
+		parts := TOKEN_WHITESPACE.Split(rest, 2)
+		node.Value, rest = parts[0], ""
+		if len(parts) == 2 {
+			rest = parts[1]
+		}
+		if !strings.HasPrefix(node.Value, "--") {
+			break
+		}
+		node.Next = &Node{}
+		node = node.Next
+	}
+
+	switch typ := strings.ToUpper(node.Value); typ {
+	case "NONE":
+		if rest != "" {
+			return nil, nil, fmt.Errorf("HEALTHCHECK NONE takes no arguments")
+		}
+		node.Value = typ
+		return rootnode, nil, nil
+	case "CMD":
+		cmd, attrs, err := parseMaybeJSON(rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		node.Value = typ
+		node.Next = cmd
+		return rootnode, attrs, nil
+	default:
+		return nil, nil, fmt.Errorf("Unknown type %q in HEALTHCHECK (try CMD)", typ)
+	}
+}
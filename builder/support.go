@@ -2,6 +2,7 @@ package builder
 
 import (
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -33,18 +34,65 @@ func (b *Builder) replaceEnv(str string) string {
 		match = match[strings.Index(match, "$"):]
 		matchKey := strings.Trim(match, "${}")
 
-		for _, keyval := range b.Config.Env {
-			tmp := strings.SplitN(keyval, "=", 2)
-			if tmp[0] == matchKey {
-				str = strings.Replace(str, match, tmp[1], -1)
-				break
-			}
+		if value, ok := b.lookupVar(matchKey); ok {
+			str = strings.Replace(str, match, value, -1)
 		}
 	}
 
 	return str
 }
 
+// lookupEnv returns the value of the variable key set with ENV.
+func (b *Builder) lookupEnv(key string) (string, bool) {
+	for _, keyval := range b.Config.Env {
+		tmp := strings.SplitN(keyval, "=", 2)
+		if tmp[0] == key {
+			return tmp[1], true
+		}
+	}
+	return "", false
+}
+
+// lookupVar returns the value of the variable key set with ENV or, if there
+// is none, of the build-time argument key declared with ARG.
+func (b *Builder) lookupVar(key string) (string, bool) {
+	if value, ok := b.lookupEnv(key); ok {
+		return value, true
+	}
+	return b.buildArg(key)
+}
+
+// buildArg returns the value of the build-time argument name declared with
+// ARG in the current build stage, given with --build-arg or by its default.
+func (b *Builder) buildArg(name string) (string, bool) {
+	if !b.allowedBuildArgs[name] {
+		return "", false
+	}
+	if value, ok := b.BuildArgs[name]; ok {
+		return value, true
+	}
+	value, ok := b.buildArgDefaults[name]
+	return value, ok
+}
+
+// buildArgsEnv returns the build-time arguments which are set in the
+// environment of RUN. ENV variables take precedence over them.
+func (b *Builder) buildArgsEnv() []string {
+	var env []string
+	for name := range b.allowedBuildArgs {
+		value, ok := b.buildArg(name)
+		if !ok {
+			continue
+		}
+		if _, set := b.lookupEnv(name); set {
+			continue
+		}
+		env = append(env, name+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
+
 func handleJsonArgs(args []string, attributes map[string]bool) []string {
 	if len(args) == 0 {
 		return []string{}
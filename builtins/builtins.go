@@ -5,7 +5,7 @@ import (
 
 	"github.com/docker/docker/api"
 	apiserver "github.com/docker/docker/api/server"
-	"github.com/docker/docker/daemon/networkdriver/bridge"
+	"github.com/docker/docker/daemon/networkdriver"
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/events"
@@ -42,6 +42,7 @@ func remote(eng *engine.Engine) error {
 //
 // * Pluggable storage drivers including aufs, vfs, lvm and btrfs.
 // * Pluggable execution drivers including lxc and chroot.
+// * Pluggable network drivers including bridge, host and null.
 //
 // In practice `daemon` still includes most core Docker components, including:
 //
@@ -53,7 +54,7 @@ func remote(eng *engine.Engine) error {
 // These components should be broken off into plugins of their own.
 //
 func daemon(eng *engine.Engine) error {
-	return eng.Register("init_networkdriver", bridge.InitDriver)
+	return eng.Register("init_networkdriver", networkdriver.InitDriver)
 }
 
 // builtins jobs independent of any subsystem
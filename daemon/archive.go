@@ -0,0 +1,256 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/engine"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/chrootarchive"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/pkg/symlink"
+)
+
+// ContainerStatPath writes information about a path in a container: its
+// name, size, mode, modification time and the target of a symlink.
+func (daemon *Daemon) ContainerStatPath(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s CONTAINER PATH", job.Name)
+	}
+	name, path := job.Args[0], job.Args[1]
+	container := daemon.Get(name)
+	if container == nil {
+		return job.Errorf("No such container: %s", name)
+	}
+	stat, err := container.StatPath(path)
+	if err != nil {
+		return pathError(job, name, path, err)
+	}
+	if err := json.NewEncoder(job.Stdout).Encode(stat); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// ContainerArchivePath writes a tar archive of a path in a container.
+func (daemon *Daemon) ContainerArchivePath(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s CONTAINER PATH", job.Name)
+	}
+	name, path := job.Args[0], job.Args[1]
+	container := daemon.Get(name)
+	if container == nil {
+		return job.Errorf("No such container: %s", name)
+	}
+	data, err := container.ArchivePath(path)
+	if err != nil {
+		return pathError(job, name, path, err)
+	}
+	defer data.Close()
+	if _, err := io.Copy(job.Stdout, data); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// ContainerExtractToDir extracts the tar archive read from stdin in a
+// directory of a container.
+func (daemon *Daemon) ContainerExtractToDir(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s CONTAINER PATH", job.Name)
+	}
+	name, path := job.Args[0], job.Args[1]
+	container := daemon.Get(name)
+	if container == nil {
+		return job.Errorf("No such container: %s", name)
+	}
+	if err := container.ExtractToDir(path, job.GetenvBool("NoOverwriteDirNonDir"), job.Stdin); err != nil {
+		return pathError(job, name, path, err)
+	}
+	return engine.StatusOK
+}
+
+// pathError fails the job with err, without the path on the host when the
+// path does not exist in the container.
+func pathError(job *engine.Job, name, path string, err error) engine.Status {
+	if os.IsNotExist(err) {
+		return job.Errorf("No such file or directory in container %s: %s", name, path)
+	}
+	return job.Error(err)
+}
+
+// PathStat is the information about a path in a container.
+type PathStat struct {
+	Name       string
+	Size       int64
+	Mode       os.FileMode
+	Mtime      time.Time
+	LinkTarget string // Target of the symlink at the path
+}
+
+// StatPath returns information about the path in the container. A symlink
+// at the end of the path is not followed.
+func (container *Container) StatPath(path string) (*PathStat, error) {
+	if err := container.Mount(); err != nil {
+		return nil, err
+	}
+	defer container.Unmount()
+
+	resolved, _, err := container.resolvePath(path, false)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Lstat(resolved)
+	if err != nil {
+		return nil, err
+	}
+	var linkTarget string
+	if fi.Mode()&os.ModeSymlink != 0 {
+		if linkTarget, err = os.Readlink(resolved); err != nil {
+			return nil, err
+		}
+	}
+
+	return &PathStat{
+		Name:       filepath.Base(filepath.Join("/", path)),
+		Size:       fi.Size(),
+		Mode:       fi.Mode(),
+		Mtime:      fi.ModTime().UTC(),
+		LinkTarget: linkTarget,
+	}, nil
+}
+
+// ArchivePath returns a tar archive of the path in the container. A symlink
+// at the end of the path is archived as is.
+func (container *Container) ArchivePath(path string) (io.ReadCloser, error) {
+	if err := container.Mount(); err != nil {
+		return nil, err
+	}
+
+	resolved, mnt, err := container.resolvePath(path, false)
+	if err != nil {
+		container.Unmount()
+		return nil, err
+	}
+	if _, err := os.Lstat(resolved); err != nil {
+		container.Unmount()
+		return nil, err
+	}
+
+	options := &archive.TarOptions{Compression: archive.Uncompressed}
+	dir, base := filepath.Split(resolved)
+	if resolved == container.basefs {
+		// Archive the content of the root directory
+		dir = resolved
+	} else {
+		options.Includes = []string{base}
+		// The root of a volume has another name on the host
+		if mnt != nil && resolved == filepath.Clean(mnt.volume.Path) {
+			options.Name = filepath.Base(mnt.MountToPath)
+		}
+	}
+	data, err := archive.TarWithOptions(filepath.Clean(dir), options)
+	if err != nil {
+		container.Unmount()
+		return nil, err
+	}
+	return ioutils.NewReadCloserWrapper(data, func() error {
+		err := data.Close()
+		container.Unmount()
+		return err
+	}), nil
+}
+
+// ExtractToDir extracts the tar archive content in the directory at path in
+// the container. The archive is extracted in a chroot of the directory, so
+// that its symlinks cannot escape it, and its files keep their owners.
+func (container *Container) ExtractToDir(path string, noOverwriteDirNonDir bool, content io.Reader) error {
+	if err := container.Mount(); err != nil {
+		return err
+	}
+	defer container.Unmount()
+
+	resolved, mnt, err := container.resolvePath(path, true)
+	if err != nil {
+		return err
+	}
+	fi, err := os.Stat(resolved)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("Bad parameter: the extraction point %s is not a directory", path)
+	}
+	if mnt != nil && !mnt.Writable {
+		return fmt.Errorf("Bad parameter: the extraction point %s is in a read-only volume", path)
+	}
+
+	return chrootarchive.Untar(content, resolved, &archive.TarOptions{
+		NoOverwriteDirNonDir: noOverwriteDirNonDir,
+	})
+}
+
+// resolvePath returns the path on the host of the path in the container and
+// the volume it is in, if any. Symlinks are followed in the scope of the
+// rootfs or of the volume, the last element of the path only if followLast
+// is set.
+func (container *Container) resolvePath(path string, followLast bool) (string, *Mount, error) {
+	path = filepath.Join("/", path)
+	if mnt, rel := container.volumeMountFor(path); mnt != nil {
+		resolved, err := resolveInScope(mnt.volume.Path, rel, followLast)
+		return resolved, mnt, err
+	}
+
+	resolved, err := resolveInScope(container.basefs, path, followLast)
+	if err != nil {
+		return "", nil, err
+	}
+	// Volumes are only mounted in the namespace of the container, a
+	// symlink of the rootfs may point into one of them
+	rel, err := filepath.Rel(container.basefs, resolved)
+	if err != nil {
+		return "", nil, err
+	}
+	if mnt, rel := container.volumeMountFor(filepath.Join("/", rel)); mnt != nil {
+		resolved, err := resolveInScope(mnt.volume.Path, rel, followLast)
+		return resolved, mnt, err
+	}
+	return resolved, nil, nil
+}
+
+// volumeMountFor returns the volume mounted at the path or at one of its
+// parent directories, with the path relative to the mount point.
+func (container *Container) volumeMountFor(path string) (*Mount, string) {
+	var (
+		found *Mount
+		rel   string
+	)
+	for mountToPath, mnt := range container.VolumeMounts() {
+		r, err := filepath.Rel(mountToPath, path)
+		if err != nil || r == ".." || strings.HasPrefix(r, "../") {
+			continue
+		}
+		if found == nil || len(mountToPath) > len(found.MountToPath) {
+			found, rel = mnt, r
+		}
+	}
+	return found, rel
+}
+
+func resolveInScope(root, path string, followLast bool) (string, error) {
+	path = filepath.Join("/", path)
+	if followLast {
+		return symlink.FollowSymlinkInScope(filepath.Join(root, path), root)
+	}
+	dir, base := filepath.Split(path)
+	resolvedDir, err := symlink.FollowSymlinkInScope(filepath.Join(root, dir), root)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolvedDir, base), nil
+}
@@ -34,7 +34,7 @@ func (daemon *Daemon) ContainerAttach(job *engine.Job) engine.Status {
 	}
 
 	//logs
-	if logs {
+	if logs && container.getLogConfig().Type == "json-file" {
 		cLog, err := container.ReadLog("json")
 		if err != nil && os.IsNotExist(err) {
 			// Legacy logs
@@ -1,6 +1,9 @@
 package daemon
 
 import (
+	"bytes"
+	"encoding/json"
+
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/runconfig"
@@ -17,19 +20,32 @@ func (daemon *Daemon) ContainerCommit(job *engine.Job) engine.Status {
 		return job.Errorf("No such container: %s", name)
 	}
 
-	var (
-		config    = container.Config
-		newConfig runconfig.Config
-	)
-
+	var newConfig runconfig.Config
 	if err := job.GetenvJson("config", &newConfig); err != nil {
 		return job.Error(err)
 	}
 
-	if err := runconfig.Merge(&newConfig, config); err != nil {
+	// The changes apply to the config of the container, overridden by the
+	// optional --run config, and not to the --run config alone
+	if err := runconfig.Merge(&newConfig, container.Config); err != nil {
 		return job.Error(err)
 	}
 
+	if changes := job.GetenvList("changes"); len(changes) > 0 {
+		buildConfigJob := job.Eng.Job("build_config")
+		buildConfigJob.SetenvJson("config", &newConfig)
+		buildConfigJob.SetenvList("changes", changes)
+		stdoutBuffer := bytes.NewBuffer(nil)
+		buildConfigJob.Stdout.Add(stdoutBuffer)
+		if err := buildConfigJob.Run(); err != nil {
+			return job.Error(err)
+		}
+		newConfig = runconfig.Config{}
+		if err := json.NewDecoder(stdoutBuffer).Decode(&newConfig); err != nil {
+			return job.Error(err)
+		}
+	}
+
 	img, err := daemon.Commit(container, job.Getenv("repo"), job.Getenv("tag"), job.Getenv("comment"), job.Getenv("author"), job.GetenvBool("pause"), &newConfig)
 	if err != nil {
 		return job.Error(err)
@@ -19,6 +19,8 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/networkdriver"
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/links"
@@ -29,9 +31,12 @@ import (
 	"github.com/docker/docker/pkg/networkfs/etchosts"
 	"github.com/docker/docker/pkg/networkfs/resolvconf"
 	"github.com/docker/docker/pkg/promise"
+	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/pkg/symlink"
+	"github.com/docker/docker/pkg/ulimit"
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
+	"github.com/docker/docker/volumes"
 )
 
 const DefaultPathEnv = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
@@ -94,6 +99,12 @@ type Container struct {
 	activeLinks  map[string]*links.Link
 	monitor      *containerMonitor
 	execCommands *execStore
+
+	logDriver logger.Logger
+	logCopier *logger.Copier
+
+	// volumes of volume drivers mounted for the running container, by path
+	mountedVolumes map[string]*volumes.Volume
 }
 
 func (container *Container) FromDisk() error {
@@ -221,6 +232,10 @@ func populateCommand(c *Container, env []string) error {
 				IPAddress:   network.IPAddress,
 				IPPrefixLen: network.IPPrefixLen,
 				MacAddress:  network.MacAddress,
+
+				GlobalIPv6Address:   network.GlobalIPv6Address,
+				GlobalIPv6PrefixLen: network.GlobalIPv6PrefixLen,
+				IPv6Gateway:         network.IPv6Gateway,
 			}
 		}
 	case "container":
@@ -262,11 +277,51 @@ func populateCommand(c *Container, env []string) error {
 	// TODO: this can be removed after lxc-conf is fully deprecated
 	lxcConfig := mergeLxcConfIntoOptions(c.hostConfig)
 
+	// The ulimits of the container override the defaults of the daemon
+	ulimits := c.hostConfig.Ulimits
+	ulimitNames := make(map[string]bool)
+	for _, ul := range ulimits {
+		ulimitNames[ul.Name] = true
+	}
+	for name, ul := range c.daemon.config.Ulimits {
+		if !ulimitNames[name] {
+			ulimits = append(ulimits, ul)
+		}
+	}
+	var rlimits []*ulimit.Rlimit
+	for _, ul := range ulimits {
+		rl, err := ul.GetRlimit()
+		if err != nil {
+			return err
+		}
+		rlimits = append(rlimits, rl)
+	}
+
 	resources := &execdriver.Resources{
-		Memory:     c.Config.Memory,
-		MemorySwap: c.Config.MemorySwap,
-		CpuShares:  c.Config.CpuShares,
-		Cpuset:     c.Config.Cpuset,
+		Memory:            c.Config.Memory,
+		MemorySwap:        c.Config.MemorySwap,
+		MemoryReservation: c.Config.MemoryReservation,
+		KernelMemory:      c.Config.KernelMemory,
+		OomKillDisable:    c.Config.OomKillDisable,
+		CpuShares:         c.Config.CpuShares,
+		CpuQuota:          c.Config.CpuQuota,
+		CpuPeriod:         c.Config.CpuPeriod,
+		Cpuset:            c.Config.Cpuset,
+		BlkioWeight:       c.Config.BlkioWeight,
+		Rlimits:           rlimits,
+	}
+	var err error
+	if resources.BlkioThrottleReadBpsDevice, err = getThrottleDevices(c.hostConfig.BlkioDeviceReadBps); err != nil {
+		return err
+	}
+	if resources.BlkioThrottleWriteBpsDevice, err = getThrottleDevices(c.hostConfig.BlkioDeviceWriteBps); err != nil {
+		return err
+	}
+	if resources.BlkioThrottleReadIOpsDevice, err = getThrottleDevices(c.hostConfig.BlkioDeviceReadIOps); err != nil {
+		return err
+	}
+	if resources.BlkioThrottleWriteIOpsDevice, err = getThrottleDevices(c.hostConfig.BlkioDeviceWriteIOps); err != nil {
+		return err
 	}
 
 	processConfig := execdriver.ProcessConfig{
@@ -297,11 +352,30 @@ func populateCommand(c *Container, env []string) error {
 		MountLabel:         c.GetMountLabel(),
 		LxcConfig:          lxcConfig,
 		AppArmorProfile:    c.AppArmorProfile,
+		OomScoreAdj:        c.hostConfig.OomScoreAdj,
 	}
 
 	return nil
 }
 
+// getThrottleDevices resolves the paths of the throttled devices to their
+// major and minor numbers.
+func getThrottleDevices(throttles []runconfig.ThrottleDevice) ([]*execdriver.ThrottleDevice, error) {
+	var devs []*execdriver.ThrottleDevice
+	for _, throttle := range throttles {
+		device, err := devices.GetDevice(throttle.Path, "")
+		if err != nil {
+			return nil, fmt.Errorf("error gathering device information while throttling device %q: %s", throttle.Path, err)
+		}
+		devs = append(devs, &execdriver.ThrottleDevice{
+			Major: device.MajorNumber,
+			Minor: device.MinorNumber,
+			Rate:  throttle.Rate,
+		})
+	}
+	return devs, nil
+}
+
 func (container *Container) Start() (err error) {
 	container.Lock()
 	defer container.Unlock()
@@ -340,6 +414,9 @@ func (container *Container) Start() (err error) {
 	if err := container.prepareVolumes(); err != nil {
 		return err
 	}
+	if err := container.mountVolumes(); err != nil {
+		return err
+	}
 	linkedEnv, err := container.setupLinkedContainers()
 	if err != nil {
 		return err
@@ -444,9 +521,13 @@ func (container *Container) buildHostsFiles(IP string) error {
 		return err
 	}
 
-	for linkAlias, child := range children {
-		_, alias := path.Split(linkAlias)
-		extraContent = append(extraContent, etchosts.Record{Hosts: alias, IP: child.NetworkSettings.IPAddress})
+	// Links are resolved by the DNS server of the daemon when it is used
+	// so that their addresses are kept up to date
+	if !container.usesDnsServer() {
+		for linkAlias, child := range children {
+			_, alias := path.Split(linkAlias)
+			extraContent = append(extraContent, etchosts.Record{Hosts: alias, IP: child.NetworkSettings.IPAddress})
+		}
 	}
 
 	for _, extraHost := range container.hostConfig.ExtraHosts {
@@ -477,7 +558,7 @@ func (container *Container) AllocateNetwork() error {
 		eng = container.daemon.eng
 	)
 
-	job := eng.Job("allocate_interface", container.ID)
+	job := eng.Job("endpoint_create", networkdriver.DefaultNetworkName, container.ID)
 	job.Setenv("RequestedMac", container.Config.MacAddress)
 	if env, err = job.Stdout.AddEnv(); err != nil {
 		return err
@@ -492,12 +573,12 @@ func (container *Container) AllocateNetwork() error {
 
 	if container.Config.PortSpecs != nil {
 		if err = migratePortMappings(container.Config, container.hostConfig); err != nil {
-			eng.Job("release_interface", container.ID).Run()
+			eng.Job("endpoint_delete", networkdriver.DefaultNetworkName, container.ID).Run()
 			return err
 		}
 		container.Config.PortSpecs = nil
 		if err = container.WriteHostConfig(); err != nil {
-			eng.Job("release_interface", container.ID).Run()
+			eng.Job("endpoint_delete", networkdriver.DefaultNetworkName, container.ID).Run()
 			return err
 		}
 	}
@@ -527,7 +608,7 @@ func (container *Container) AllocateNetwork() error {
 
 	for port := range portSpecs {
 		if err = container.allocatePort(eng, port, bindings); err != nil {
-			eng.Job("release_interface", container.ID).Run()
+			eng.Job("endpoint_delete", networkdriver.DefaultNetworkName, container.ID).Run()
 			return err
 		}
 	}
@@ -535,10 +616,13 @@ func (container *Container) AllocateNetwork() error {
 
 	container.NetworkSettings.Ports = bindings
 	container.NetworkSettings.Bridge = env.Get("Bridge")
-	container.NetworkSettings.IPAddress = env.Get("IP")
+	container.NetworkSettings.IPAddress = env.Get("IPAddress")
 	container.NetworkSettings.IPPrefixLen = env.GetInt("IPPrefixLen")
 	container.NetworkSettings.MacAddress = env.Get("MacAddress")
 	container.NetworkSettings.Gateway = env.Get("Gateway")
+	container.NetworkSettings.GlobalIPv6Address = env.Get("GlobalIPv6Address")
+	container.NetworkSettings.GlobalIPv6PrefixLen = env.GetInt("GlobalIPv6PrefixLen")
+	container.NetworkSettings.IPv6Gateway = env.Get("IPv6Gateway")
 
 	return nil
 }
@@ -549,10 +633,13 @@ func (container *Container) ReleaseNetwork() {
 	}
 	eng := container.daemon.eng
 
-	job := eng.Job("release_interface", container.ID)
+	job := eng.Job("endpoint_delete", networkdriver.DefaultNetworkName, container.ID)
 	job.SetenvBool("overrideShutdown", true)
 	job.Run()
-	container.NetworkSettings = &NetworkSettings{}
+	// The container stays connected to its networks until it is
+	// disconnected
+	networks := container.releaseNetworks()
+	container.NetworkSettings = &NetworkSettings{Networks: networks}
 }
 
 func (container *Container) isNetworkAllocated() bool {
@@ -571,8 +658,9 @@ func (container *Container) RestoreNetwork() error {
 	eng := container.daemon.eng
 
 	// Re-allocate the interface with the same IP and MAC address.
-	job := eng.Job("allocate_interface", container.ID)
+	job := eng.Job("endpoint_create", networkdriver.DefaultNetworkName, container.ID)
 	job.Setenv("RequestedIP", container.NetworkSettings.IPAddress)
+	job.Setenv("RequestedIPv6", container.NetworkSettings.GlobalIPv6Address)
 	job.Setenv("RequestedMac", container.NetworkSettings.MacAddress)
 	if err := job.Run(); err != nil {
 		return err
@@ -603,6 +691,8 @@ func (container *Container) cleanup() {
 		log.Errorf("%v: Failed to umount filesystem: %v", container.ID, err)
 	}
 
+	container.unmountVolumes()
+
 	for _, eConfig := range container.execCommands.s {
 		container.daemon.unregisterExecCommand(eConfig)
 	}
@@ -681,14 +771,27 @@ func (container *Container) Kill() error {
 	return nil
 }
 
+// StopSignal returns the signal sent to stop the container, SIGTERM unless
+// its config sets another one.
+func (container *Container) StopSignal() int {
+	var stopSignal syscall.Signal
+	if container.Config.StopSignal != "" {
+		stopSignal, _ = signal.ParseSignal(container.Config.StopSignal)
+	}
+	if stopSignal <= 0 {
+		stopSignal, _ = signal.ParseSignal(signal.DefaultStopSignal)
+	}
+	return int(stopSignal)
+}
+
 func (container *Container) Stop(seconds int) error {
 	if !container.IsRunning() {
 		return nil
 	}
 
-	// 1. Send a SIGTERM
-	if err := container.KillSig(15); err != nil {
-		log.Infof("Failed to send SIGTERM to the process, force killing")
+	// 1. Send the stop signal, SIGTERM by default
+	if err := container.KillSig(container.StopSignal()); err != nil {
+		log.Infof("Failed to send signal %d to the process, force killing", container.StopSignal())
 		if err := container.KillSig(9); err != nil {
 			return err
 		}
@@ -696,7 +799,7 @@ func (container *Container) Stop(seconds int) error {
 
 	// 2. Wait for the process to exit on its own
 	if _, err := container.WaitStop(time.Duration(seconds) * time.Second); err != nil {
-		log.Infof("Container %v failed to exit within %d seconds of SIGTERM - using the force", container.ID, seconds)
+		log.Infof("Container %v failed to exit within %d seconds of signal %d - using the force", container.ID, seconds, container.StopSignal())
 		// 3. If it doesn't, then send SIGKILL
 		if err := container.Kill(); err != nil {
 			container.WaitStop(-1 * time.Second)
@@ -959,6 +1062,16 @@ func (container *Container) setupContainerDns() error {
 		return err
 	}
 
+	if container.usesDnsServer() {
+		dnsSearch := resolvconf.GetSearchDomains(resolvConf)
+		if len(config.DnsSearch) > 0 {
+			dnsSearch = config.DnsSearch
+		} else if len(daemon.config.DnsSearch) > 0 {
+			dnsSearch = daemon.config.DnsSearch
+		}
+		return resolvconf.Build(container.ResolvConfPath, []string{daemon.dnsServer.Addr().IP.String()}, dnsSearch)
+	}
+
 	if config.NetworkMode != "host" {
 		// check configurations for any container/daemon dns settings
 		if len(config.Dns) > 0 || len(daemon.config.Dns) > 0 || len(config.DnsSearch) > 0 || len(daemon.config.DnsSearch) > 0 {
@@ -992,18 +1105,16 @@ func (container *Container) setupContainerDns() error {
 }
 
 func (container *Container) updateParentsHosts() error {
-	parents, err := container.daemon.Parents(container.Name)
-	if err != nil {
-		return err
-	}
-	for _, cid := range parents {
-		if cid == "0" {
+	// The hosts file of a parent names the container by the alias of the
+	// link, which is kept when the container is renamed
+	for _, ref := range container.daemon.containerGraph.RefPaths(container.ID) {
+		if ref.ParentID == "0" {
 			continue
 		}
 
-		c := container.daemon.Get(cid)
+		c := container.daemon.Get(ref.ParentID)
 		if c != nil && !container.daemon.config.DisableNetwork && container.hostConfig.NetworkMode.IsPrivate() {
-			if err := etchosts.Update(c.HostsPath, container.NetworkSettings.IPAddress, container.Name[1:]); err != nil {
+			if err := etchosts.Update(c.HostsPath, container.NetworkSettings.IPAddress, ref.Name); err != nil {
 				log.Errorf("Failed to update /etc/hosts in parent container: %v", err)
 			}
 		}
@@ -1068,19 +1179,75 @@ func (container *Container) initializeNetworking() error {
 
 // Make sure the config is compatible with the current kernel
 func (container *Container) verifyDaemonSettings() {
-	if container.Config.Memory > 0 && !container.daemon.sysInfo.MemoryLimit {
-		log.Infof("WARNING: Your kernel does not support memory limit capabilities. Limitation discarded.")
-		container.Config.Memory = 0
-	}
-	if container.Config.Memory > 0 && !container.daemon.sysInfo.SwapLimit {
-		log.Infof("WARNING: Your kernel does not support swap limit capabilities. Limitation discarded.")
-		container.Config.MemorySwap = -1
+	for _, warning := range container.daemon.verifyResources(container.Config, container.hostConfig) {
+		log.Infof("WARNING: %s", warning)
 	}
 	if container.daemon.sysInfo.IPv4ForwardingDisabled {
 		log.Infof("WARNING: IPv4 forwarding is disabled. Networking will not work")
 	}
 }
 
+// verifyResources discards the resource limits that the kernel does not
+// support and returns a warning for each of them. hostConfig can be nil.
+func (daemon *Daemon) verifyResources(config *runconfig.Config, hostConfig *runconfig.HostConfig) []string {
+	var (
+		warnings []string
+		sysInfo  = daemon.SystemConfig()
+	)
+	if config.Memory > 0 && !sysInfo.MemoryLimit {
+		warnings = append(warnings, "Your kernel does not support memory limit capabilities. Limitation discarded.")
+		config.Memory = 0
+	}
+	if config.Memory > 0 && !sysInfo.SwapLimit {
+		warnings = append(warnings, "Your kernel does not support swap limit capabilities. Limitation discarded.")
+		config.MemorySwap = -1
+	}
+	if config.MemoryReservation > 0 && !sysInfo.MemoryLimit {
+		warnings = append(warnings, "Your kernel does not support memory soft limit capabilities. Limitation discarded.")
+		config.MemoryReservation = 0
+	}
+	if config.KernelMemory > 0 && !sysInfo.KernelMemory {
+		warnings = append(warnings, "Your kernel does not support kernel memory limit capabilities. Limitation discarded.")
+		config.KernelMemory = 0
+	}
+	if config.OomKillDisable && !sysInfo.OomKillDisable {
+		warnings = append(warnings, "Your kernel does not support oom kill disable. Setting discarded.")
+		config.OomKillDisable = false
+	}
+	if config.CpuPeriod > 0 && !sysInfo.CpuCfsPeriod {
+		warnings = append(warnings, "Your kernel does not support CPU cfs period. Period discarded.")
+		config.CpuPeriod = 0
+	}
+	if config.CpuQuota > 0 && !sysInfo.CpuCfsQuota {
+		warnings = append(warnings, "Your kernel does not support CPU cfs quota. Quota discarded.")
+		config.CpuQuota = 0
+	}
+	if config.BlkioWeight > 0 && !sysInfo.BlkioWeight {
+		warnings = append(warnings, "Your kernel does not support block IO weight. Weight discarded.")
+		config.BlkioWeight = 0
+	}
+	if hostConfig == nil {
+		return warnings
+	}
+	if len(hostConfig.BlkioDeviceReadBps) > 0 && !sysInfo.BlkioReadBpsDevice {
+		warnings = append(warnings, "Your kernel does not support block IO read limit in bytes per second. Limitation discarded.")
+		hostConfig.BlkioDeviceReadBps = nil
+	}
+	if len(hostConfig.BlkioDeviceWriteBps) > 0 && !sysInfo.BlkioWriteBpsDevice {
+		warnings = append(warnings, "Your kernel does not support block IO write limit in bytes per second. Limitation discarded.")
+		hostConfig.BlkioDeviceWriteBps = nil
+	}
+	if len(hostConfig.BlkioDeviceReadIOps) > 0 && !sysInfo.BlkioReadIOpsDevice {
+		warnings = append(warnings, "Your kernel does not support block IO read limit in IO per second. Limitation discarded.")
+		hostConfig.BlkioDeviceReadIOps = nil
+	}
+	if len(hostConfig.BlkioDeviceWriteIOps) > 0 && !sysInfo.BlkioWriteIOpsDevice {
+		warnings = append(warnings, "Your kernel does not support block IO write limit in IO per second. Limitation discarded.")
+		hostConfig.BlkioDeviceWriteIOps = nil
+	}
+	return warnings
+}
+
 func (container *Container) setupLinkedContainers() ([]string, error) {
 	var (
 		env    []string
@@ -1188,24 +1355,86 @@ func (container *Container) setupWorkingDirectory() error {
 	return nil
 }
 
-func (container *Container) startLoggingToDisk() error {
-	// Setup logging of stdout and stderr to disk
+// getLogConfig returns the log configuration of the container, falling back
+// to the daemon's default when none was given at creation.
+func (container *Container) getLogConfig() runconfig.LogConfig {
+	return container.daemon.logConfig(container.hostConfig.LogConfig)
+}
+
+func (container *Container) getLogger() (logger.Logger, error) {
+	cfg := container.getLogConfig()
+	c, err := logger.GetLogDriver(cfg.Type)
+	if err != nil {
+		return nil, err
+	}
 	pth, err := container.logPath("json")
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return c(logger.Context{
+		Config:        cfg.Config,
+		ContainerID:   container.ID,
+		ContainerName: container.Name,
+		LogPath:       pth,
+	})
+}
 
-	if err := container.daemon.LogToDisk(container.stdout, pth, "stdout"); err != nil {
-		return err
+// startLogging sets up the log driver of the container and starts copying
+// its stdout and stderr to it.
+func (container *Container) startLogging() error {
+	if container.getLogConfig().Type == "none" {
+		return nil
 	}
 
-	if err := container.daemon.LogToDisk(container.stderr, pth, "stderr"); err != nil {
+	l, err := container.getLogger()
+	if err != nil {
+		return fmt.Errorf("Failed to initialize logging driver: %v", err)
+	}
+
+	stdout, err := container.StdoutPipe()
+	if err != nil {
+		l.Close()
 		return err
 	}
+	stderr, err := container.StderrPipe()
+	if err != nil {
+		stdout.Close()
+		l.Close()
+		return err
+	}
+
+	container.logDriver = l
+	container.logCopier = logger.NewCopier(container.ID, map[string]io.Reader{"stdout": stdout, "stderr": stderr}, l)
+	container.logCopier.Run()
 
 	return nil
 }
 
+// stopLogging waits for the pending log lines to be written and closes the
+// log driver. The output streams must have been closed beforehand.
+func (container *Container) stopLogging() {
+	if container.logDriver == nil {
+		return
+	}
+	if container.logCopier != nil {
+		exit := make(chan struct{})
+		go func() {
+			container.logCopier.Wait()
+			close(exit)
+		}()
+		select {
+		case <-time.After(time.Second):
+			log.Warnf("%s: logger didn't exit in time: logs may be truncated", container.ID)
+		case <-exit:
+		}
+	}
+	if err := container.logDriver.Close(); err != nil {
+		log.Errorf("%s: Error closing logger: %s", container.ID, err)
+	}
+	container.logCopier = nil
+	container.logDriver = nil
+}
+
 func (container *Container) waitForStart() error {
 	container.monitor = newContainerMonitor(container, container.hostConfig.RestartPolicy)
 
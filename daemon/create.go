@@ -18,16 +18,8 @@ func (daemon *Daemon) ContainerCreate(job *engine.Job) engine.Status {
 		return job.Errorf("Usage: %s", job.Name)
 	}
 	config := runconfig.ContainerConfigFromJob(job)
-	if config.Memory != 0 && config.Memory < 4194304 {
-		return job.Errorf("Minimum memory limit allowed is 4MB")
-	}
-	if config.Memory > 0 && !daemon.SystemConfig().MemoryLimit {
-		job.Errorf("Your kernel does not support memory limit capabilities. Limitation discarded.\n")
-		config.Memory = 0
-	}
-	if config.Memory > 0 && !daemon.SystemConfig().SwapLimit {
-		job.Errorf("Your kernel does not support swap limit capabilities. Limitation discarded.\n")
-		config.MemorySwap = -1
+	if err := verifyResourceLimits(config); err != nil {
+		return job.Error(err)
 	}
 
 	var hostConfig *runconfig.HostConfig
@@ -37,6 +29,9 @@ func (daemon *Daemon) ContainerCreate(job *engine.Job) engine.Status {
 		// Older versions of the API don't provide a HostConfig.
 		hostConfig = nil
 	}
+	for _, warning := range daemon.verifyResources(config, hostConfig) {
+		job.Errorf("%s\n", warning)
+	}
 
 	container, buildWarnings, err := daemon.Create(config, hostConfig, name)
 	if err != nil {
@@ -83,6 +78,14 @@ func (daemon *Daemon) Create(config *runconfig.Config, hostConfig *runconfig.Hos
 	if warnings, err = daemon.mergeAndVerifyConfig(config, img); err != nil {
 		return nil, nil, err
 	}
+	if hostConfig != nil {
+		if err := validateLogConfig(daemon.logConfig(hostConfig.LogConfig)); err != nil {
+			return nil, nil, err
+		}
+		if err := validateOomScoreAdj(hostConfig.OomScoreAdj); err != nil {
+			return nil, nil, err
+		}
+	}
 	if hostConfig != nil && hostConfig.SecurityOpt == nil {
 		hostConfig.SecurityOpt, err = daemon.GenerateSecurityOpt(hostConfig.IpcMode)
 		if err != nil {
@@ -108,6 +111,31 @@ func (daemon *Daemon) Create(config *runconfig.Config, hostConfig *runconfig.Hos
 	}
 	return container, warnings, nil
 }
+
+// verifyResourceLimits checks that the resource limits of the config are in
+// the ranges accepted by the kernel.
+func verifyResourceLimits(config *runconfig.Config) error {
+	if config.Memory != 0 && config.Memory < 4194304 {
+		return fmt.Errorf("Minimum memory limit allowed is 4MB")
+	}
+	if config.Memory > 0 && config.MemoryReservation > config.Memory {
+		return fmt.Errorf("Minimum memory limit should be larger than memory reservation limit")
+	}
+	if config.KernelMemory != 0 && config.KernelMemory < 4194304 {
+		return fmt.Errorf("Minimum kernel memory limit allowed is 4MB")
+	}
+	if config.CpuPeriod != 0 && (config.CpuPeriod < 1000 || config.CpuPeriod > 1000000) {
+		return fmt.Errorf("CPU cfs period can not be less than 1ms (i.e. 1000) or larger than 1s (i.e. 1000000)")
+	}
+	if config.CpuQuota != 0 && config.CpuQuota < 1000 {
+		return fmt.Errorf("CPU cfs quota can not be less than 1ms (i.e. 1000)")
+	}
+	if config.BlkioWeight != 0 && (config.BlkioWeight < 10 || config.BlkioWeight > 1000) {
+		return fmt.Errorf("Range of blkio weight is from 10 to 1000")
+	}
+	return nil
+}
+
 func (daemon *Daemon) GenerateSecurityOpt(ipcMode runconfig.IpcMode) ([]string, error) {
 	if ipcMode.IsHost() {
 		return label.DisableSecOpt(), nil
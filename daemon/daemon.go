@@ -22,6 +22,8 @@ import (
 	"github.com/docker/docker/daemon/graphdriver"
 	_ "github.com/docker/docker/daemon/graphdriver/vfs"
 	_ "github.com/docker/docker/daemon/networkdriver/bridge"
+	_ "github.com/docker/docker/daemon/networkdriver/host"
+	_ "github.com/docker/docker/daemon/networkdriver/null"
 	"github.com/docker/docker/daemon/networkdriver/portallocator"
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/engine"
@@ -29,11 +31,13 @@ import (
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/broadcastwriter"
+	"github.com/docker/docker/pkg/dnsserver"
 	"github.com/docker/docker/pkg/graphdb"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/namesgenerator"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/parsers/kernel"
+	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/pkg/sysinfo"
 	"github.com/docker/docker/pkg/truncindex"
 	"github.com/docker/docker/runconfig"
@@ -100,37 +104,46 @@ type Daemon struct {
 	driver         graphdriver.Driver
 	execDriver     execdriver.Driver
 	trustStore     *trust.TrustStore
+	dnsServer      *dnsserver.Server
 }
 
 // Install installs daemon capabilities to eng.
 func (daemon *Daemon) Install(eng *engine.Engine) error {
 	// FIXME: remove ImageDelete's dependency on Daemon, then move to graph/
 	for name, method := range map[string]engine.Handler{
-		"attach":            daemon.ContainerAttach,
-		"commit":            daemon.ContainerCommit,
-		"container_changes": daemon.ContainerChanges,
-		"container_copy":    daemon.ContainerCopy,
-		"container_inspect": daemon.ContainerInspect,
-		"containers":        daemon.Containers,
-		"create":            daemon.ContainerCreate,
-		"rm":                daemon.ContainerRm,
-		"export":            daemon.ContainerExport,
-		"info":              daemon.CmdInfo,
-		"kill":              daemon.ContainerKill,
-		"logs":              daemon.ContainerLogs,
-		"pause":             daemon.ContainerPause,
-		"resize":            daemon.ContainerResize,
-		"restart":           daemon.ContainerRestart,
-		"start":             daemon.ContainerStart,
-		"stop":              daemon.ContainerStop,
-		"top":               daemon.ContainerTop,
-		"unpause":           daemon.ContainerUnpause,
-		"wait":              daemon.ContainerWait,
-		"image_delete":      daemon.ImageDelete, // FIXME: see above
-		"execCreate":        daemon.ContainerExecCreate,
-		"execStart":         daemon.ContainerExecStart,
-		"execResize":        daemon.ContainerExecResize,
-		"execInspect":       daemon.ContainerExecInspect,
+		"attach":             daemon.ContainerAttach,
+		"commit":             daemon.ContainerCommit,
+		"container_changes":  daemon.ContainerChanges,
+		"container_copy":     daemon.ContainerCopy,
+		"container_stat":     daemon.ContainerStatPath,
+		"container_archive":  daemon.ContainerArchivePath,
+		"container_extract":  daemon.ContainerExtractToDir,
+		"container_inspect":  daemon.ContainerInspect,
+		"containers":         daemon.Containers,
+		"create":             daemon.ContainerCreate,
+		"rm":                 daemon.ContainerRm,
+		"export":             daemon.ContainerExport,
+		"info":               daemon.CmdInfo,
+		"kill":               daemon.ContainerKill,
+		"logs":               daemon.ContainerLogs,
+		"pause":              daemon.ContainerPause,
+		"resize":             daemon.ContainerResize,
+		"restart":            daemon.ContainerRestart,
+		"start":              daemon.ContainerStart,
+		"stop":               daemon.ContainerStop,
+		"stats":              daemon.ContainerStats,
+		"top":                daemon.ContainerTop,
+		"unpause":            daemon.ContainerUnpause,
+		"rename":             daemon.ContainerRename,
+		"update":             daemon.ContainerUpdate,
+		"wait":               daemon.ContainerWait,
+		"image_delete":       daemon.ImageDelete, // FIXME: see above
+		"execCreate":         daemon.ContainerExecCreate,
+		"execStart":          daemon.ContainerExecStart,
+		"execResize":         daemon.ContainerExecResize,
+		"execInspect":        daemon.ContainerExecInspect,
+		"network_connect":    daemon.ContainerNetworkConnect,
+		"network_disconnect": daemon.ContainerNetworkDisconnect,
 	} {
 		if err := eng.Register(name, method); err != nil {
 			return err
@@ -142,6 +155,9 @@ func (daemon *Daemon) Install(eng *engine.Engine) error {
 	if err := daemon.trustStore.Install(eng); err != nil {
 		return err
 	}
+	if err := daemon.volumes.Install(eng); err != nil {
+		return err
+	}
 	// FIXME: this hack is necessary for legacy integration tests to access
 	// the daemon object.
 	eng.Hack_SetGlobalVar("httpapi.daemon", daemon)
@@ -290,15 +306,6 @@ func (daemon *Daemon) ensureName(container *Container) error {
 	return nil
 }
 
-func (daemon *Daemon) LogToDisk(src *broadcastwriter.BroadcastWriter, dst, stream string) error {
-	log, err := os.OpenFile(dst, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
-	if err != nil {
-		return err
-	}
-	src.AddWriter(log, stream)
-	return nil
-}
-
 func (daemon *Daemon) restore() error {
 	var (
 		debug         = (os.Getenv("DEBUG") != "" || os.Getenv("TEST") != "")
@@ -428,6 +435,11 @@ func (daemon *Daemon) mergeAndVerifyConfig(config *runconfig.Config, img *image.
 	if len(config.Entrypoint) == 0 && len(config.Cmd) == 0 {
 		return nil, fmt.Errorf("No command specified")
 	}
+	if config.StopSignal != "" {
+		if _, err := signal.ParseSignal(config.StopSignal); err != nil {
+			return nil, err
+		}
+	}
 	return warnings, nil
 }
 
@@ -734,7 +746,16 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 	if !config.EnableIptables && config.EnableIpMasq {
 		config.EnableIpMasq = false
 	}
+	if config.FixedCIDRv6 != "" && !config.EnableIPv6 {
+		return nil, fmt.Errorf("You specified --fixed-cidr-v6 without --ipv6. Please set --ipv6 to true.")
+	}
 	config.DisableNetwork = config.BridgeIface == disableNetworkBridge
+	if config.LogConfig.Type == "" {
+		config.LogConfig.Type = "json-file"
+	}
+	if err := validateLogConfig(config.LogConfig); err != nil {
+		return nil, err
+	}
 
 	// Claim the pidfile first, to avoid any and all unexpected race conditions.
 	// Some of the init doesn't need a pidfile lock - but let's not try to be smart.
@@ -856,7 +877,10 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 		job.Setenv("BridgeIface", config.BridgeIface)
 		job.Setenv("BridgeIP", config.BridgeIP)
 		job.Setenv("FixedCIDR", config.FixedCIDR)
+		job.SetenvBool("EnableIPv6", config.EnableIPv6)
+		job.Setenv("FixedCIDRv6", config.FixedCIDRv6)
 		job.Setenv("DefaultBindingIP", config.DefaultIp.String())
+		job.Setenv("NetworksPath", path.Join(config.Root, "networks"))
 
 		if err := job.Run(); err != nil {
 			return nil, err
@@ -918,6 +942,9 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 		eng:            eng,
 		trustStore:     t,
 	}
+	if config.EnableDnsServer && !config.DisableNetwork {
+		daemon.startDnsServer()
+	}
 	if err := daemon.restore(); err != nil {
 		return nil, err
 	}
@@ -936,6 +963,11 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 		if err := daemon.driver.Cleanup(); err != nil {
 			log.Errorf("daemon.driver.Cleanup(): %s", err.Error())
 		}
+		if daemon.dnsServer != nil {
+			if err := daemon.dnsServer.Close(); err != nil {
+				log.Errorf("daemon.dnsServer.Close(): %s", err)
+			}
+		}
 		if err := daemon.containerGraph.Close(); err != nil {
 			log.Errorf("daemon.containerGraph.Close(): %s", err.Error())
 		}
@@ -955,8 +987,9 @@ func (daemon *Daemon) shutdown() error {
 
 			go func() {
 				defer group.Done()
-				if err := c.KillSig(15); err != nil {
-					log.Debugf("kill 15 error for %s - %s", c.ID, err)
+				sig := c.StopSignal()
+				if err := c.KillSig(sig); err != nil {
+					log.Debugf("kill %d error for %s - %s", sig, c.ID, err)
 				}
 				c.WaitStop(-1 * time.Second)
 				log.Debugf("container stopped %s", c.ID)
@@ -1078,7 +1111,27 @@ func (daemon *Daemon) ContainerGraph() *graphdb.Database {
 	return daemon.containerGraph
 }
 
-func (daemon *Daemon) ImageGetCached(imgID string, config *runconfig.Config) (*image.Image, error) {
+// ImageGetCached returns the child of the image imgID which was created with
+// config, or nil if there is none. When cacheFrom is not nil, only the parent
+// chains of these images, which can have been pulled or loaded, are looked
+// up. Otherwise the most recent matching child in the graph is returned.
+func (daemon *Daemon) ImageGetCached(imgID string, config *runconfig.Config, cacheFrom []string) (*image.Image, error) {
+	if cacheFrom != nil {
+		for _, id := range cacheFrom {
+			for id != "" {
+				img, err := daemon.Graph().Get(id)
+				if err != nil {
+					return nil, err
+				}
+				if img.Parent == imgID && runconfig.Compare(&img.ContainerConfig, config) {
+					return img, nil
+				}
+				id = img.Parent
+			}
+		}
+		return nil, nil
+	}
+
 	// Retrieve all images
 	images, err := daemon.Graph().Map()
 	if err != nil {
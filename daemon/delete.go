@@ -71,6 +71,11 @@ func (daemon *Daemon) ContainerRm(job *engine.Job) engine.Status {
 
 func (daemon *Daemon) DeleteVolumes(volumeIDs map[string]struct{}) {
 	for id := range volumeIDs {
+		// Named volumes outlive the containers using them and can only be
+		// removed explicitly
+		if vol := daemon.volumes.Get(id); vol != nil && vol.Name != "" {
+			continue
+		}
 		if err := daemon.volumes.Delete(id); err != nil {
 			log.Infof("%s", err)
 			continue
@@ -93,6 +98,7 @@ func (daemon *Daemon) Destroy(container *Container) error {
 	if err := container.Stop(3); err != nil {
 		return err
 	}
+	container.disconnectNetworks()
 
 	// Deregister the container before removing its directory, to avoid race conditions
 	daemon.idIndex.Delete(container.ID)
@@ -0,0 +1,130 @@
+package daemon
+
+import (
+	"net"
+	"path"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/networkdriver"
+	"github.com/docker/docker/pkg/dnsserver"
+	"github.com/docker/docker/pkg/graphdb"
+	"github.com/docker/docker/pkg/networkfs/resolvconf"
+)
+
+// startDnsServer runs a DNS server on the address of the bridge which
+// answers the names of the containers and the aliases of their links. The
+// daemon falls back to writing the upstream servers in the resolv.conf of
+// the containers when the server cannot listen.
+func (daemon *Daemon) startDnsServer() {
+	job := daemon.eng.Job("network_inspect", networkdriver.DefaultNetworkName)
+	env, err := job.Stdout.AddEnv()
+	if err != nil {
+		log.Errorf("Could not start the DNS server: %s", err)
+		return
+	}
+	if err := job.Run(); err != nil {
+		log.Errorf("Could not start the DNS server: %s", err)
+		return
+	}
+	options := map[string]string{}
+	if err := env.GetJson("Options", &options); err != nil || options["Gateway"] == "" {
+		log.Errorf("Could not start the DNS server: no address on the bridge")
+		return
+	}
+
+	server, err := dnsserver.New(net.JoinHostPort(options["Gateway"], "53"), daemon.resolveName, daemon.upstreamDns)
+	if err != nil {
+		log.Errorf("Could not start the DNS server: %s", err)
+		return
+	}
+	daemon.dnsServer = server
+	go func() {
+		if err := server.Serve(); err != nil {
+			log.Errorf("DNS server stopped: %s", err)
+		}
+	}()
+}
+
+// usesDnsServer returns whether the names are resolved by the DNS server of
+// the daemon in the container rather than with its hosts file.
+func (container *Container) usesDnsServer() bool {
+	return container.daemon.dnsServer != nil && container.hostConfig.NetworkMode.IsPrivate() && len(container.hostConfig.Dns) == 0
+}
+
+// resolveName looks up the running container called name by the container
+// with the address client. Link aliases of the client take precedence over
+// container names.
+func (daemon *Daemon) resolveName(client net.IP, name string) []net.IP {
+	if name == "" || strings.Contains(name, "/") {
+		return nil
+	}
+	parents := []string{"/"}
+	if c := daemon.containerByIP(client); c != nil {
+		parents = append([]string{c.Name}, parents...)
+	}
+	for _, parent := range parents {
+		entity := daemon.lookupName(parent, name)
+		if entity == nil {
+			continue
+		}
+		c := daemon.Get(entity.ID())
+		if c == nil || !c.IsRunning() {
+			continue
+		}
+		var ips []net.IP
+		for _, addr := range []string{c.NetworkSettings.IPAddress, c.NetworkSettings.GlobalIPv6Address} {
+			if ip := net.ParseIP(addr); ip != nil {
+				ips = append(ips, ip)
+			}
+		}
+		if len(ips) > 0 {
+			return ips
+		}
+	}
+	return nil
+}
+
+// lookupName returns the entity called name under parent in the graph of
+// the containers. DNS names are case insensitive while the names in the
+// graph are not, so a name which only differs by its case matches when
+// there is no exact match.
+func (daemon *Daemon) lookupName(parent, name string) *graphdb.Entity {
+	if entity := daemon.containerGraph.Get(path.Join(parent, name)); entity != nil {
+		return entity
+	}
+	children, err := daemon.containerGraph.Children(parent, 0)
+	if err != nil {
+		return nil
+	}
+	for _, child := range children {
+		if strings.EqualFold(child.Edge.Name, name) {
+			return child.Entity
+		}
+	}
+	return nil
+}
+
+func (daemon *Daemon) containerByIP(ip net.IP) *Container {
+	for _, c := range daemon.List() {
+		if c.IsRunning() && (c.NetworkSettings.IPAddress == ip.String() || c.NetworkSettings.GlobalIPv6Address == ip.String()) {
+			return c
+		}
+	}
+	return nil
+}
+
+// upstreamDns returns the servers which resolve the names unknown to the
+// daemon. As queries are sent by the daemon, local servers of the host can
+// be used.
+func (daemon *Daemon) upstreamDns() []string {
+	if len(daemon.config.Dns) > 0 {
+		return daemon.config.Dns
+	}
+	if resolvConf, err := resolvconf.Get(); err == nil {
+		if dns := resolvconf.GetNameservers(resolvConf); len(dns) > 0 {
+			return dns
+		}
+	}
+	return DefaultDns
+}
@@ -0,0 +1,75 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/docker/docker/pkg/graphdb"
+	"github.com/docker/docker/pkg/truncindex"
+)
+
+func TestResolveNameIgnoresCase(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-dns-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	graph, err := graphdb.NewSqliteConn(path.Join(root, "linkgraph.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer graph.Close()
+
+	daemon := &Daemon{
+		containers:     &contStore{s: make(map[string]*Container)},
+		idIndex:        truncindex.NewTruncIndex(nil),
+		containerGraph: graph,
+	}
+	for _, c := range []struct{ id, name, ip string }{
+		{"1", "/WebApp", "172.17.0.5"},
+		{"2", "/client", "172.17.0.6"},
+		{"3", "/db", "172.17.0.7"},
+	} {
+		container := &Container{
+			ID:              c.id,
+			Name:            c.name,
+			State:           NewState(),
+			NetworkSettings: &NetworkSettings{IPAddress: c.ip},
+		}
+		container.SetRunning(1)
+		daemon.containers.Add(c.id, container)
+		daemon.idIndex.Add(c.id)
+		if _, err := graph.Set(c.name, c.id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The client links to db as Database
+	if _, err := graph.Set("/client/Database", "3"); err != nil {
+		t.Fatal(err)
+	}
+
+	client := net.ParseIP("172.17.0.6")
+	for name, expected := range map[string]string{
+		"WebApp":   "172.17.0.5",
+		"webapp":   "172.17.0.5",
+		"WEBAPP":   "172.17.0.5",
+		"DB":       "172.17.0.7",
+		"database": "172.17.0.7",
+		"Database": "172.17.0.7",
+	} {
+		ips := daemon.resolveName(client, name)
+		if len(ips) != 1 || ips[0].String() != expected {
+			t.Fatalf("Expected %s to resolve to %s, got %v", name, expected, ips)
+		}
+	}
+	if ips := daemon.resolveName(client, "unknown"); ips != nil {
+		t.Fatalf("Expected an unknown name not to resolve, got %v", ips)
+	}
+	// Link aliases are only visible to the linking container
+	if ips := daemon.resolveName(net.ParseIP("172.17.0.5"), "database"); ips != nil {
+		t.Fatalf("Expected the alias of another container not to resolve, got %v", ips)
+	}
+}
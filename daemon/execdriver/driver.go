@@ -2,10 +2,14 @@ package execdriver
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"time"
 
+	"github.com/docker/docker/pkg/ulimit"
+	"github.com/docker/libcontainer"
 	"github.com/docker/libcontainer/devices"
 )
 
@@ -61,6 +65,8 @@ type Driver interface {
 	GetPidsForContainer(id string) ([]int, error) // Returns a list of pids for the given container.
 	Terminate(c *Command) error                   // kill it with fire
 	Clean(id string) error                        // clean all traces of container exec
+	Stats(id string) (*ResourceStats, error)      // Get resource stats for a running container
+	Update(c *Command) error                      // Apply the resources of c to the running container
 }
 
 // Network settings of the container
@@ -78,18 +84,54 @@ type Ipc struct {
 }
 
 type NetworkInterface struct {
-	Gateway     string `json:"gateway"`
-	IPAddress   string `json:"ip"`
-	IPPrefixLen int    `json:"ip_prefix_len"`
-	MacAddress  string `json:"mac_address"`
-	Bridge      string `json:"bridge"`
+	Gateway             string `json:"gateway"`
+	IPAddress           string `json:"ip"`
+	IPPrefixLen         int    `json:"ip_prefix_len"`
+	MacAddress          string `json:"mac_address"`
+	Bridge              string `json:"bridge"`
+	GlobalIPv6Address   string `json:"global_ipv6"`
+	GlobalIPv6PrefixLen int    `json:"global_ipv6_prefix_len"`
+	IPv6Gateway         string `json:"ipv6_gateway"`
 }
 
 type Resources struct {
-	Memory     int64  `json:"memory"`
-	MemorySwap int64  `json:"memory_swap"`
-	CpuShares  int64  `json:"cpu_shares"`
-	Cpuset     string `json:"cpuset"`
+	Memory                       int64             `json:"memory"`
+	MemorySwap                   int64             `json:"memory_swap"`
+	MemoryReservation            int64             `json:"memory_reservation"`
+	KernelMemory                 int64             `json:"kernel_memory"`
+	OomKillDisable               bool              `json:"oom_kill_disable"`
+	CpuShares                    int64             `json:"cpu_shares"`
+	CpuQuota                     int64             `json:"cpu_quota"`
+	CpuPeriod                    int64             `json:"cpu_period"`
+	Cpuset                       string            `json:"cpuset"`
+	BlkioWeight                  int64             `json:"blkio_weight"`
+	BlkioThrottleReadBpsDevice   []*ThrottleDevice `json:"blkio_throttle_read_bps_device"`
+	BlkioThrottleWriteBpsDevice  []*ThrottleDevice `json:"blkio_throttle_write_bps_device"`
+	BlkioThrottleReadIOpsDevice  []*ThrottleDevice `json:"blkio_throttle_read_iops_device"`
+	BlkioThrottleWriteIOpsDevice []*ThrottleDevice `json:"blkio_throttle_write_iops_device"`
+	Rlimits                      []*ulimit.Rlimit  `json:"rlimits"`
+}
+
+// ThrottleDevice limits the bytes or the operations per second of a block
+// device, identified by its major and minor numbers.
+type ThrottleDevice struct {
+	Major int64  `json:"major"`
+	Minor int64  `json:"minor"`
+	Rate  uint64 `json:"rate"`
+}
+
+// String returns the rule in the format of the blkio cgroup files.
+func (t *ThrottleDevice) String() string {
+	return fmt.Sprintf("%d:%d %d", t.Major, t.Minor, t.Rate)
+}
+
+// ResourceStats contains the resource usage of a running container as read
+// from its cgroups and network interfaces.
+type ResourceStats struct {
+	*libcontainer.ContainerStats
+	Read        time.Time `json:"read"`
+	MemoryLimit int64     `json:"memory_limit"`
+	SystemUsage uint64    `json:"system_usage"`
 }
 
 type Mount struct {
@@ -134,4 +176,5 @@ type Command struct {
 	MountLabel         string            `json:"mount_label"`
 	LxcConfig          []string          `json:"lxc_config"`
 	AppArmorProfile    string            `json:"apparmor_profile"`
+	OomScoreAdj        int               `json:"oom_score_adj"`
 }
@@ -19,10 +19,14 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/docker/docker/daemon/execdriver"
+	sysinfo "github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/pkg/term"
 	"github.com/docker/docker/utils"
+	"github.com/docker/libcontainer"
 	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/cgroups/fs"
 	"github.com/docker/libcontainer/mount/nodes"
+	"github.com/docker/libcontainer/network"
 )
 
 const DriverName = "lxc"
@@ -30,10 +34,11 @@ const DriverName = "lxc"
 var ErrExec = errors.New("Unsupported: Exec is not supported by the lxc driver")
 
 type driver struct {
-	root       string // root path for the driver to use
-	initPath   string
-	apparmor   bool
-	sharedRoot bool
+	root          string // root path for the driver to use
+	initPath      string
+	apparmor      bool
+	sharedRoot    bool
+	machineMemory int64
 }
 
 func NewDriver(root, initPath string, apparmor bool) (*driver, error) {
@@ -42,11 +47,17 @@ func NewDriver(root, initPath string, apparmor bool) (*driver, error) {
 		return nil, err
 	}
 
+	meminfo, err := sysinfo.ReadMemInfo()
+	if err != nil {
+		return nil, err
+	}
+
 	return &driver{
-		apparmor:   apparmor,
-		root:       root,
-		initPath:   initPath,
-		sharedRoot: rootIsShared(),
+		apparmor:      apparmor,
+		root:          root,
+		initPath:      initPath,
+		sharedRoot:    rootIsShared(),
+		machineMemory: meminfo.MemTotal,
 	}, nil
 }
 
@@ -106,6 +117,7 @@ func (d *driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, startCallba
 	params = append(params,
 		"-mtu", strconv.Itoa(c.Network.Mtu),
 	)
+	params = append(params, execdriver.UlimitArgs(c.Resources)...)
 
 	if c.ProcessConfig.User != "" {
 		params = append(params, "-u", c.ProcessConfig.User)
@@ -195,6 +207,14 @@ func (d *driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, startCallba
 
 	c.ContainerPid = pid
 
+	// lxc has no setting for the OOM score, the init of the container is
+	// adjusted once it runs
+	if c.OomScoreAdj != 0 {
+		if err := ioutil.WriteFile(fmt.Sprintf("/proc/%d/oom_score_adj", pid), []byte(strconv.Itoa(c.OomScoreAdj)), 0644); err != nil {
+			log.Errorf("Error setting the OOM score of container %s: %s", c.ID, err)
+		}
+	}
+
 	if startCallback != nil {
 		startCallback(&c.ProcessConfig, pid)
 	}
@@ -345,28 +365,37 @@ func (d *driver) Info(id string) execdriver.Info {
 	}
 }
 
-func (d *driver) GetPidsForContainer(id string) ([]int, error) {
-	pids := []int{}
-
-	// cpu is chosen because it is the only non optional subsystem in cgroups
-	subsystem := "cpu"
+// cgroupPath returns the path of the given cgroup subsystem for the
+// container with the specified id.
+func cgroupPath(subsystem, id string) (string, error) {
 	cgroupRoot, err := cgroups.FindCgroupMountpoint(subsystem)
 	if err != nil {
-		return pids, err
+		return "", err
 	}
 
 	cgroupDir, err := cgroups.GetThisCgroupDir(subsystem)
 	if err != nil {
-		return pids, err
+		return "", err
 	}
 
-	filename := filepath.Join(cgroupRoot, cgroupDir, id, "tasks")
-	if _, err := os.Stat(filename); os.IsNotExist(err) {
+	p := filepath.Join(cgroupRoot, cgroupDir, id)
+	if _, err := os.Stat(p); os.IsNotExist(err) {
 		// With more recent lxc versions use, cgroup will be in lxc/
-		filename = filepath.Join(cgroupRoot, cgroupDir, "lxc", id, "tasks")
+		p = filepath.Join(cgroupRoot, cgroupDir, "lxc", id)
 	}
+	return p, nil
+}
 
-	output, err := ioutil.ReadFile(filename)
+func (d *driver) GetPidsForContainer(id string) ([]int, error) {
+	pids := []int{}
+
+	// cpu is chosen because it is the only non optional subsystem in cgroups
+	cgroupDir, err := cgroupPath("cpu", id)
+	if err != nil {
+		return pids, err
+	}
+
+	output, err := ioutil.ReadFile(filepath.Join(cgroupDir, "tasks"))
 	if err != nil {
 		return pids, err
 	}
@@ -383,6 +412,57 @@ func (d *driver) GetPidsForContainer(id string) ([]int, error) {
 	return pids, nil
 }
 
+func (d *driver) Stats(id string) (*execdriver.ResourceStats, error) {
+	if !d.Info(id).IsRunning() {
+		return nil, execdriver.ErrNotRunning
+	}
+
+	paths := make(map[string]string)
+	for _, subsystem := range []string{"cpu", "cpuacct", "memory", "blkio"} {
+		p, err := cgroupPath(subsystem, id)
+		if err != nil {
+			if cgroups.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		paths[subsystem] = p
+	}
+
+	now := time.Now()
+	cgroupStats, err := fs.GetStats(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	// lxc does not keep the configured limit around, read it back from
+	// the memory cgroup and cap it to the memory of the machine
+	memoryLimit := d.machineMemory
+	if p, ok := paths["memory"]; ok {
+		if data, err := ioutil.ReadFile(filepath.Join(p, "memory.limit_in_bytes")); err == nil {
+			if limit, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64); err == nil && limit < memoryLimit {
+				memoryLimit = limit
+			}
+		}
+	}
+
+	stats := &libcontainer.ContainerStats{CgroupStats: cgroupStats}
+	// The host end of the veth pair is named after the container, there is
+	// none when the container does not use the bridge
+	veth := vethPair(id)
+	if _, err := os.Stat(filepath.Join("/sys/class/net", veth)); err == nil {
+		if stats.NetworkStats, err = network.GetStats(&network.NetworkState{VethHost: veth}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &execdriver.ResourceStats{
+		Read:           now,
+		ContainerStats: stats,
+		MemoryLimit:    memoryLimit,
+	}, nil
+}
+
 func linkLxcStart(root string) error {
 	sourcePath, err := exec.LookPath("lxc-start")
 	if err != nil {
@@ -415,6 +495,25 @@ func rootIsShared() bool {
 	return true
 }
 
+func (d *driver) Update(c *execdriver.Command) error {
+	if !d.Info(c.ID).IsRunning() {
+		return execdriver.ErrNotRunning
+	}
+
+	paths := make(map[string]string)
+	for _, subsystem := range []string{"cpu", "cpuset", "memory", "blkio"} {
+		p, err := cgroupPath(subsystem, c.ID)
+		if err != nil {
+			if cgroups.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		paths[subsystem] = p
+	}
+	return execdriver.UpdateCgroups(paths, c.Resources)
+}
+
 func (d *driver) generateLXCConfig(c *execdriver.Command) (string, error) {
 	root := path.Join(d.root, "containers", c.ID, "config.lxc")
 
@@ -13,7 +13,9 @@ import (
 	"strings"
 	"syscall"
 
+	"github.com/docker/docker/opts"
 	"github.com/docker/docker/pkg/reexec"
+	"github.com/docker/docker/pkg/ulimit"
 	"github.com/docker/libcontainer/netlink"
 )
 
@@ -32,6 +34,7 @@ type InitArgs struct {
 	Root       string
 	CapAdd     string
 	CapDrop    string
+	Ulimits    []*ulimit.Ulimit
 }
 
 func init() {
@@ -65,6 +68,9 @@ func setupNamespace(args *InitArgs) error {
 	if err := setupNetworking(args); err != nil {
 		return err
 	}
+	if err := setupRlimits(args); err != nil {
+		return err
+	}
 	if err := finalizeNamespace(args); err != nil {
 		return err
 	}
@@ -93,7 +99,9 @@ func getArgs() *InitArgs {
 		mtu        = flag.Int("mtu", 1500, "interface mtu")
 		capAdd     = flag.String("cap-add", "", "capabilities to add")
 		capDrop    = flag.String("cap-drop", "", "capabilities to drop")
+		ulimits    = opts.NewUlimitOpt(nil)
 	)
+	flag.Var(ulimits, "ulimit", "resource limit, as name=soft:hard")
 
 	flag.Parse()
 
@@ -107,6 +115,7 @@ func getArgs() *InitArgs {
 		Mtu:        *mtu,
 		CapAdd:     *capAdd,
 		CapDrop:    *capDrop,
+		Ulimits:    ulimits.GetList(),
 	}
 }
 
@@ -191,6 +200,20 @@ func setupNetworking(args *InitArgs) error {
 	return nil
 }
 
+// Setup the resource limits, before the capabilities are dropped
+func setupRlimits(args *InitArgs) error {
+	for _, ul := range args.Ulimits {
+		rlimit, err := ul.GetRlimit()
+		if err != nil {
+			return err
+		}
+		if err := syscall.Setrlimit(rlimit.Type, &syscall.Rlimit{Cur: rlimit.Soft, Max: rlimit.Hard}); err != nil {
+			return fmt.Errorf("Unable to set ulimit %s: %v", ul.Name, err)
+		}
+	}
+	return nil
+}
+
 // Setup working directory
 func setupWorkingDirectory(args *InitArgs) error {
 	if args.WorkDir == "" {
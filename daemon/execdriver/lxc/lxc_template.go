@@ -14,7 +14,14 @@ const LxcTemplate = `
 lxc.network.type = veth
 lxc.network.link = {{.Network.Interface.Bridge}}
 lxc.network.name = eth0
+lxc.network.veth.pair = {{vethPair .ID}}
 lxc.network.mtu = {{.Network.Mtu}}
+{{if .Network.Interface.GlobalIPv6Address}}
+lxc.network.ipv6 = {{.Network.Interface.GlobalIPv6Address}}/{{.Network.Interface.GlobalIPv6PrefixLen}}
+{{end}}
+{{if .Network.Interface.IPv6Gateway}}
+lxc.network.ipv6.gateway = {{.Network.Interface.IPv6Gateway}}
+{{end}}
 {{else if .Network.HostNetworking}}
 lxc.network.type = none
 {{else}}
@@ -90,17 +97,49 @@ lxc.aa_profile = unconfined
 {{if .Resources}}
 {{if .Resources.Memory}}
 lxc.cgroup.memory.limit_in_bytes = {{.Resources.Memory}}
+{{if not .Resources.MemoryReservation}}
 lxc.cgroup.memory.soft_limit_in_bytes = {{.Resources.Memory}}
+{{end}}
 {{with $memSwap := getMemorySwap .Resources}}
 lxc.cgroup.memory.memsw.limit_in_bytes = {{$memSwap}}
 {{end}}
 {{end}}
+{{if .Resources.MemoryReservation}}
+lxc.cgroup.memory.soft_limit_in_bytes = {{.Resources.MemoryReservation}}
+{{end}}
+{{if .Resources.KernelMemory}}
+lxc.cgroup.memory.kmem.limit_in_bytes = {{.Resources.KernelMemory}}
+{{end}}
+{{if .Resources.OomKillDisable}}
+lxc.cgroup.memory.oom_control = 1
+{{end}}
 {{if .Resources.CpuShares}}
 lxc.cgroup.cpu.shares = {{.Resources.CpuShares}}
 {{end}}
+{{if .Resources.CpuPeriod}}
+lxc.cgroup.cpu.cfs_period_us = {{.Resources.CpuPeriod}}
+{{end}}
+{{if .Resources.CpuQuota}}
+lxc.cgroup.cpu.cfs_quota_us = {{.Resources.CpuQuota}}
+{{end}}
 {{if .Resources.Cpuset}}
 lxc.cgroup.cpuset.cpus = {{.Resources.Cpuset}}
 {{end}}
+{{if .Resources.BlkioWeight}}
+lxc.cgroup.blkio.weight = {{.Resources.BlkioWeight}}
+{{end}}
+{{range $device := .Resources.BlkioThrottleReadBpsDevice}}
+lxc.cgroup.blkio.throttle.read_bps_device = {{$device}}
+{{end}}
+{{range $device := .Resources.BlkioThrottleWriteBpsDevice}}
+lxc.cgroup.blkio.throttle.write_bps_device = {{$device}}
+{{end}}
+{{range $device := .Resources.BlkioThrottleReadIOpsDevice}}
+lxc.cgroup.blkio.throttle.read_iops_device = {{$device}}
+{{end}}
+{{range $device := .Resources.BlkioThrottleWriteIOpsDevice}}
+lxc.cgroup.blkio.throttle.write_iops_device = {{$device}}
+{{end}}
 {{end}}
 
 {{if .LxcConfig}}
@@ -141,6 +180,16 @@ func getMemorySwap(v *execdriver.Resources) int64 {
 	return v.Memory * 2
 }
 
+// vethPair returns the name of the host end of the veth pair of the
+// container, which its network stats are read from. Interface names are
+// limited to 15 characters.
+func vethPair(id string) string {
+	if len(id) > 11 {
+		id = id[:11]
+	}
+	return "veth" + id
+}
+
 func getLabel(c map[string][]string, name string) string {
 	label := c["label"]
 	for _, l := range label {
@@ -159,6 +208,7 @@ func init() {
 		"escapeFstabSpaces": escapeFstabSpaces,
 		"formatMountLabel":  label.FormatMountLabel,
 		"isDirectory":       isDirectory,
+		"vethPair":          vethPair,
 	}
 	LxcTemplateCompiled, err = template.New("lxc").Funcs(funcMap).Parse(LxcTemplate)
 	if err != nil {
@@ -103,6 +103,98 @@ func TestCustomLxcConfig(t *testing.T) {
 	grepFile(t, p, "lxc.cgroup.cpuset.cpus = 0,1")
 }
 
+func TestLxcConfigIPv6(t *testing.T) {
+	root, err := ioutil.TempDir("", "TestLxcConfigIPv6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	os.MkdirAll(path.Join(root, "containers", "1"), 0777)
+
+	driver, err := NewDriver(root, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	command := &execdriver.Command{
+		ID: "1",
+		Network: &execdriver.Network{
+			Mtu: 1500,
+			Interface: &execdriver.NetworkInterface{
+				Gateway:             "172.17.42.1",
+				IPAddress:           "172.17.0.2",
+				IPPrefixLen:         16,
+				Bridge:              "docker0",
+				GlobalIPv6Address:   "2001:db8::2",
+				GlobalIPv6PrefixLen: 64,
+				IPv6Gateway:         "fe80::1",
+			},
+		},
+		ProcessConfig: execdriver.ProcessConfig{},
+	}
+
+	p, err := driver.generateLXCConfig(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grepFile(t, p, "lxc.network.veth.pair = veth1")
+	grepFile(t, p, "lxc.network.ipv6 = 2001:db8::2/64")
+	grepFile(t, p, "lxc.network.ipv6.gateway = fe80::1")
+}
+
+func TestLxcConfigCgroupLimits(t *testing.T) {
+	root, err := ioutil.TempDir("", "TestLxcConfigCgroupLimits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	os.MkdirAll(path.Join(root, "containers", "1"), 0777)
+
+	driver, err := NewDriver(root, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	command := &execdriver.Command{
+		ID: "1",
+		Resources: &execdriver.Resources{
+			Memory:            67108864,
+			MemoryReservation: 33554432,
+			KernelMemory:      16777216,
+			OomKillDisable:    true,
+			CpuQuota:          50000,
+			CpuPeriod:         100000,
+			BlkioWeight:       300,
+			BlkioThrottleReadBpsDevice: []*execdriver.ThrottleDevice{
+				{Major: 8, Minor: 0, Rate: 1048576},
+			},
+			BlkioThrottleWriteIOpsDevice: []*execdriver.ThrottleDevice{
+				{Major: 8, Minor: 16, Rate: 100},
+			},
+		},
+		Network: &execdriver.Network{
+			Mtu:       1500,
+			Interface: nil,
+		},
+		ProcessConfig: execdriver.ProcessConfig{},
+	}
+
+	p, err := driver.generateLXCConfig(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grepFile(t, p, "lxc.cgroup.memory.soft_limit_in_bytes = 33554432")
+	grepFile(t, p, "lxc.cgroup.memory.kmem.limit_in_bytes = 16777216")
+	grepFile(t, p, "lxc.cgroup.memory.oom_control = 1")
+	grepFile(t, p, "lxc.cgroup.cpu.cfs_quota_us = 50000")
+	grepFile(t, p, "lxc.cgroup.cpu.cfs_period_us = 100000")
+	grepFile(t, p, "lxc.cgroup.blkio.weight = 300")
+	grepFile(t, p, "lxc.cgroup.blkio.throttle.read_bps_device = 8:0 1048576")
+	grepFile(t, p, "lxc.cgroup.blkio.throttle.write_iops_device = 8:16 100")
+}
+
 func grepFile(t *testing.T, path string, pattern string) {
 	f, err := os.Open(path)
 	if err != nil {
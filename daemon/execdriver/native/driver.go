@@ -10,15 +10,19 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/docker/docker/daemon/execdriver"
+	sysinfo "github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/pkg/term"
 	"github.com/docker/libcontainer"
 	"github.com/docker/libcontainer/apparmor"
+	"github.com/docker/libcontainer/cgroups"
 	"github.com/docker/libcontainer/cgroups/fs"
 	"github.com/docker/libcontainer/cgroups/systemd"
 	consolepkg "github.com/docker/libcontainer/console"
@@ -41,6 +45,7 @@ type driver struct {
 	root             string
 	initPath         string
 	activeContainers map[string]*activeContainer
+	machineMemory    int64
 	sync.Mutex
 }
 
@@ -54,10 +59,16 @@ func NewDriver(root, initPath string) (*driver, error) {
 		return nil, err
 	}
 
+	meminfo, err := sysinfo.ReadMemInfo()
+	if err != nil {
+		return nil, err
+	}
+
 	return &driver{
 		root:             root,
 		initPath:         initPath,
 		activeContainers: make(map[string]*activeContainer),
+		machineMemory:    meminfo.MemTotal,
 	}, nil
 }
 
@@ -110,6 +121,20 @@ func (d *driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, startCallba
 		return execdriver.ExitStatus{-1, false}, err
 	}
 
+	// libcontainer does not set all the resource limits, the driver writes
+	// the others to the cgroups of the container before it joins them. The
+	// kernel memory limit is only accepted while the cgroups are empty.
+	if c.Resources != nil {
+		paths, err := createCgroups(container.Cgroups)
+		defer cgroups.RemovePaths(paths)
+		if err != nil {
+			return execdriver.ExitStatus{-1, false}, err
+		}
+		if err := execdriver.SetupCgroups(paths, c.Resources); err != nil {
+			return execdriver.ExitStatus{-1, false}, err
+		}
+	}
+
 	execOutputChan := make(chan execOutput, 1)
 	waitForStart := make(chan struct{})
 
@@ -121,8 +146,9 @@ func (d *driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, startCallba
 				"-console", console,
 				"-pipe", "3",
 				"-root", filepath.Join(d.root, c.ID),
-				"--",
-			}, args...)
+				"-oom-score-adj", strconv.Itoa(c.OomScoreAdj),
+			}, execdriver.UlimitArgs(c.Resources)...)
+			c.ProcessConfig.Args = append(append(c.ProcessConfig.Args, "--"), args...)
 
 			// set this to nil so that when we set the clone flags anything else is reset
 			c.ProcessConfig.SysProcAttr = &syscall.SysProcAttr{
@@ -255,6 +281,93 @@ func (d *driver) GetPidsForContainer(id string) ([]int, error) {
 	return fs.GetPids(c)
 }
 
+func (d *driver) Stats(id string) (*execdriver.ResourceStats, error) {
+	d.Lock()
+	active := d.activeContainers[id]
+	d.Unlock()
+
+	if active == nil {
+		return nil, execdriver.ErrNotRunning
+	}
+	state, err := libcontainer.GetState(filepath.Join(d.root, id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, execdriver.ErrNotRunning
+		}
+		return nil, err
+	}
+	now := time.Now()
+	stats, err := libcontainer.GetStats(nil, state)
+	if err != nil {
+		return nil, err
+	}
+	memoryLimit := active.container.Cgroups.Memory
+	// if the container does not have any memory limit specified set the
+	// limit to the machines memory
+	if memoryLimit == 0 {
+		memoryLimit = d.machineMemory
+	}
+	return &execdriver.ResourceStats{
+		Read:           now,
+		ContainerStats: stats,
+		MemoryLimit:    memoryLimit,
+	}, nil
+}
+
+func (d *driver) Update(c *execdriver.Command) error {
+	d.Lock()
+	active := d.activeContainers[c.ID]
+	d.Unlock()
+
+	if active == nil {
+		return fmt.Errorf("active container for %s does not exist", c.ID)
+	}
+	state, err := libcontainer.GetState(filepath.Join(d.root, c.ID))
+	if err != nil {
+		return err
+	}
+	if err := execdriver.UpdateCgroups(state.CgroupPaths, c.Resources); err != nil {
+		return err
+	}
+	// Keep the config in sync, the stats report its memory limit
+	return d.setupCgroups(active.container, c)
+}
+
+// createCgroups creates the memory, cpu and blkio cgroups of the container,
+// where libcontainer or systemd put its processes, and returns their paths.
+func createCgroups(c *cgroups.Cgroup) (map[string]string, error) {
+	paths := make(map[string]string)
+	for _, subsystem := range []string{"memory", "cpu", "blkio"} {
+		mountpoint, err := cgroups.FindCgroupMountpoint(subsystem)
+		if err != nil {
+			// Don't fail if a cgroup hierarchy was not found, just skip this subsystem
+			if cgroups.IsNotFound(err) {
+				continue
+			}
+			return paths, err
+		}
+		initPath, err := cgroups.GetInitCgroupDir(subsystem)
+		if err != nil {
+			return paths, err
+		}
+		path := filepath.Join(mountpoint, initPath, c.Parent, c.Name)
+		if systemd.UseSystemd() {
+			slice := "system.slice"
+			if c.Slice != "" {
+				slice = c.Slice
+			}
+			path = filepath.Join(mountpoint, initPath, slice, fmt.Sprintf("%s-%s.scope", c.Parent, c.Name))
+		} else if filepath.IsAbs(c.Parent) {
+			path = filepath.Join(mountpoint, c.Parent, c.Name)
+		}
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return paths, err
+		}
+		paths[subsystem] = path
+	}
+	return paths, nil
+}
+
 func (d *driver) writeContainerFile(container *libcontainer.Config, id string) error {
 	data, err := json.Marshal(container)
 	if err != nil {
@@ -6,10 +6,14 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"syscall"
 
+	"github.com/docker/docker/opts"
 	"github.com/docker/docker/pkg/reexec"
 	"github.com/docker/libcontainer"
 	"github.com/docker/libcontainer/namespaces"
@@ -23,10 +27,13 @@ func initializer() {
 	runtime.LockOSThread()
 
 	var (
-		pipe    = flag.Int("pipe", 0, "sync pipe fd")
-		console = flag.String("console", "", "console (pty slave) path")
-		root    = flag.String("root", ".", "root path for configuration files")
+		pipe        = flag.Int("pipe", 0, "sync pipe fd")
+		console     = flag.String("console", "", "console (pty slave) path")
+		root        = flag.String("root", ".", "root path for configuration files")
+		oomScoreAdj = flag.Int("oom-score-adj", 0, "OOM score adjustment of the container")
+		ulimits     = opts.NewUlimitOpt(nil)
 	)
+	flag.Var(ulimits, "ulimit", "resource limit of the container, as name=soft:hard")
 
 	flag.Parse()
 
@@ -42,6 +49,25 @@ func initializer() {
 	}
 	f.Close()
 
+	// libcontainer does not adjust the OOM score, the command of the
+	// container inherits it from its init
+	if *oomScoreAdj != 0 {
+		if err := ioutil.WriteFile("/proc/self/oom_score_adj", []byte(strconv.Itoa(*oomScoreAdj)), 0644); err != nil {
+			writeError(err)
+		}
+	}
+
+	// libcontainer does not set the resource limits either
+	for _, ul := range ulimits.GetList() {
+		rlimit, err := ul.GetRlimit()
+		if err != nil {
+			writeError(err)
+		}
+		if err := syscall.Setrlimit(rlimit.Type, &syscall.Rlimit{Cur: rlimit.Soft, Max: rlimit.Hard}); err != nil {
+			writeError(fmt.Errorf("error setting ulimit %s: %v", ul.Name, err))
+		}
+	}
+
 	rootfs, err := os.Getwd()
 	if err != nil {
 		writeError(err)
@@ -2,8 +2,13 @@ package execdriver
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/docker/docker/pkg/ulimit"
 	"github.com/docker/docker/utils"
 	"github.com/docker/libcontainer/security/capabilities"
 )
@@ -61,3 +66,142 @@ func TweakCapabilities(basics, adds, drops []string) ([]string, error) {
 
 	return newCaps, nil
 }
+
+// UlimitArgs returns the arguments which pass the resource limits of the
+// container to its init, which sets them before executing the command.
+func UlimitArgs(r *Resources) []string {
+	var args []string
+	if r == nil {
+		return args
+	}
+	for _, rlimit := range r.Rlimits {
+		ul := &ulimit.Ulimit{Name: rlimit.Name(), Soft: int64(rlimit.Soft), Hard: int64(rlimit.Hard)}
+		args = append(args, "-ulimit", ul.String())
+	}
+	return args
+}
+
+// UpdateCgroups writes the resources which can change while the container
+// runs to its cgroups. paths maps the subsystems to the cgroups of the
+// container. Zero values are left unchanged.
+func UpdateCgroups(paths map[string]string, r *Resources) error {
+	if r.Memory != 0 {
+		if err := updateMemory(paths, r); err != nil {
+			return err
+		}
+	}
+	reservation := r.MemoryReservation
+	if reservation == 0 {
+		reservation = r.Memory
+	}
+	for _, setting := range []struct {
+		subsystem, file string
+		value           int64
+	}{
+		{"memory", "memory.soft_limit_in_bytes", reservation},
+		{"cpu", "cpu.shares", r.CpuShares},
+		{"cpu", "cpu.cfs_period_us", r.CpuPeriod},
+		{"cpu", "cpu.cfs_quota_us", r.CpuQuota},
+		{"blkio", "blkio.weight", r.BlkioWeight},
+	} {
+		if setting.value == 0 {
+			continue
+		}
+		if err := writeCgroupFile(paths, setting.subsystem, setting.file, strconv.FormatInt(setting.value, 10)); err != nil {
+			return err
+		}
+	}
+	if r.Cpuset != "" {
+		if err := writeCgroupFile(paths, "cpuset", "cpuset.cpus", r.Cpuset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetupCgroups writes the resource limits that libcontainer does not set to
+// the cgroups of the container. paths maps the subsystems to the cgroups of
+// the container. The kernel memory limit is only accepted before any process
+// joins the cgroup.
+func SetupCgroups(paths map[string]string, r *Resources) error {
+	for _, setting := range []struct {
+		subsystem, file string
+		value           int64
+	}{
+		{"memory", "memory.kmem.limit_in_bytes", r.KernelMemory},
+		{"cpu", "cpu.cfs_period_us", r.CpuPeriod},
+		{"cpu", "cpu.cfs_quota_us", r.CpuQuota},
+		{"blkio", "blkio.weight", r.BlkioWeight},
+	} {
+		if setting.value == 0 {
+			continue
+		}
+		if err := writeCgroupFile(paths, setting.subsystem, setting.file, strconv.FormatInt(setting.value, 10)); err != nil {
+			return err
+		}
+	}
+	if r.OomKillDisable {
+		if err := writeCgroupFile(paths, "memory", "memory.oom_control", "1"); err != nil {
+			return err
+		}
+	}
+	for _, throttle := range []struct {
+		file    string
+		devices []*ThrottleDevice
+	}{
+		{"blkio.throttle.read_bps_device", r.BlkioThrottleReadBpsDevice},
+		{"blkio.throttle.write_bps_device", r.BlkioThrottleWriteBpsDevice},
+		{"blkio.throttle.read_iops_device", r.BlkioThrottleReadIOpsDevice},
+		{"blkio.throttle.write_iops_device", r.BlkioThrottleWriteIOpsDevice},
+	} {
+		// The kernel reads a single rule per write
+		for _, device := range throttle.devices {
+			if err := writeCgroupFile(paths, "blkio", throttle.file, device.String()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// updateMemory changes the memory limit and the limit of memory and swap.
+// The memory limit cannot exceed the other one, so the order of the writes
+// depends on whether the limit grows.
+func updateMemory(paths map[string]string, r *Resources) error {
+	memorySwap := r.MemorySwap
+	if memorySwap == 0 {
+		// By default, MemorySwap is set to twice the size of RAM, as when
+		// the container starts
+		memorySwap = r.Memory * 2
+	}
+	files := []string{"memory.limit_in_bytes"}
+	values := map[string]int64{"memory.limit_in_bytes": r.Memory}
+	if dir, ok := paths["memory"]; ok && memorySwap != -1 {
+		if _, err := os.Stat(filepath.Join(dir, "memory.memsw.limit_in_bytes")); err == nil {
+			values["memory.memsw.limit_in_bytes"] = memorySwap
+			data, err := ioutil.ReadFile(filepath.Join(dir, "memory.limit_in_bytes"))
+			if err != nil {
+				return err
+			}
+			if limit, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64); err == nil && r.Memory > limit {
+				files = append([]string{"memory.memsw.limit_in_bytes"}, files...)
+			} else {
+				files = append(files, "memory.memsw.limit_in_bytes")
+			}
+		}
+	}
+	for _, file := range files {
+		if err := writeCgroupFile(paths, "memory", file, strconv.FormatInt(values[file], 10)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCgroupFile(paths map[string]string, subsystem, file, value string) error {
+	dir, ok := paths[subsystem]
+	if !ok {
+		return fmt.Errorf("cgroup subsystem %s of the container not found", subsystem)
+	}
+	return ioutil.WriteFile(filepath.Join(dir, file), []byte(value), 0700)
+}
@@ -0,0 +1,28 @@
+package execdriver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/pkg/ulimit"
+)
+
+func TestUlimitArgs(t *testing.T) {
+	if args := UlimitArgs(nil); len(args) != 0 {
+		t.Fatalf("Expected no arguments without resources, got %v", args)
+	}
+
+	var rlimits []*ulimit.Rlimit
+	for _, ul := range []*ulimit.Ulimit{{Name: "nofile", Soft: 1024, Hard: 2048}, {Name: "core", Soft: -1, Hard: -1}} {
+		rlimit, err := ul.GetRlimit()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rlimits = append(rlimits, rlimit)
+	}
+	args := UlimitArgs(&Resources{Rlimits: rlimits})
+	expected := []string{"-ulimit", "nofile=1024:2048", "-ulimit", "core=-1:-1"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Fatalf("Expected %v, got %v", expected, args)
+	}
+}
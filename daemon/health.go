@@ -0,0 +1,299 @@
+package daemon
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/daemon/execdriver/lxc"
+	"github.com/docker/docker/pkg/broadcastwriter"
+	"github.com/docker/docker/runconfig"
+	"github.com/docker/docker/utils"
+)
+
+const (
+	// Health statuses of a container
+	HealthStarting  = "starting"
+	HealthHealthy   = "healthy"
+	HealthUnhealthy = "unhealthy"
+
+	defaultProbeInterval = 30 * time.Second
+	defaultProbeTimeout  = 30 * time.Second
+	defaultProbeRetries  = 3
+
+	// maxHealthLogEntries is the number of probe results kept in the state
+	maxHealthLogEntries = 5
+	// maxProbeOutputLen is the number of bytes of output kept for a probe
+	maxProbeOutputLen = 4096
+)
+
+// Health is the health of a running container, as reported by its probe.
+type Health struct {
+	Status        string               // HealthStarting, HealthHealthy or HealthUnhealthy
+	FailingStreak int                  // Number of consecutive failures
+	Log           []*HealthcheckResult // Results of the last probes, oldest first
+
+	stop chan struct{} // closed to stop the probes
+}
+
+// HealthcheckResult is the result of a single run of the probe.
+type HealthcheckResult struct {
+	Start    time.Time
+	End      time.Time
+	ExitCode int    // 0 when healthy
+	Output   string // Beginning of the output of the probe
+}
+
+// initHealthMonitor starts running the probe of the container, if it has
+// one. It is called with the container locked once it is running.
+func (container *Container) initHealthMonitor() {
+	config := container.Config.Healthcheck
+	if config == nil || len(config.Test) == 0 || config.Test[0] == "NONE" {
+		container.Health = nil
+		return
+	}
+	if strings.HasPrefix(container.daemon.execDriver.Name(), lxc.DriverName) {
+		log.Warnf("Cannot check the health of container %s: %s", container.ID, lxc.ErrExec)
+		container.Health = nil
+		return
+	}
+
+	health := &Health{
+		Status: HealthStarting,
+		stop:   make(chan struct{}),
+	}
+	container.Health = health
+	go container.monitorHealth(health, config)
+}
+
+// stopHealthMonitor stops the probes once the container has stopped.
+func (container *Container) stopHealthMonitor() {
+	container.Lock()
+	if container.Health != nil && container.Health.stop != nil {
+		close(container.Health.stop)
+		container.Health.stop = nil
+	}
+	container.Unlock()
+}
+
+func (container *Container) monitorHealth(health *Health, config *runconfig.HealthConfig) {
+	interval, timeout, retries := config.Interval, config.Timeout, config.Retries
+	if interval == 0 {
+		interval = defaultProbeInterval
+	}
+	if timeout == 0 {
+		timeout = defaultProbeTimeout
+	}
+	if retries == 0 {
+		retries = defaultProbeRetries
+	}
+	stop := health.stop
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+		if container.IsPaused() {
+			continue
+		}
+		result, err := container.runHealthProbe(config.Test, timeout)
+		if err != nil {
+			log.Errorf("Cannot check the health of container %s: %s", container.ID, err)
+			result = &HealthcheckResult{ExitCode: -1, Output: err.Error()}
+		}
+		select {
+		case <-stop:
+			// The probe failed because the container stopped
+			return
+		default:
+		}
+		container.handleProbeResult(health, result, retries)
+	}
+}
+
+// runHealthProbe executes the probe in the container the way exec commands
+// are run. The probe is killed after the timeout.
+func (container *Container) runHealthProbe(test []string, timeout time.Duration) (*HealthcheckResult, error) {
+	var cmd []string
+	switch test[0] {
+	case "CMD":
+		cmd = test[1:]
+	case "CMD-SHELL":
+		cmd = []string{"/bin/sh", "-c", strings.Join(test[1:], " ")}
+	default:
+		return nil, fmt.Errorf("Unknown type of healthcheck: %s", test[0])
+	}
+	if len(cmd) == 0 {
+		return nil, fmt.Errorf("The healthcheck has no command")
+	}
+
+	entrypoint, args := container.daemon.getEntrypointAndArgs(nil, cmd)
+	execConfig := &execConfig{
+		ID:           utils.GenerateRandomID(),
+		OpenStdout:   true,
+		OpenStderr:   true,
+		StreamConfig: StreamConfig{},
+		ProcessConfig: execdriver.ProcessConfig{
+			Entrypoint: entrypoint,
+			Arguments:  args,
+		},
+		Container: container,
+		Running:   true,
+	}
+	output := &probeOutput{}
+	execConfig.StreamConfig.stdout = broadcastwriter.New()
+	execConfig.StreamConfig.stdout.AddWriter(output, "")
+	execConfig.StreamConfig.stderr = broadcastwriter.New()
+	execConfig.StreamConfig.stderr.AddWriter(output, "")
+
+	container.daemon.registerExecCommand(execConfig)
+	defer container.daemon.unregisterExecCommand(execConfig)
+
+	pid := make(chan int, 1)
+	callback := func(processConfig *execdriver.ProcessConfig, p int) {
+		pid <- p
+	}
+	result := &HealthcheckResult{Start: time.Now().UTC()}
+	done := make(chan error, 1)
+	go func() {
+		done <- container.monitorExec(execConfig, callback)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+		result.ExitCode = execConfig.ExitCode
+		result.Output = output.String()
+	case <-time.After(timeout):
+		// The probe may not have started yet, it is killed once it has
+		go func() {
+			select {
+			case p := <-pid:
+				killProcessTree(p)
+			case <-done:
+			}
+		}()
+		result.ExitCode = -1
+		result.Output = fmt.Sprintf("Health check exceeded timeout (%v)", timeout)
+	}
+	result.End = time.Now().UTC()
+	return result, err
+}
+
+// handleProbeResult records the result of a probe and updates the health
+// status, logging an event when it changes.
+func (container *Container) handleProbeResult(health *Health, result *HealthcheckResult, retries int) {
+	container.Lock()
+	if container.Health != health {
+		container.Unlock()
+		return
+	}
+	previous := health.Status
+	health.Log = append(health.Log, result)
+	if len(health.Log) > maxHealthLogEntries {
+		health.Log = health.Log[len(health.Log)-maxHealthLogEntries:]
+	}
+	if result.ExitCode == 0 {
+		health.FailingStreak = 0
+		health.Status = HealthHealthy
+	} else {
+		health.FailingStreak++
+		if health.FailingStreak >= retries {
+			health.Status = HealthUnhealthy
+		}
+	}
+	status := health.Status
+	if err := container.toDisk(); err != nil {
+		log.Debugf("%s", err)
+	}
+	container.Unlock()
+
+	if status != previous {
+		container.LogEvent("health_status: " + status)
+	}
+}
+
+// healthStatus returns the health status of the container, "none" when it
+// has no probe or is not running.
+func (s *State) healthStatus() string {
+	if s.Health == nil || !s.Running {
+		return "none"
+	}
+	return s.Health.Status
+}
+
+// killProcessTree kills the process pid and its descendants. The process
+// started by the exec driver forks the command after entering the
+// namespaces of the container, so killing it alone is not enough.
+func killProcessTree(pid int) {
+	children := map[int][]int{}
+	entries, _ := ioutil.ReadDir("/proc")
+	for _, entry := range entries {
+		p, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		stat, err := ioutil.ReadFile(filepath.Join("/proc", entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		// The name of the command is between parentheses and can contain
+		// spaces, the parent pid is the second field after it
+		fields := strings.Fields(string(stat[bytes.LastIndex(stat, []byte(")"))+1:]))
+		if len(fields) < 2 {
+			continue
+		}
+		if ppid, err := strconv.Atoi(fields[1]); err == nil {
+			children[ppid] = append(children[ppid], p)
+		}
+	}
+
+	pids := []int{pid}
+	for i := 0; i < len(pids); i++ {
+		pids = append(pids, children[pids[i]]...)
+	}
+	for _, p := range pids {
+		if err := syscall.Kill(p, syscall.SIGKILL); err != nil {
+			log.Debugf("Cannot kill process %d: %s", p, err)
+		}
+	}
+}
+
+// probeOutput keeps the beginning of the output of a probe.
+type probeOutput struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (o *probeOutput) Write(p []byte) (int, error) {
+	o.Lock()
+	defer o.Unlock()
+	if room := maxProbeOutputLen - o.buf.Len(); room > 0 {
+		if len(p) > room {
+			o.buf.Write(p[:room])
+		} else {
+			o.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (o *probeOutput) Close() error {
+	return nil
+}
+
+func (o *probeOutput) String() string {
+	o.Lock()
+	defer o.Unlock()
+	return o.buf.String()
+}
@@ -55,6 +55,15 @@ func (daemon *Daemon) ContainerInspect(job *engine.Job) engine.Status {
 			}
 		}
 
+		// an empty log driver is kept on disk so that the container follows
+		// changes of the daemon default, but the effective one is reported
+		if logConfig := container.hostConfig.LogConfig; logConfig.Type == "" {
+			container.hostConfig.LogConfig = container.getLogConfig()
+			defer func() {
+				container.hostConfig.LogConfig = logConfig
+			}()
+		}
+
 		out.SetJson("HostConfig", container.hostConfig)
 
 		container.hostConfig.Links = nil
@@ -0,0 +1,55 @@
+package daemon
+
+import (
+	"os"
+
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/jsonfilelog"
+	"github.com/docker/docker/runconfig"
+
+	// Importing packages here only to make sure their init gets called and
+	// therefore they register themselves to the log driver factory.
+	_ "github.com/docker/docker/daemon/logger/syslog"
+)
+
+// validateLogConfig checks that cfg refers to a known log driver and that
+// the options are accepted by that driver. "none" is always accepted and
+// disables logging altogether.
+func validateLogConfig(cfg runconfig.LogConfig) error {
+	if cfg.Type != "none" {
+		if _, err := logger.GetLogDriver(cfg.Type); err != nil {
+			return err
+		}
+	}
+	return logger.ValidateLogOpts(cfg.Type, cfg.Config)
+}
+
+// logConfig returns the log configuration that applies to a container
+// created with cfg: the daemon's default when no driver was given, or the
+// default driver with the given options if only those were set.
+func (daemon *Daemon) logConfig(cfg runconfig.LogConfig) runconfig.LogConfig {
+	if cfg.Type != "" {
+		return cfg
+	}
+	if len(cfg.Config) > 0 {
+		cfg.Type = daemon.config.LogConfig.Type
+		return cfg
+	}
+	return daemon.config.LogConfig
+}
+
+// openLogFiles opens the json log of the container along with its rotated
+// files, oldest first.
+func (container *Container) openLogFiles() ([]*os.File, error) {
+	container.Lock()
+	l, running := container.logDriver.(*jsonfilelog.JSONFileLogger)
+	container.Unlock()
+	if running {
+		return l.OpenFiles()
+	}
+	pth, err := container.logPath("json")
+	if err != nil {
+		return nil, err
+	}
+	return jsonfilelog.OpenFiles(pth)
+}
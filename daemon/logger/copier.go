@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Copier reads lines from the output streams of a container and sends them
+// to a Logger, tagged with the name of the stream they were read from.
+type Copier struct {
+	// cid is the container id for which we are copying logs
+	cid string
+	// srcs is map of name -> reader pairs, for example "stdout", "stderr"
+	srcs     map[string]io.Reader
+	dst      Logger
+	copyJobs sync.WaitGroup
+}
+
+// NewCopier creates a new Copier
+func NewCopier(cid string, srcs map[string]io.Reader, dst Logger) *Copier {
+	return &Copier{
+		cid:  cid,
+		srcs: srcs,
+		dst:  dst,
+	}
+}
+
+// Run starts copying in the background. It returns immediately.
+func (c *Copier) Run() {
+	for src, w := range c.srcs {
+		c.copyJobs.Add(1)
+		go c.copySrc(src, w)
+	}
+}
+
+func (c *Copier) copySrc(name string, src io.Reader) {
+	defer c.copyJobs.Done()
+	reader := bufio.NewReader(src)
+	for {
+		line, err := reader.ReadBytes('\n')
+		line = bytes.TrimSuffix(line, []byte{'\n'})
+		// a partial line left at the end of the stream is logged as well
+		if err == nil || len(line) > 0 {
+			msg := &Message{
+				ContainerID: c.cid,
+				Line:        line,
+				Source:      name,
+				Timestamp:   time.Now().UTC(),
+			}
+			if logErr := c.dst.Log(msg); logErr != nil {
+				log.Errorf("Failed to log msg %q for logger %s: %s", msg.Line, c.dst.Name(), logErr)
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				log.Errorf("Error scanning log stream: %s", err)
+			}
+			return
+		}
+	}
+}
+
+// Wait waits until all copying is done
+func (c *Copier) Wait() {
+	c.copyJobs.Wait()
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+)
+
+type testLoggerRecorder struct {
+	sync.Mutex
+	msgs []*Message
+}
+
+func (l *testLoggerRecorder) Log(m *Message) error {
+	l.Lock()
+	l.msgs = append(l.msgs, m)
+	l.Unlock()
+	return nil
+}
+
+func (l *testLoggerRecorder) Name() string { return "test" }
+
+func (l *testLoggerRecorder) Close() error { return nil }
+
+func TestCopier(t *testing.T) {
+	var (
+		stdout = bytes.NewBufferString("Line that thinks that it is log line from docker stdout\nsecond line\n")
+		stderr = bytes.NewBufferString("Line that thinks that it is log line from docker stderr\npartial")
+		l      = &testLoggerRecorder{}
+		c      = NewCopier("cid", map[string]io.Reader{"stdout": stdout, "stderr": stderr}, l)
+	)
+	c.Run()
+	c.Wait()
+
+	if len(l.msgs) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(l.msgs))
+	}
+	counts := map[string]int{}
+	for _, m := range l.msgs {
+		if m.ContainerID != "cid" {
+			t.Fatalf("wrong container id: %s", m.ContainerID)
+		}
+		if bytes.HasSuffix(m.Line, []byte{'\n'}) {
+			t.Fatalf("line %q should not end with a newline", m.Line)
+		}
+		if m.Timestamp.IsZero() {
+			t.Fatal("message should have a timestamp")
+		}
+		counts[m.Source]++
+	}
+	if counts["stdout"] != 2 || counts["stderr"] != 2 {
+		t.Fatalf("unexpected number of messages per stream: %v", counts)
+	}
+}
@@ -0,0 +1,207 @@
+package jsonfilelog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/pkg/jsonlog"
+	"github.com/docker/docker/pkg/units"
+)
+
+const Name = "json-file"
+
+// JSONFileLogger is Logger implementation for default docker logging:
+// JSON objects to file. Once the file grows over capacity it is rotated,
+// keeping at most maxFiles files.
+type JSONFileLogger struct {
+	buf      *bytes.Buffer
+	f        *os.File   // store for closing
+	mu       sync.Mutex // protects buffer and file
+	path     string
+	capacity int64 // maximum size of each file, -1 for unlimited
+	currSize int64
+	maxFiles int
+}
+
+func init() {
+	if err := logger.RegisterLogDriver(Name, New); err != nil {
+		panic(err)
+	}
+	if err := logger.RegisterLogOptValidator(Name, ValidateLogOpt); err != nil {
+		panic(err)
+	}
+}
+
+// New creates new JSONFileLogger which writes to the log path of the
+// container
+func New(ctx logger.Context) (logger.Logger, error) {
+	capacity, maxFiles, err := parseLogOpt(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+	log, err := os.OpenFile(ctx.LogPath, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	size, err := log.Seek(0, os.SEEK_END)
+	if err != nil {
+		log.Close()
+		return nil, err
+	}
+	return &JSONFileLogger{
+		f:        log,
+		buf:      bytes.NewBuffer(nil),
+		path:     ctx.LogPath,
+		capacity: capacity,
+		currSize: size,
+		maxFiles: maxFiles,
+	}, nil
+}
+
+// Log converts logger.Message to jsonlog.JSONLog and serializes it to file
+func (l *JSONFileLogger) Log(msg *logger.Message) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	err := (&jsonlog.JSONLog{Log: string(msg.Line) + "\n", Stream: msg.Source, Created: msg.Timestamp}).MarshalJSONBuf(l.buf)
+	if err != nil {
+		return err
+	}
+	l.buf.WriteByte('\n')
+	var rotateErr error
+	if l.capacity != -1 && l.currSize > 0 && l.currSize+int64(l.buf.Len()) > l.capacity {
+		// the message still goes to the current file if the rotation fails
+		rotateErr = l.rotate()
+	}
+	n, err := l.buf.WriteTo(l.f)
+	l.currSize += n
+	if err != nil {
+		// this buffer is screwed, replace it with another to avoid races
+		l.buf = bytes.NewBuffer(nil)
+		return err
+	}
+	return rotateErr
+}
+
+// rotate shifts the log files by one, dropping the oldest one, and starts a
+// new, empty log file. The current file is only closed once the new one is
+// open, so the logger keeps writing to it if the rotation fails. It must be
+// called with l.mu held.
+func (l *JSONFileLogger) rotate() error {
+	if err := rotate(l.path, l.maxFiles); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	l.f.Close()
+	l.f = f
+	l.currSize = 0
+	return nil
+}
+
+func rotate(name string, maxFiles int) error {
+	if maxFiles < 2 {
+		return nil
+	}
+	for i := maxFiles - 1; i > 1; i-- {
+		toPath := name + "." + strconv.Itoa(i)
+		fromPath := name + "." + strconv.Itoa(i-1)
+		if err := os.Rename(fromPath, toPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(name, name+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// OpenFiles opens the log files written by the logger, oldest first. The
+// files are opened while holding the lock of the logger so a concurrent
+// rotation cannot make lines appear twice or go missing.
+func (l *JSONFileLogger) OpenFiles() ([]*os.File, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return OpenFiles(l.path)
+}
+
+// OpenFiles opens the json log at path along with the files it was rotated
+// to, oldest first. It returns an error satisfying os.IsNotExist if there is
+// no log at path.
+func OpenFiles(path string) ([]*os.File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []*os.File{f}
+	for i := 1; ; i++ {
+		f, err := os.Open(path + "." + strconv.Itoa(i))
+		if err != nil {
+			if os.IsNotExist(err) {
+				break
+			}
+			for _, f := range files {
+				f.Close()
+			}
+			return nil, err
+		}
+		files = append([]*os.File{f}, files...)
+	}
+	return files, nil
+}
+
+// ValidateLogOpt looks for json specific log options max-file & max-size.
+func ValidateLogOpt(cfg map[string]string) error {
+	for key := range cfg {
+		switch key {
+		case "max-file":
+		case "max-size":
+		default:
+			return fmt.Errorf("unknown log opt '%s' for json-file log driver", key)
+		}
+	}
+	_, _, err := parseLogOpt(cfg)
+	return err
+}
+
+func parseLogOpt(cfg map[string]string) (capacity int64, maxFiles int, err error) {
+	capacity = -1
+	if capacityStr, ok := cfg["max-size"]; ok {
+		if capacity, err = units.RAMInBytes(capacityStr); err != nil {
+			return 0, 0, fmt.Errorf("invalid max-size '%s' for json-file log driver: %v", capacityStr, err)
+		}
+		if capacity <= 0 {
+			return 0, 0, fmt.Errorf("max-size for json-file log driver must be a positive size, got '%s'", capacityStr)
+		}
+	}
+	maxFiles = 1
+	if maxFileString, ok := cfg["max-file"]; ok {
+		if maxFiles, err = strconv.Atoi(maxFileString); err != nil {
+			return 0, 0, fmt.Errorf("invalid max-file '%s' for json-file log driver: %v", maxFileString, err)
+		}
+		if maxFiles < 1 {
+			return 0, 0, fmt.Errorf("max-file for json-file log driver cannot be less than 1")
+		}
+		if capacity == -1 && maxFiles > 1 {
+			return 0, 0, fmt.Errorf("max-file for json-file log driver requires max-size to be set")
+		}
+	}
+	return capacity, maxFiles, nil
+}
+
+// Close closes underlying file
+func (l *JSONFileLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
+
+// Name returns name of this logger
+func (l *JSONFileLogger) Name() string {
+	return Name
+}
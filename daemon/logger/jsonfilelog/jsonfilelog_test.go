@@ -0,0 +1,177 @@
+package jsonfilelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/pkg/jsonlog"
+)
+
+func TestJSONFileLogger(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, "container.log")
+	l, err := New(logger.Context{ContainerID: "a7317399f3f857173c6179d44823594f8294678dea9999662e5c625b5a1c7657", LogPath: filename})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	created := time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if err := l.Log(&logger.Message{ContainerID: "cid", Line: []byte("line1"), Source: "src1", Timestamp: created}); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Log(&logger.Message{ContainerID: "cid", Line: []byte("line2"), Source: "src2", Timestamp: created}); err != nil {
+		t.Fatal(err)
+	}
+	res, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"log":"line1\n","stream":"src1","time":"2015-01-01T00:00:00Z"}
+{"log":"line2\n","stream":"src2","time":"2015-01-01T00:00:00Z"}
+`
+	if string(res) != expected {
+		t.Fatalf("Wrong log content: %q, expected %q", res, expected)
+	}
+}
+
+func TestJSONFileLoggerWithOpts(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, "container.log")
+	config := map[string]string{"max-file": "3", "max-size": "1k"}
+	l, err := New(logger.Context{ContainerID: "cid", LogPath: filename, Config: config})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	for i := 0; i < 100; i++ {
+		if err := l.Log(&logger.Message{ContainerID: "cid", Line: []byte(fmt.Sprintf("line%02d", i)), Source: "src1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := l.(*JSONFileLogger).OpenFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected 3 log files, got %d", len(files))
+	}
+	var (
+		previous = -1
+		readers  []io.Reader
+	)
+	for _, f := range files {
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Size() > 1024 {
+			t.Fatalf("log file %s is bigger than max-size: %d", fi.Name(), fi.Size())
+		}
+		readers = append(readers, f)
+	}
+	dec := json.NewDecoder(io.MultiReader(readers...))
+	for {
+		var jl jsonlog.JSONLog
+		if err := dec.Decode(&jl); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		var n int
+		if _, err := fmt.Sscanf(jl.Log, "line%d\n", &n); err != nil {
+			t.Fatal(err)
+		}
+		if n != previous+1 && previous != -1 {
+			t.Fatalf("expected line%02d after line%02d, got line%02d", previous+1, previous, n)
+		}
+		previous = n
+	}
+	if previous != 99 {
+		t.Fatalf("expected the last line to be line99, got line%02d", previous)
+	}
+	if _, err := os.Stat(filename + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected no more than 3 files, got %v", err)
+	}
+}
+
+func TestJSONFileLoggerRotateError(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, "container.log")
+	// the current file cannot be renamed over a non-empty directory
+	if err := os.MkdirAll(filepath.Join(filename+".1", "dir"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	config := map[string]string{"max-file": "2", "max-size": "100"}
+	l, err := New(logger.Context{ContainerID: "cid", LogPath: filename, Config: config})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	for i := 0; i < 4; i++ {
+		err := l.Log(&logger.Message{ContainerID: "cid", Line: []byte(fmt.Sprintf("line%d", i)), Source: "src1"})
+		if i == 0 && err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 && err == nil {
+			t.Fatalf("expected an error rotating the log for line%d", i)
+		}
+	}
+	res, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if !strings.Contains(string(res), fmt.Sprintf(`"log":"line%d\n"`, i)) {
+			t.Fatalf("expected line%d in the current log file, got %q", i, res)
+		}
+	}
+}
+
+func TestValidateLogOpt(t *testing.T) {
+	valid := []map[string]string{
+		{},
+		{"max-size": "10m"},
+		{"max-size": "10m", "max-file": "3"},
+	}
+	for _, cfg := range valid {
+		if err := ValidateLogOpt(cfg); err != nil {
+			t.Fatalf("expected %v to be valid: %v", cfg, err)
+		}
+	}
+	invalid := []map[string]string{
+		{"max-size": "ten"},
+		{"max-size": "10m", "max-file": "0"},
+		{"max-file": "3"},
+		{"unknown": "value"},
+	}
+	for _, cfg := range invalid {
+		if err := ValidateLogOpt(cfg); err == nil {
+			t.Fatalf("expected %v to be invalid", cfg)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Message is a single line of output produced by a container.
+type Message struct {
+	ContainerID string
+	Line        []byte
+	Source      string
+	Timestamp   time.Time
+}
+
+// Logger is the interface for log drivers. A Logger is created for each run
+// of a container and closed once the container stops.
+type Logger interface {
+	// Log writes a single message to the driver's backend.
+	Log(*Message) error
+	// Name returns the name the driver was registered with.
+	Name() string
+	// Close releases any resources held by the driver.
+	Close() error
+}
+
+// Context holds the information a log driver needs to set itself up for a
+// container.
+type Context struct {
+	Config        map[string]string
+	ContainerID   string
+	ContainerName string
+	LogPath       string
+}
+
+// Creator is the constructor of a log driver.
+type Creator func(Context) (Logger, error)
+
+// LogOptValidator checks the options given to a log driver.
+type LogOptValidator func(cfg map[string]string) error
+
+var (
+	driversMu  sync.Mutex
+	drivers    = make(map[string]Creator)
+	validators = make(map[string]LogOptValidator)
+)
+
+// RegisterLogDriver registers a log driver under the given name. It is
+// intended to be called from the init function of the driver package.
+func RegisterLogDriver(name string, c Creator) error {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if _, exists := drivers[name]; exists {
+		return fmt.Errorf("logger: log driver named '%s' is already registered", name)
+	}
+	drivers[name] = c
+	return nil
+}
+
+// GetLogDriver returns the constructor of the log driver registered under
+// the given name.
+func GetLogDriver(name string) (Creator, error) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	c, exists := drivers[name]
+	if !exists {
+		return nil, fmt.Errorf("logger: no log driver named '%s' is registered", name)
+	}
+	return c, nil
+}
+
+// RegisterLogOptValidator registers the function checking the options of the
+// log driver with the given name. Drivers without a validator do not accept
+// any option.
+func RegisterLogOptValidator(name string, v LogOptValidator) error {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if _, exists := validators[name]; exists {
+		return fmt.Errorf("logger: log opt validator named '%s' is already registered", name)
+	}
+	validators[name] = v
+	return nil
+}
+
+// ValidateLogOpts checks the options given to the log driver with the given
+// name.
+func ValidateLogOpts(name string, cfg map[string]string) error {
+	driversMu.Lock()
+	v, exists := validators[name]
+	driversMu.Unlock()
+	if exists {
+		return v(cfg)
+	}
+	for key := range cfg {
+		return fmt.Errorf("logger: unknown log opt '%s' for %s log driver", key, name)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package syslog
+
+import (
+	"fmt"
+	"log/syslog"
+	"os"
+	"path"
+
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/utils"
+)
+
+const Name = "syslog"
+
+// Syslog is a Logger implementation which sends the output of a container
+// to the local syslog daemon through its unix socket.
+type Syslog struct {
+	writer *syslog.Writer
+}
+
+func init() {
+	if err := logger.RegisterLogDriver(Name, New); err != nil {
+		panic(err)
+	}
+}
+
+// New connects to the local syslog daemon. Messages are tagged with the name
+// of the daemon binary and the short id of the container.
+func New(ctx logger.Context) (logger.Logger, error) {
+	tag := fmt.Sprintf("%s/%s", path.Base(os.Args[0]), utils.TruncateID(ctx.ContainerID))
+	w, err := syslog.New(syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &Syslog{
+		writer: w,
+	}, nil
+}
+
+// Log sends a message to syslog, with the priority depending on the stream
+// it was read from.
+func (s *Syslog) Log(msg *logger.Message) error {
+	if msg.Source == "stderr" {
+		return s.writer.Err(string(msg.Line))
+	}
+	return s.writer.Info(string(msg.Line))
+}
+
+// Close closes the connection to the syslog daemon.
+func (s *Syslog) Close() error {
+	return s.writer.Close()
+}
+
+// Name returns name of this logger
+func (s *Syslog) Name() string {
+	return Name
+}
@@ -44,7 +44,10 @@ func (daemon *Daemon) ContainerLogs(job *engine.Job) engine.Status {
 	if container == nil {
 		return job.Errorf("No such container: %s", name)
 	}
-	cLog, err := container.ReadLog("json")
+	if logType := container.getLogConfig().Type; logType != "json-file" {
+		return job.Errorf("\"logs\" is not supported for containers using the %q logging driver, only \"json-file\" can be read back", logType)
+	}
+	files, err := container.openLogFiles()
 	if err != nil && os.IsNotExist(err) {
 		// Legacy logs
 		log.Debugf("Old logs format")
@@ -67,6 +70,11 @@ func (daemon *Daemon) ContainerLogs(job *engine.Job) engine.Status {
 	} else if err != nil {
 		log.Errorf("Error reading logs (json): %s", err)
 	} else {
+		defer func() {
+			for _, f := range files {
+				f.Close()
+			}
+		}()
 		if tail != "all" {
 			var err error
 			lines, err = strconv.Atoi(tail)
@@ -76,9 +84,9 @@ func (daemon *Daemon) ContainerLogs(job *engine.Job) engine.Status {
 			}
 		}
 		if lines != 0 {
+			var cLog io.Reader
 			if lines > 0 {
-				f := cLog.(*os.File)
-				ls, err := tailfile.TailFile(f, lines)
+				ls, err := tailFiles(files, lines)
 				if err != nil {
 					return job.Error(err)
 				}
@@ -87,6 +95,12 @@ func (daemon *Daemon) ContainerLogs(job *engine.Job) engine.Status {
 					fmt.Fprintf(tmp, "%s\n", l)
 				}
 				cLog = tmp
+			} else {
+				readers := make([]io.Reader, len(files))
+				for i, f := range files {
+					readers[i] = f
+				}
+				cLog = io.MultiReader(readers...)
 			}
 			dec := json.NewDecoder(cLog)
 			l := &jsonlog.JSONLog{}
@@ -146,3 +160,17 @@ func (daemon *Daemon) ContainerLogs(job *engine.Job) engine.Status {
 	}
 	return engine.StatusOK
 }
+
+// tailFiles returns the last n lines of the given files, which are ordered
+// from the oldest to the newest, as if they were a single file.
+func tailFiles(files []*os.File, n int) ([][]byte, error) {
+	var lines [][]byte
+	for i := len(files) - 1; i >= 0 && len(lines) < n; i-- {
+		ls, err := tailfile.TailFile(files[i], n-len(lines))
+		if err != nil {
+			return nil, err
+		}
+		lines = append(ls, lines...)
+	}
+	return lines, nil
+}
@@ -0,0 +1,40 @@
+package daemon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestTailFiles(t *testing.T) {
+	var files []*os.File
+	// oldest first, the newest file is empty as it was just rotated
+	for _, content := range []string{"1\n2\n3\n", "4\n5\n", ""} {
+		f, err := ioutil.TempFile("", "docker-logs-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(f.Name())
+		defer f.Close()
+		if _, err := f.WriteString(content); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+
+	for n, expected := range map[int]string{
+		1:  "[5]",
+		2:  "[4 5]",
+		4:  "[2 3 4 5]",
+		10: "[1 2 3 4 5]",
+	} {
+		lines, err := tailFiles(files, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := fmt.Sprintf("%s", lines); s != expected {
+			t.Fatalf("tail %d: expected %s, got %s", n, expected, s)
+		}
+	}
+}
@@ -122,7 +122,7 @@ func (m *containerMonitor) Start() error {
 	for {
 		m.container.RestartCount++
 
-		if err := m.container.startLoggingToDisk(); err != nil {
+		if err := m.container.startLogging(); err != nil {
 			m.resetContainer(false)
 
 			return err
@@ -149,6 +149,7 @@ func (m *containerMonitor) Start() error {
 
 		// here container.Lock is already lost
 		afterRun = true
+		m.container.stopHealthMonitor()
 
 		m.resetMonitor(err == nil && exitStatus.ExitCode == 0)
 
@@ -207,6 +208,14 @@ func (m *containerMonitor) waitForNextRestart() {
 	}
 }
 
+// SetRestartPolicy changes the restart policy applied the next time the
+// container exits
+func (m *containerMonitor) SetRestartPolicy(policy runconfig.RestartPolicy) {
+	m.mux.Lock()
+	m.restartPolicy = policy
+	m.mux.Unlock()
+}
+
 // shouldRestart checks the restart policy and applies the rules to determine if
 // the container's process should be restarted
 func (m *containerMonitor) shouldRestart(exitCode int) bool {
@@ -247,6 +256,8 @@ func (m *containerMonitor) callback(processConfig *execdriver.ProcessConfig, pid
 	}
 
 	m.container.setRunning(pid)
+	m.container.plugNetworks(pid)
+	m.container.initHealthMonitor()
 
 	// signal that the process has started
 	// close channel only if not closed
@@ -285,6 +296,8 @@ func (m *containerMonitor) resetContainer(lock bool) {
 		log.Errorf("%s: Error close stderr: %s", container.ID, err)
 	}
 
+	container.stopLogging()
+
 	if container.command != nil && container.command.ProcessConfig.Terminal != nil {
 		if err := container.command.ProcessConfig.Terminal.Close(); err != nil {
 			log.Errorf("%s: Error closing terminal: %s", container.ID, err)
@@ -0,0 +1,211 @@
+package daemon
+
+import (
+	"fmt"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/engine"
+)
+
+// ContainerNetworkConnect connects the container CONTAINER to the network
+// NETWORK. A running container gets an interface on the network right away,
+// a stopped one when it starts.
+//
+// Syntax: network_connect NETWORK CONTAINER
+func (daemon *Daemon) ContainerNetworkConnect(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s NETWORK CONTAINER", job.Name)
+	}
+	name := job.Args[1]
+	container := daemon.Get(name)
+	if container == nil {
+		return job.Errorf("No such container: %s", name)
+	}
+	if err := container.ConnectNetwork(job.Args[0]); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// ContainerNetworkDisconnect disconnects the container CONTAINER from the
+// network NETWORK.
+//
+// Syntax: network_disconnect NETWORK CONTAINER
+func (daemon *Daemon) ContainerNetworkDisconnect(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s NETWORK CONTAINER", job.Name)
+	}
+	name := job.Args[1]
+	container := daemon.Get(name)
+	if container == nil {
+		return job.Errorf("No such container: %s", name)
+	}
+	if err := container.DisconnectNetwork(job.Args[0]); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// lookupNetwork returns the name and the ID of the network called or with
+// the ID name.
+func (container *Container) lookupNetwork(name string) (string, string, error) {
+	job := container.daemon.eng.Job("network_inspect", name)
+	env, err := job.Stdout.AddEnv()
+	if err != nil {
+		return "", "", err
+	}
+	if err := job.Run(); err != nil {
+		return "", "", err
+	}
+	return env.Get("Name"), env.Get("Id"), nil
+}
+
+func (container *Container) ConnectNetwork(name string) error {
+	container.Lock()
+	defer container.Unlock()
+
+	mode := container.hostConfig.NetworkMode
+	if container.Config.NetworkDisabled || !mode.IsPrivate() {
+		return fmt.Errorf("Container %s does not use bridge networking and cannot be connected to a network", container.ID)
+	}
+	name, id, err := container.lookupNetwork(name)
+	if err != nil {
+		return err
+	}
+	if _, exists := container.NetworkSettings.Networks[name]; exists {
+		return fmt.Errorf("Conflict: container %s is already connected to network %s", container.ID, name)
+	}
+
+	var pid int
+	if container.Running {
+		pid = container.Pid
+	}
+	endpoint, err := container.joinNetwork(id, pid)
+	if err != nil {
+		return err
+	}
+	if container.NetworkSettings.Networks == nil {
+		container.NetworkSettings.Networks = make(map[string]*EndpointSettings)
+	}
+	container.NetworkSettings.Networks[name] = endpoint
+	return container.toDisk()
+}
+
+func (container *Container) DisconnectNetwork(name string) error {
+	container.Lock()
+	defer container.Unlock()
+
+	name, id, err := container.lookupNetwork(name)
+	if err != nil {
+		return err
+	}
+	if _, exists := container.NetworkSettings.Networks[name]; !exists {
+		return fmt.Errorf("Container %s is not connected to network %s", container.ID, name)
+	}
+	if err := container.leaveNetwork(id, true); err != nil {
+		return err
+	}
+	delete(container.NetworkSettings.Networks, name)
+	return container.toDisk()
+}
+
+// joinNetwork adds the container to the network id and, when pid is not
+// zero, adds an interface on the network to the namespace of pid. The
+// container is not left on the network when the interface cannot be added.
+func (container *Container) joinNetwork(id string, pid int) (*EndpointSettings, error) {
+	if err := container.daemon.eng.Job("network_join", id, container.ID).Run(); err != nil {
+		return nil, err
+	}
+	if pid == 0 {
+		return &EndpointSettings{NetworkID: id}, nil
+	}
+	endpoint, err := container.createEndpoint(id, pid)
+	if err != nil {
+		if err := container.leaveNetwork(id, true); err != nil {
+			log.Errorf("%s: Error leaving network %s: %s", container.ID, id, err)
+		}
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// createEndpoint adds an interface on the network id to the namespace of
+// pid.
+func (container *Container) createEndpoint(id string, pid int) (*EndpointSettings, error) {
+	job := container.daemon.eng.Job("endpoint_create", id, container.ID)
+	job.SetenvInt("Pid", pid)
+	env, err := job.Stdout.AddEnv()
+	if err != nil {
+		return nil, err
+	}
+	if err := job.Run(); err != nil {
+		return nil, err
+	}
+	return &EndpointSettings{
+		NetworkID:           env.Get("NetworkID"),
+		Interface:           env.Get("Interface"),
+		IPAddress:           env.Get("IPAddress"),
+		IPPrefixLen:         env.GetInt("IPPrefixLen"),
+		Gateway:             env.Get("Gateway"),
+		MacAddress:          env.Get("MacAddress"),
+		GlobalIPv6Address:   env.Get("GlobalIPv6Address"),
+		GlobalIPv6PrefixLen: env.GetInt("GlobalIPv6PrefixLen"),
+		IPv6Gateway:         env.Get("IPv6Gateway"),
+	}, nil
+}
+
+// leaveNetwork removes the interface of the container on the network id, and
+// its membership of the network if remove is set.
+func (container *Container) leaveNetwork(id string, remove bool) error {
+	if remove {
+		return container.daemon.eng.Job("network_leave", id, container.ID).Run()
+	}
+	job := container.daemon.eng.Job("endpoint_delete", id, container.ID)
+	job.SetenvBool("overrideShutdown", true)
+	return job.Run()
+}
+
+// plugNetworks adds the interfaces on the networks of the container to the
+// namespace of its process pid. It is called each time the process of the
+// container starts, including restarts.
+func (container *Container) plugNetworks(pid int) {
+	for name, endpoint := range container.NetworkSettings.Networks {
+		// Release the interface of a previous process of the container
+		if err := container.leaveNetwork(endpoint.NetworkID, false); err != nil {
+			log.Errorf("%s: Error releasing network %s: %s", container.ID, name, err)
+		}
+		joined, err := container.createEndpoint(endpoint.NetworkID, pid)
+		if err != nil {
+			log.Errorf("%s: Error connecting to network %s: %s", container.ID, name, err)
+			joined = &EndpointSettings{NetworkID: endpoint.NetworkID}
+		}
+		container.NetworkSettings.Networks[name] = joined
+	}
+}
+
+// releaseNetworks removes the interfaces of the container on its networks
+// and returns its memberships, without addresses.
+func (container *Container) releaseNetworks() map[string]*EndpointSettings {
+	if len(container.NetworkSettings.Networks) == 0 {
+		return nil
+	}
+	networks := make(map[string]*EndpointSettings)
+	for name, endpoint := range container.NetworkSettings.Networks {
+		if err := container.leaveNetwork(endpoint.NetworkID, false); err != nil {
+			log.Errorf("%s: Error releasing network %s: %s", container.ID, name, err)
+		}
+		networks[name] = &EndpointSettings{NetworkID: endpoint.NetworkID}
+	}
+	return networks
+}
+
+// disconnectNetworks removes the container from all its networks, when it is
+// destroyed.
+func (container *Container) disconnectNetworks() {
+	for name, endpoint := range container.NetworkSettings.Networks {
+		if err := container.leaveNetwork(endpoint.NetworkID, true); err != nil {
+			log.Errorf("%s: Error disconnecting from network %s: %s", container.ID, name, err)
+		}
+	}
+	container.NetworkSettings.Networks = nil
+}
@@ -9,13 +9,32 @@ import (
 type PortMapping map[string]string // Deprecated
 
 type NetworkSettings struct {
-	IPAddress   string
-	IPPrefixLen int
-	MacAddress  string
-	Gateway     string
-	Bridge      string
-	PortMapping map[string]PortMapping // Deprecated
-	Ports       nat.PortMap
+	IPAddress           string
+	IPPrefixLen         int
+	MacAddress          string
+	Gateway             string
+	GlobalIPv6Address   string
+	GlobalIPv6PrefixLen int
+	IPv6Gateway         string
+	Bridge              string
+	PortMapping         map[string]PortMapping // Deprecated
+	Ports               nat.PortMap
+	Networks            map[string]*EndpointSettings
+}
+
+// EndpointSettings describes the interface of a container on a network
+// created with `docker network create`. Only the NetworkID is set while the
+// container is not running.
+type EndpointSettings struct {
+	NetworkID           string
+	Interface           string
+	IPAddress           string
+	IPPrefixLen         int
+	Gateway             string
+	MacAddress          string
+	GlobalIPv6Address   string
+	GlobalIPv6PrefixLen int
+	IPv6Gateway         string
 }
 
 func (settings *NetworkSettings) PortMappingAPI() *engine.Table {
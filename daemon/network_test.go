@@ -0,0 +1,76 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/docker/engine"
+)
+
+func newNetworkEngine(t *testing.T, createEndpoint engine.Handler) (*engine.Engine, *[]string) {
+	var left []string
+	eng := engine.New()
+	eng.Logging = false
+	for name, handler := range map[string]engine.Handler{
+		"network_join":    func(job *engine.Job) engine.Status { return engine.StatusOK },
+		"endpoint_create": createEndpoint,
+		"network_leave": func(job *engine.Job) engine.Status {
+			left = append(left, job.Args[0])
+			return engine.StatusOK
+		},
+	} {
+		if err := eng.Register(name, handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return eng, &left
+}
+
+func TestJoinNetwork(t *testing.T) {
+	eng, left := newNetworkEngine(t, func(job *engine.Job) engine.Status {
+		out := &engine.Env{}
+		out.Set("NetworkID", job.Args[0])
+		out.Set("IPAddress", "10.201.0.2")
+		out.SetInt("IPPrefixLen", 16)
+		out.Set("GlobalIPv6Address", "2001:db8::2")
+		out.SetInt("GlobalIPv6PrefixLen", 64)
+		out.Set("IPv6Gateway", "2001:db8::1")
+		if _, err := out.WriteTo(job.Stdout); err != nil {
+			return job.Error(err)
+		}
+		return engine.StatusOK
+	})
+	container := &Container{ID: "container_id", daemon: &Daemon{eng: eng}}
+
+	endpoint, err := container.joinNetwork("testnet", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := EndpointSettings{
+		NetworkID:           "testnet",
+		IPAddress:           "10.201.0.2",
+		IPPrefixLen:         16,
+		GlobalIPv6Address:   "2001:db8::2",
+		GlobalIPv6PrefixLen: 64,
+		IPv6Gateway:         "2001:db8::1",
+	}
+	if *endpoint != expected {
+		t.Fatalf("Expected the endpoint %+v, got %+v", expected, *endpoint)
+	}
+	if len(*left) != 0 {
+		t.Fatalf("Expected the container to stay on the network, it left %v", *left)
+	}
+}
+
+func TestJoinNetworkLeavesOnError(t *testing.T) {
+	eng, left := newNetworkEngine(t, func(job *engine.Job) engine.Status {
+		return job.Errorf("No address left on network %s", job.Args[0])
+	})
+	container := &Container{ID: "container_id", daemon: &Daemon{eng: eng}}
+
+	if _, err := container.joinNetwork("testnet", 1); err == nil {
+		t.Fatal("Expected an error when the endpoint cannot be created")
+	}
+	if len(*left) != 1 || (*left)[0] != "testnet" {
+		t.Fatalf("Expected the container to leave the network, it left %v", *left)
+	}
+}
@@ -43,6 +43,12 @@ func (i *ifaces) Set(key string, n *networkInterface) {
 	i.Unlock()
 }
 
+func (i *ifaces) Delete(key string) {
+	i.Lock()
+	delete(i.c, key)
+	i.Unlock()
+}
+
 func (i *ifaces) Get(key string) *networkInterface {
 	i.Lock()
 	res := i.c[key]
@@ -73,27 +79,45 @@ var (
 
 	bridgeIface   string
 	bridgeNetwork *net.IPNet
+	// bridgeIPv6 is the link-local address of the bridge with --ipv6, which
+	// is the IPv6 gateway of the containers
+	bridgeIPv6 = "fe80::1/64"
+	// globalIPv6Network is the subnet given with --fixed-cidr-v6, in which
+	// the containers get their global IPv6 address
+	globalIPv6Network *net.IPNet
+
+	enableIPTables              bool
+	enableIPv6                  bool
+	interContainerCommunication bool
+	ipMasq                      bool
 
 	defaultBindingIP  = net.ParseIP("0.0.0.0")
 	currentInterfaces = ifaces{c: make(map[string]*networkInterface)}
 )
 
-func InitDriver(job *engine.Job) engine.Status {
+func init() {
+	networkdriver.Register("bridge", Init)
+}
+
+// Init sets up the bridge of the daemon and returns the bridge driver.
+func Init(config *engine.Env) (networkdriver.Driver, error) {
 	var (
-		network        *net.IPNet
-		enableIPTables = job.GetenvBool("EnableIptables")
-		icc            = job.GetenvBool("InterContainerCommunication")
-		ipMasq         = job.GetenvBool("EnableIpMasq")
-		ipForward      = job.GetenvBool("EnableIpForward")
-		bridgeIP       = job.Getenv("BridgeIP")
-		fixedCIDR      = job.Getenv("FixedCIDR")
+		network     *net.IPNet
+		ipForward   = config.GetBool("EnableIpForward")
+		bridgeIP    = config.Get("BridgeIP")
+		fixedCIDR   = config.Get("FixedCIDR")
+		fixedCIDRv6 = config.Get("FixedCIDRv6")
 	)
+	enableIPTables = config.GetBool("EnableIptables")
+	enableIPv6 = config.GetBool("EnableIPv6")
+	interContainerCommunication = config.GetBool("InterContainerCommunication")
+	ipMasq = config.GetBool("EnableIpMasq")
 
-	if defaultIP := job.Getenv("DefaultBindingIP"); defaultIP != "" {
+	if defaultIP := config.Get("DefaultBindingIP"); defaultIP != "" {
 		defaultBindingIP = net.ParseIP(defaultIP)
 	}
 
-	bridgeIface = job.Getenv("BridgeIface")
+	bridgeIface = config.Get("BridgeIface")
 	usingDefaultBridge := false
 	if bridgeIface == "" {
 		usingDefaultBridge = true
@@ -104,16 +128,16 @@ func InitDriver(job *engine.Job) engine.Status {
 	if err != nil {
 		// If we're not using the default bridge, fail without trying to create it
 		if !usingDefaultBridge {
-			return job.Error(err)
+			return nil, err
 		}
 		// If the bridge interface is not found (or has no address), try to create it and/or add an address
 		if err := configureBridge(bridgeIP); err != nil {
-			return job.Error(err)
+			return nil, err
 		}
 
 		addr, err = networkdriver.GetIfaceAddr(bridgeIface)
 		if err != nil {
-			return job.Error(err)
+			return nil, err
 		}
 		network = addr.(*net.IPNet)
 	} else {
@@ -122,37 +146,37 @@ func InitDriver(job *engine.Job) engine.Status {
 		if bridgeIP != "" {
 			bip, _, err := net.ParseCIDR(bridgeIP)
 			if err != nil {
-				return job.Error(err)
+				return nil, err
 			}
 			if !network.IP.Equal(bip) {
-				return job.Errorf("bridge ip (%s) does not match existing bridge configuration %s", network.IP, bip)
+				return nil, fmt.Errorf("bridge ip (%s) does not match existing bridge configuration %s", network.IP, bip)
 			}
 		}
 	}
 
 	// Configure iptables for link support
 	if enableIPTables {
-		if err := setupIPTables(addr, icc, ipMasq); err != nil {
-			return job.Error(err)
+		if err := setupIPTables(addr, interContainerCommunication, ipMasq); err != nil {
+			return nil, err
 		}
 	}
 
 	if ipForward {
 		// Enable IPv4 forwarding
 		if err := ioutil.WriteFile("/proc/sys/net/ipv4/ip_forward", []byte{'1', '\n'}, 0644); err != nil {
-			job.Logf("WARNING: unable to enable IPv4 forwarding: %s\n", err)
+			log.Warnf("WARNING: unable to enable IPv4 forwarding: %s", err)
 		}
 	}
 
 	// We can always try removing the iptables
 	if err := iptables.RemoveExistingChain("DOCKER"); err != nil {
-		return job.Error(err)
+		return nil, err
 	}
 
 	if enableIPTables {
 		chain, err := iptables.NewChain("DOCKER", bridgeIface)
 		if err != nil {
-			return job.Error(err)
+			return nil, err
 		}
 		portmapper.SetIptablesChain(chain)
 	}
@@ -161,28 +185,38 @@ func InitDriver(job *engine.Job) engine.Status {
 	if fixedCIDR != "" {
 		_, subnet, err := net.ParseCIDR(fixedCIDR)
 		if err != nil {
-			return job.Error(err)
+			return nil, err
 		}
 		log.Debugf("Subnet: %v", subnet)
 		if err := ipallocator.RegisterSubnet(bridgeNetwork, subnet); err != nil {
-			return job.Error(err)
+			return nil, err
 		}
 	}
 
+	if enableIPv6 {
+		if err := setupIPv6(fixedCIDRv6, ipForward); err != nil {
+			return nil, err
+		}
+	}
+
+	return &driver{networks: make(map[string]*bridgeNet)}, nil
+}
+
+// Install registers the jobs publishing the ports of the containers on the
+// bridge of the daemon, and linking them.
+func (d *driver) Install(eng *engine.Engine) error {
 	// https://github.com/docker/docker/issues/2768
-	job.Eng.Hack_SetGlobalVar("httpapi.bridgeIP", bridgeNetwork.IP)
+	eng.Hack_SetGlobalVar("httpapi.bridgeIP", bridgeNetwork.IP)
 
 	for name, f := range map[string]engine.Handler{
-		"allocate_interface": Allocate,
-		"release_interface":  Release,
-		"allocate_port":      AllocatePort,
-		"link":               LinkContainers,
+		"allocate_port": AllocatePort,
+		"link":          LinkContainers,
 	} {
-		if err := job.Eng.Register(name, f); err != nil {
-			return job.Error(err)
+		if err := eng.Register(name, f); err != nil {
+			return err
 		}
 	}
-	return engine.StatusOK
+	return nil
 }
 
 func setupIPTables(addr net.Addr, icc, ipmasq bool) error {
@@ -253,6 +287,102 @@ func setupIPTables(addr net.Addr, icc, ipmasq bool) error {
 	return nil
 }
 
+// setupIP6Tables sets up the ip6tables rules forwarding the IPv6 traffic of
+// the bridge. There is no masquerading, the containers have global addresses.
+func setupIP6Tables(icc bool) error {
+	var (
+		args       = []string{"FORWARD", "-i", bridgeIface, "-o", bridgeIface, "-j"}
+		acceptArgs = append(args, "ACCEPT")
+		dropArgs   = append(args, "DROP")
+		iccArgs    = acceptArgs
+	)
+	if icc {
+		iptables.Raw6(append([]string{"-D"}, dropArgs...)...)
+	} else {
+		iptables.Raw6(append([]string{"-D"}, acceptArgs...)...)
+		iccArgs = dropArgs
+	}
+
+	for _, rule := range [][]string{
+		iccArgs,
+		// Accept all non-intercontainer outgoing packets
+		{"FORWARD", "-i", bridgeIface, "!", "-o", bridgeIface, "-j", "ACCEPT"},
+		// Accept incoming packets for existing connections
+		{"FORWARD", "-o", bridgeIface, "-m", "conntrack", "--ctstate", "RELATED,ESTABLISHED", "-j", "ACCEPT"},
+	} {
+		if iptables.Exists6(rule...) {
+			continue
+		}
+		if output, err := iptables.Raw6(append([]string{"-I"}, rule...)...); err != nil {
+			return fmt.Errorf("Unable to set up IPv6 forwarding: %s", err)
+		} else if len(output) != 0 {
+			return &iptables.ChainError{Chain: "FORWARD", Output: output}
+		}
+	}
+	return nil
+}
+
+// setupIPv6 adds the link-local gateway address to the bridge and, if
+// fixedCIDRv6 is set, routes the global subnet of the containers to the
+// bridge.
+func setupIPv6(fixedCIDRv6 string, ipForward bool) error {
+	iface, err := net.InterfaceByName(bridgeIface)
+	if err != nil {
+		return err
+	}
+	ip, ipNet, err := net.ParseCIDR(bridgeIPv6)
+	if err != nil {
+		return err
+	}
+	if !ifaceHasIP(iface, ip) {
+		if err := netlink.NetworkLinkAddIp(iface, ip, ipNet); err != nil {
+			return fmt.Errorf("Unable to add IPv6 address %s to bridge %s: %s", bridgeIPv6, bridgeIface, err)
+		}
+	}
+
+	if fixedCIDRv6 != "" {
+		_, subnet, err := net.ParseCIDR(fixedCIDRv6)
+		if err != nil {
+			return err
+		}
+		if subnet.IP.To4() != nil {
+			return fmt.Errorf("%s is not an IPv6 subnet", fixedCIDRv6)
+		}
+		log.Debugf("IPv6 subnet: %v", subnet)
+		if err := netlink.AddRoute(subnet.String(), "", "", bridgeIface); err != nil && !os.IsExist(err) {
+			return fmt.Errorf("Unable to route %s to bridge %s: %s", subnet, bridgeIface, err)
+		}
+		globalIPv6Network = subnet
+	}
+
+	if enableIPTables {
+		if err := setupIP6Tables(interContainerCommunication); err != nil {
+			return err
+		}
+	}
+
+	if ipForward {
+		// Enable IPv6 forwarding
+		if err := ioutil.WriteFile("/proc/sys/net/ipv6/conf/all/forwarding", []byte{'1', '\n'}, 0644); err != nil {
+			log.Warnf("WARNING: unable to enable IPv6 forwarding: %s", err)
+		}
+	}
+	return nil
+}
+
+func ifaceHasIP(iface *net.Interface, ip net.IP) bool {
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // configureBridge attempts to create and configure a network bridge interface named `bridgeIface` on the host
 // If bridgeIP is empty, it will try to find a non-conflicting IP from the Docker-specified private ranges
 // If the bridge `bridgeIface` already exists, it will only perform the IP address association with the existing
@@ -358,72 +488,6 @@ func generateMacAddr(ip net.IP) net.HardwareAddr {
 	return hw
 }
 
-// Allocate a network interface
-func Allocate(job *engine.Job) engine.Status {
-	var (
-		ip          net.IP
-		mac         net.HardwareAddr
-		err         error
-		id          = job.Args[0]
-		requestedIP = net.ParseIP(job.Getenv("RequestedIP"))
-	)
-
-	if requestedIP != nil {
-		ip, err = ipallocator.RequestIP(bridgeNetwork, requestedIP)
-	} else {
-		ip, err = ipallocator.RequestIP(bridgeNetwork, nil)
-	}
-	if err != nil {
-		return job.Error(err)
-	}
-
-	// If no explicit mac address was given, generate a random one.
-	if mac, err = net.ParseMAC(job.Getenv("RequestedMac")); err != nil {
-		mac = generateMacAddr(ip)
-	}
-
-	out := engine.Env{}
-	out.Set("IP", ip.String())
-	out.Set("Mask", bridgeNetwork.Mask.String())
-	out.Set("Gateway", bridgeNetwork.IP.String())
-	out.Set("MacAddress", mac.String())
-	out.Set("Bridge", bridgeIface)
-
-	size, _ := bridgeNetwork.Mask.Size()
-	out.SetInt("IPPrefixLen", size)
-
-	currentInterfaces.Set(id, &networkInterface{
-		IP: ip,
-	})
-
-	out.WriteTo(job.Stdout)
-
-	return engine.StatusOK
-}
-
-// release an interface for a select ip
-func Release(job *engine.Job) engine.Status {
-	var (
-		id                 = job.Args[0]
-		containerInterface = currentInterfaces.Get(id)
-	)
-
-	if containerInterface == nil {
-		return job.Errorf("No network information to release for %s", id)
-	}
-
-	for _, nat := range containerInterface.PortMappings {
-		if err := portmapper.Unmap(nat); err != nil {
-			log.Infof("Unable to unmap port %s: %s", nat, err)
-		}
-	}
-
-	if err := ipallocator.ReleaseIP(bridgeNetwork, containerInterface.IP); err != nil {
-		log.Infof("Unable to release ip %s", err)
-	}
-	return engine.StatusOK
-}
-
 // Allocate an external port and map it to the interface
 func AllocatePort(job *engine.Job) engine.Status {
 	var (
@@ -438,6 +502,10 @@ func AllocatePort(job *engine.Job) engine.Status {
 		network       = currentInterfaces.Get(id)
 	)
 
+	if network == nil {
+		return job.Errorf("No network interface allocated for %s", id)
+	}
+
 	if hostIP != "" {
 		ip = net.ParseIP(hostIP)
 		if ip == nil {
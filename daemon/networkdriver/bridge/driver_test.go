@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/docker/docker/daemon/networkdriver"
 	"github.com/docker/docker/daemon/networkdriver/portmapper"
 	"github.com/docker/docker/engine"
 )
@@ -50,26 +51,31 @@ func newPortAllocationJobWithInvalidHostIP(eng *engine.Engine, port int) (job *e
 	return
 }
 
+// createDefaultEndpoint initializes the driver and allocates an interface to
+// container_id on the network of the daemon.
+func createDefaultEndpoint(t *testing.T) {
+	d, err := Init(&engine.Env{})
+	if err != nil {
+		t.Fatalf("Failed to initialize network driver: %s", err)
+	}
+	if err := d.CreateNetwork("network_id", map[string]string{networkdriver.DefaultOption: "true"}); err != nil {
+		t.Fatalf("Failed to set up the network of the daemon: %s", err)
+	}
+	if _, err := d.CreateEndpoint("network_id", "container_id", map[string]string{}); err != nil {
+		t.Fatalf("Failed to allocate network interface: %s", err)
+	}
+}
+
 func TestAllocatePortDetection(t *testing.T) {
 	eng := engine.New()
 	eng.Logging = false
 
 	freePort := findFreePort(t)
 
-	// Init driver
-	job := eng.Job("initdriver")
-	if res := InitDriver(job); res != engine.StatusOK {
-		t.Fatal("Failed to initialize network driver")
-	}
-
-	// Allocate interface
-	job = eng.Job("allocate_interface", "container_id")
-	if res := Allocate(job); res != engine.StatusOK {
-		t.Fatal("Failed to allocate network interface")
-	}
+	createDefaultEndpoint(t)
 
 	// Allocate same port twice, expect failure on second call
-	job = newPortAllocationJob(eng, freePort)
+	job := newPortAllocationJob(eng, freePort)
 	if res := AllocatePort(job); res != engine.StatusOK {
 		t.Fatal("Failed to find a free port to allocate")
 	}
@@ -84,20 +90,10 @@ func TestHostnameFormatChecking(t *testing.T) {
 
 	freePort := findFreePort(t)
 
-	// Init driver
-	job := eng.Job("initdriver")
-	if res := InitDriver(job); res != engine.StatusOK {
-		t.Fatal("Failed to initialize network driver")
-	}
-
-	// Allocate interface
-	job = eng.Job("allocate_interface", "container_id")
-	if res := Allocate(job); res != engine.StatusOK {
-		t.Fatal("Failed to allocate network interface")
-	}
+	createDefaultEndpoint(t)
 
 	// Allocate port with invalid HostIP, expect failure with Bad Request http status
-	job = newPortAllocationJobWithInvalidHostIP(eng, freePort)
+	job := newPortAllocationJobWithInvalidHostIP(eng, freePort)
 	if res := AllocatePort(job); res == engine.StatusOK {
 		t.Fatal("Failed to check invalid HostIP")
 	}
@@ -118,3 +114,41 @@ func TestMacAddrGeneration(t *testing.T) {
 		t.Fatal("Non-unique MAC address")
 	}
 }
+
+func TestIPv6Endpoint(t *testing.T) {
+	config := &engine.Env{}
+	config.SetBool("EnableIPv6", true)
+	config.Set("FixedCIDRv6", "2001:db8:1::/64")
+	d, err := Init(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize network driver: %s", err)
+	}
+	defer func() {
+		enableIPv6 = false
+		globalIPv6Network = nil
+	}()
+	if err := d.CreateNetwork("network_id", map[string]string{networkdriver.DefaultOption: "true"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ep, err := d.CreateEndpoint("network_id", "ipv6_container_id", map[string]string{"RequestedIPv6": "2001:db8:1::42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.DeleteEndpoint("network_id", "ipv6_container_id")
+	if ep.GlobalIPv6Address.String() != "2001:db8:1::42" || ep.GlobalIPv6PrefixLen != 64 || ep.IPv6Gateway.String() != "fe80::1" {
+		t.Fatalf("Unexpected IPv6 address %s/%d with gateway %s", ep.GlobalIPv6Address, ep.GlobalIPv6PrefixLen, ep.IPv6Gateway)
+	}
+
+	if _, err := d.CreateEndpoint("network_id", "other_container_id", map[string]string{"RequestedIPv6": "2001:db8:1::42"}); err == nil {
+		t.Fatal("Expected an error allocating an IPv6 address twice")
+	}
+	ep, err = d.CreateEndpoint("network_id", "other_container_id", map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.DeleteEndpoint("network_id", "other_container_id")
+	if !globalIPv6Network.Contains(ep.GlobalIPv6Address) || ep.GlobalIPv6Address.String() == "2001:db8:1::42" {
+		t.Fatalf("Unexpected IPv6 address %s", ep.GlobalIPv6Address)
+	}
+}
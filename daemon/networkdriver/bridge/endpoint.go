@@ -0,0 +1,114 @@
+package bridge
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+
+	"github.com/docker/libcontainer/netlink"
+	"github.com/docker/libcontainer/network"
+	"github.com/docker/libcontainer/system"
+	"github.com/docker/libcontainer/utils"
+)
+
+// plugEndpoint creates a veth pair, attaches one end to bridge and moves the
+// other one into the network namespace of the process pid, where it is
+// configured with the address ip. It returns the name of the interface in
+// the namespace and the name of the one on the host.
+func plugEndpoint(bridge string, pid int, ip *net.IPNet, mac net.HardwareAddr) (string, string, error) {
+	hostIf, peerIf, err := createVethPair()
+	if err != nil {
+		return "", "", err
+	}
+
+	var ifName string
+	if err := func() error {
+		if err := network.SetInterfaceMaster(hostIf, bridge); err != nil {
+			return err
+		}
+		if err := network.InterfaceUp(hostIf); err != nil {
+			return err
+		}
+		if err := network.SetInterfaceInNamespacePid(peerIf, pid); err != nil {
+			return err
+		}
+		return inNetNS(pid, func() error {
+			ifName = nextInterfaceName()
+			if err := network.ChangeInterfaceName(peerIf, ifName); err != nil {
+				return err
+			}
+			if err := network.SetInterfaceMac(ifName, mac.String()); err != nil {
+				return err
+			}
+			if err := network.SetInterfaceIp(ifName, ip.String()); err != nil {
+				return err
+			}
+			return network.InterfaceUp(ifName)
+		})
+	}(); err != nil {
+		netlink.NetworkLinkDel(hostIf)
+		return "", "", err
+	}
+	return ifName, hostIf, nil
+}
+
+func createVethPair() (string, string, error) {
+	for i := 0; i < 10; i++ {
+		name1, err := utils.GenerateRandomName("veth", 7)
+		if err != nil {
+			return "", "", err
+		}
+		name2, err := utils.GenerateRandomName("veth", 7)
+		if err != nil {
+			return "", "", err
+		}
+		if err := network.CreateVethPair(name1, name2, 0); err != nil {
+			if err == netlink.ErrInterfaceExists {
+				continue
+			}
+			return "", "", err
+		}
+		return name1, name2, nil
+	}
+	return "", "", fmt.Errorf("Unable to find a free name for a veth pair")
+}
+
+// nextInterfaceName returns the first ethN name not used in the current
+// network namespace.
+func nextInterfaceName() string {
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("eth%d", i)
+		if _, err := net.InterfaceByName(name); err != nil {
+			return name
+		}
+	}
+}
+
+// inNetNS runs fn in the network namespace of the process pid.
+func inNetNS(pid int, fn func() error) error {
+	// The namespace is a property of the thread, which must not be
+	// reused by other goroutines until it is switched back.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	origNS, err := os.Open(fmt.Sprintf("/proc/self/task/%d/ns/net", syscall.Gettid()))
+	if err != nil {
+		return err
+	}
+	defer origNS.Close()
+
+	ns, err := os.Open(fmt.Sprintf("/proc/%d/ns/net", pid))
+	if err != nil {
+		return err
+	}
+	defer ns.Close()
+
+	if err := system.Setns(ns.Fd(), syscall.CLONE_NEWNET); err != nil {
+		return fmt.Errorf("Unable to enter the network namespace of %d: %s", pid, err)
+	}
+	defer system.Setns(origNS.Fd(), syscall.CLONE_NEWNET)
+
+	return fn()
+}
@@ -0,0 +1,440 @@
+package bridge
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/networkdriver"
+	"github.com/docker/docker/daemon/networkdriver/ipallocator"
+	"github.com/docker/docker/daemon/networkdriver/portmapper"
+	"github.com/docker/docker/pkg/iptables"
+	"github.com/docker/docker/pkg/networkfs/resolvconf"
+	"github.com/docker/libcontainer/netlink"
+)
+
+// driver implements networkdriver.Driver with a bridge per network. The
+// network of the daemon uses the bridge given with -b, docker0 by default.
+type driver struct {
+	networks map[string]*bridgeNet
+	sync.Mutex
+}
+
+type bridgeNet struct {
+	bridge string
+	// addr is the address of the bridge, which is the gateway of the
+	// network, along with the mask of the subnet
+	addr      *net.IPNet
+	isDefault bool
+	endpoints map[string]*endpoint
+}
+
+// endpoint is the interface of a container on a network.
+type endpoint struct {
+	ip            net.IP
+	ipv6          net.IP
+	mac           net.HardwareAddr
+	hostInterface string
+}
+
+func (d *driver) String() string {
+	return "bridge"
+}
+
+// CreateNetwork sets up the bridge of the network id. The Subnet, Gateway
+// and Bridge options are chosen by the driver when they are not given.
+func (d *driver) CreateNetwork(id string, options map[string]string) error {
+	d.Lock()
+	defer d.Unlock()
+
+	if _, exists := d.networks[id]; exists {
+		return fmt.Errorf("Network %s already exists", id)
+	}
+
+	if options[networkdriver.DefaultOption] == "true" {
+		options["Bridge"] = bridgeIface
+		options["Subnet"] = (&net.IPNet{IP: bridgeNetwork.IP.Mask(bridgeNetwork.Mask), Mask: bridgeNetwork.Mask}).String()
+		options["Gateway"] = bridgeNetwork.IP.String()
+		d.networks[id] = &bridgeNet{
+			bridge:    bridgeIface,
+			addr:      bridgeNetwork,
+			isDefault: true,
+			endpoints: make(map[string]*endpoint),
+		}
+		return nil
+	}
+
+	var (
+		subnet *net.IPNet
+		err    error
+	)
+	if cidr := options["Subnet"]; cidr != "" {
+		if _, subnet, err = net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("Bad parameter: invalid subnet %s", cidr)
+		}
+		if ones, bits := subnet.Mask.Size(); bits-ones < 2 {
+			return fmt.Errorf("Bad parameter: subnet %s is too small", cidr)
+		}
+		if err := d.checkNetworkOverlaps(subnet); err != nil {
+			return err
+		}
+	} else if subnet, err = d.findNetworkSubnet(); err != nil {
+		return err
+	}
+
+	gateway := firstHost(subnet)
+	if ip := options["Gateway"]; ip != "" {
+		if gateway = net.ParseIP(ip); gateway == nil || !subnet.Contains(gateway) {
+			return fmt.Errorf("Bad parameter: invalid gateway %s for subnet %s", ip, subnet)
+		}
+	}
+	bridge := options["Bridge"]
+	if bridge == "" {
+		bridge = "br-" + id[:12]
+	}
+
+	n := &bridgeNet{
+		bridge:    bridge,
+		addr:      &net.IPNet{IP: gateway, Mask: subnet.Mask},
+		endpoints: make(map[string]*endpoint),
+	}
+	if err := d.setupNetworkBridge(n); err != nil {
+		d.removeNetworkBridge(n)
+		return err
+	}
+	// The gateway is not handed out to containers
+	if _, err := ipallocator.RequestIP(n.addr, gateway); err != nil && err != ipallocator.ErrIPOutOfRange {
+		d.removeNetworkBridge(n)
+		return err
+	}
+	d.networks[id] = n
+
+	options["Subnet"] = subnet.String()
+	options["Gateway"] = gateway.String()
+	options["Bridge"] = bridge
+	return nil
+}
+
+// DeleteNetwork removes the iptables rules and the bridge of the network id.
+func (d *driver) DeleteNetwork(id string) error {
+	d.Lock()
+	defer d.Unlock()
+
+	n, err := d.get(id)
+	if err != nil {
+		return err
+	}
+	if n.isDefault {
+		return fmt.Errorf("The network of the daemon cannot be removed")
+	}
+	d.removeNetworkBridge(n)
+	ipallocator.ReleaseIP(n.addr, n.addr.IP)
+	delete(d.networks, id)
+	return nil
+}
+
+// CreateEndpoint allocates an address on the network to the interface
+// endpointID, and a global IPv6 address on the network of the daemon if
+// --fixed-cidr-v6 is set.
+func (d *driver) CreateEndpoint(networkID, endpointID string, options map[string]string) (*networkdriver.Endpoint, error) {
+	d.Lock()
+	defer d.Unlock()
+
+	n, err := d.get(networkID)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := n.endpoints[endpointID]; exists {
+		return nil, fmt.Errorf("Conflict: endpoint %s already exists on network %s", endpointID, networkID)
+	}
+
+	ip, err := ipallocator.RequestIP(n.addr, net.ParseIP(options["RequestedIP"]))
+	if err != nil {
+		return nil, err
+	}
+	// If no explicit mac address was given, generate one from the IP.
+	mac, err := net.ParseMAC(options["RequestedMac"])
+	if err != nil {
+		mac = generateMacAddr(ip)
+	}
+
+	size, _ := n.addr.Mask.Size()
+	ep := &networkdriver.Endpoint{
+		IPAddress:   ip,
+		IPPrefixLen: size,
+		Gateway:     n.addr.IP,
+		MacAddress:  mac,
+		Bridge:      n.bridge,
+	}
+
+	var ipv6 net.IP
+	if n.isDefault && globalIPv6Network != nil {
+		if ipv6, err = ipallocator.RequestIP(globalIPv6Network, net.ParseIP(options["RequestedIPv6"])); err != nil {
+			ipallocator.ReleaseIP(n.addr, ip)
+			return nil, err
+		}
+		gateway, _, _ := net.ParseCIDR(bridgeIPv6)
+		ep.GlobalIPv6Address = ipv6
+		ep.GlobalIPv6PrefixLen, _ = globalIPv6Network.Mask.Size()
+		ep.IPv6Gateway = gateway
+	}
+
+	n.endpoints[endpointID] = &endpoint{ip: ip, ipv6: ipv6, mac: mac}
+	if n.isDefault {
+		currentInterfaces.Set(endpointID, &networkInterface{
+			IP: ip,
+		})
+	}
+	return ep, nil
+}
+
+// DeleteEndpoint releases the address of the interface endpointID, and its
+// port mappings on the network of the daemon.
+func (d *driver) DeleteEndpoint(networkID, endpointID string) error {
+	d.Lock()
+	defer d.Unlock()
+
+	n, err := d.get(networkID)
+	if err != nil {
+		return err
+	}
+	ep, exists := n.endpoints[endpointID]
+	if !exists {
+		return fmt.Errorf("No network information to release for %s", endpointID)
+	}
+
+	if n.isDefault {
+		if iface := currentInterfaces.Get(endpointID); iface != nil {
+			for _, nat := range iface.PortMappings {
+				if err := portmapper.Unmap(nat); err != nil {
+					log.Infof("Unable to unmap port %s: %s", nat, err)
+				}
+			}
+			currentInterfaces.Delete(endpointID)
+		}
+	}
+
+	if err := ipallocator.ReleaseIP(n.addr, ep.ip); err != nil {
+		log.Infof("Unable to release ip %s", err)
+	}
+	if ep.ipv6 != nil {
+		if err := ipallocator.ReleaseIP(globalIPv6Network, ep.ipv6); err != nil {
+			log.Infof("Unable to release ipv6 %s", err)
+		}
+	}
+	delete(n.endpoints, endpointID)
+	return nil
+}
+
+// Join adds a veth pair to the bridge of the network, with its peer in the
+// network namespace of the process pid.
+func (d *driver) Join(networkID, endpointID string, pid int) (string, error) {
+	d.Lock()
+	defer d.Unlock()
+
+	n, err := d.get(networkID)
+	if err != nil {
+		return "", err
+	}
+	ep, exists := n.endpoints[endpointID]
+	if !exists {
+		return "", fmt.Errorf("No such endpoint: %s", endpointID)
+	}
+	ifName, hostIf, err := plugEndpoint(n.bridge, pid, &net.IPNet{IP: ep.ip, Mask: n.addr.Mask}, ep.mac)
+	if err != nil {
+		return "", err
+	}
+	ep.hostInterface = hostIf
+	return ifName, nil
+}
+
+// Leave deletes the veth pair added by Join.
+func (d *driver) Leave(networkID, endpointID string) error {
+	d.Lock()
+	defer d.Unlock()
+
+	n, err := d.get(networkID)
+	if err != nil {
+		return err
+	}
+	ep, exists := n.endpoints[endpointID]
+	if !exists || ep.hostInterface == "" {
+		return nil
+	}
+	if err := netlink.NetworkLinkDel(ep.hostInterface); err != nil {
+		// The interface is gone along with the network namespace of a
+		// stopped container
+		log.Debugf("Unable to delete interface %s: %s", ep.hostInterface, err)
+	}
+	ep.hostInterface = ""
+	return nil
+}
+
+// get returns the network id. It must be called with the driver locked.
+func (d *driver) get(id string) (*bridgeNet, error) {
+	n, exists := d.networks[id]
+	if !exists {
+		return nil, fmt.Errorf("No such network: %s", id)
+	}
+	return n, nil
+}
+
+// setupNetworkBridge creates the bridge of the network n if needed, and sets
+// up its iptables rules.
+func (d *driver) setupNetworkBridge(n *bridgeNet) error {
+	if _, err := net.InterfaceByName(n.bridge); err != nil {
+		if err := createBridgeIface(n.bridge); err != nil && !os.IsExist(err) {
+			return err
+		}
+		iface, err := net.InterfaceByName(n.bridge)
+		if err != nil {
+			return err
+		}
+		if err := netlink.NetworkLinkAddIp(iface, n.addr.IP, n.addr); err != nil {
+			return fmt.Errorf("Unable to add private network: %s", err)
+		}
+		if err := netlink.NetworkLinkUp(iface); err != nil {
+			return fmt.Errorf("Unable to start network bridge: %s", err)
+		}
+	}
+	if !enableIPTables {
+		return nil
+	}
+	if err := setupNetworkIPTables(n, "-I"); err != nil {
+		return err
+	}
+	// Isolate the network from all the others, the default one included
+	for _, other := range d.networks {
+		if other == n {
+			continue
+		}
+		if err := isolateBridges(n.bridge, other.bridge, "-I"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeNetworkBridge deletes the iptables rules and the bridge of the
+// network n.
+func (d *driver) removeNetworkBridge(n *bridgeNet) {
+	if enableIPTables {
+		if err := setupNetworkIPTables(n, "-D"); err != nil {
+			log.Errorf("Error removing the iptables rules of bridge %s: %s", n.bridge, err)
+		}
+		for _, other := range d.networks {
+			if other == n {
+				continue
+			}
+			if err := isolateBridges(n.bridge, other.bridge, "-D"); err != nil {
+				log.Errorf("Error removing the iptables rules of bridge %s: %s", n.bridge, err)
+			}
+		}
+	}
+	if err := netlink.DeleteBridge(n.bridge); err != nil {
+		log.Debugf("Unable to delete bridge %s: %s", n.bridge, err)
+	}
+}
+
+// setupNetworkIPTables inserts (action -I) or deletes (action -D) the
+// forwarding and masquerading rules of the network n, which follow the ones
+// of the bridge of the daemon.
+func setupNetworkIPTables(n *bridgeNet, action string) error {
+	icc := "DROP"
+	if interContainerCommunication {
+		icc = "ACCEPT"
+	}
+	subnet := &net.IPNet{IP: n.addr.IP.Mask(n.addr.Mask), Mask: n.addr.Mask}
+	rules := [][]string{
+		{"FORWARD", "-i", n.bridge, "-o", n.bridge, "-j", icc},
+		{"FORWARD", "-i", n.bridge, "!", "-o", n.bridge, "-j", "ACCEPT"},
+		{"FORWARD", "-o", n.bridge, "-m", "conntrack", "--ctstate", "RELATED,ESTABLISHED", "-j", "ACCEPT"},
+	}
+	if ipMasq {
+		rules = append(rules, []string{"POSTROUTING", "-t", "nat", "-s", subnet.String(), "!", "-o", n.bridge, "-j", "MASQUERADE"})
+	}
+	for _, rule := range rules {
+		if err := toggleRule(action, rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isolateBridges inserts (action -I) or deletes (action -D) the rules
+// dropping the traffic between the bridges a and b.
+func isolateBridges(a, b, action string) error {
+	for _, rule := range [][]string{
+		{"FORWARD", "-i", a, "-o", b, "-j", "DROP"},
+		{"FORWARD", "-i", b, "-o", a, "-j", "DROP"},
+	} {
+		if err := toggleRule(action, rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toggleRule(action string, rule []string) error {
+	exists := iptables.Exists(rule...)
+	if (action == "-I" && exists) || (action == "-D" && !exists) {
+		return nil
+	}
+	if output, err := iptables.Raw(append([]string{action}, rule...)...); err != nil {
+		return fmt.Errorf("Unable to set up network isolation: %s", err)
+	} else if len(output) != 0 {
+		return &iptables.ChainError{Chain: rule[0], Output: output}
+	}
+	return nil
+}
+
+// findNetworkSubnet returns a subnet which overlaps neither the routes of
+// the host, its nameservers, nor the other networks.
+func (d *driver) findNetworkSubnet() (*net.IPNet, error) {
+	nameservers := []string{}
+	if resolvConf, _ := resolvconf.Get(); resolvConf != nil {
+		nameservers = append(nameservers, resolvconf.GetNameserversAsCIDR(resolvConf)...)
+	}
+	for _, addr := range addrs {
+		_, subnet, err := net.ParseCIDR(addr)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.checkNetworkOverlaps(subnet); err != nil {
+			continue
+		}
+		if err := networkdriver.CheckNameserverOverlaps(nameservers, subnet); err != nil {
+			continue
+		}
+		if err := networkdriver.CheckRouteOverlaps(subnet); err != nil {
+			continue
+		}
+		return subnet, nil
+	}
+	return nil, fmt.Errorf("Could not find a free subnet for the network. Please choose one with --subnet")
+}
+
+func (d *driver) checkNetworkOverlaps(subnet *net.IPNet) error {
+	for _, n := range d.networks {
+		if networkdriver.NetworkOverlaps(subnet, n.addr) {
+			return fmt.Errorf("Subnet %s overlaps with bridge %s", subnet, n.bridge)
+		}
+	}
+	return nil
+}
+
+// firstHost returns the first address of subnet after the network address,
+// used as the gateway of the network.
+func firstHost(subnet *net.IPNet) net.IP {
+	ip := make(net.IP, len(subnet.IP))
+	copy(ip, subnet.IP.Mask(subnet.Mask))
+	for i := len(ip) - 1; i >= 0; i-- {
+		ip[i]++
+		if ip[i] != 0 {
+			break
+		}
+	}
+	return ip
+}
@@ -0,0 +1,99 @@
+package bridge
+
+import (
+	"net"
+	"os/exec"
+	"testing"
+
+	"github.com/docker/docker/daemon/networkdriver"
+	"github.com/docker/docker/engine"
+)
+
+func initNetworksDriver(t *testing.T) networkdriver.Driver {
+	d, err := Init(&engine.Env{})
+	if err != nil {
+		t.Fatalf("Failed to initialize network driver: %s", err)
+	}
+	if err := d.CreateNetwork("default_id", map[string]string{networkdriver.DefaultOption: "true"}); err != nil {
+		t.Fatalf("Failed to set up the network of the daemon: %s", err)
+	}
+	return d
+}
+
+func TestNetworks(t *testing.T) {
+	d := initNetworksDriver(t)
+
+	options := map[string]string{"Subnet": "10.201.0.0/24"}
+	if err := d.CreateNetwork("0123456789abcdef", options); err != nil {
+		t.Fatal(err)
+	}
+	defer d.DeleteNetwork("0123456789abcdef")
+	if options["Gateway"] != "10.201.0.1" || options["Bridge"] != "br-0123456789ab" {
+		t.Fatalf("Unexpected gateway %s and bridge %s", options["Gateway"], options["Bridge"])
+	}
+	if _, err := net.InterfaceByName(options["Bridge"]); err != nil {
+		t.Fatalf("Expected bridge %s to exist: %s", options["Bridge"], err)
+	}
+
+	if err := d.CreateNetwork("fedcba9876543210", map[string]string{"Subnet": "10.201.0.128/25"}); err == nil {
+		t.Fatal("Expected an error creating a network overlapping another one")
+	}
+
+	// Connect the process of a fake container with its own network namespace
+	cmd := exec.Command("unshare", "-n", "sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Process.Kill()
+
+	ep, err := d.CreateEndpoint("0123456789abcdef", "container_id", map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ep.IPAddress.String() != "10.201.0.2" || ep.IPPrefixLen != 24 {
+		t.Fatalf("Unexpected address %s/%d", ep.IPAddress, ep.IPPrefixLen)
+	}
+	ifName, err := d.Join("0123456789abcdef", "container_id", cmd.Process.Pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ifName != "eth1" {
+		t.Fatalf("Unexpected interface %s", ifName)
+	}
+	if err := inNetNS(cmd.Process.Pid, func() error {
+		_, err := net.InterfaceByName("eth1")
+		return err
+	}); err != nil {
+		t.Fatalf("Expected the interface in the namespace of the container: %s", err)
+	}
+
+	if err := d.Leave("0123456789abcdef", "container_id"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.DeleteEndpoint("0123456789abcdef", "container_id"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Networks are set up again with their options when the daemon restarts
+	d = initNetworksDriver(t)
+	restored := map[string]string{}
+	for k, v := range options {
+		restored[k] = v
+	}
+	if err := d.CreateNetwork("0123456789abcdef", restored); err != nil {
+		t.Fatal(err)
+	}
+	if restored["Bridge"] != options["Bridge"] || restored["Subnet"] != options["Subnet"] {
+		t.Fatalf("Expected network on %s with subnet %s, got %s with %s", options["Bridge"], options["Subnet"], restored["Bridge"], restored["Subnet"])
+	}
+
+	if err := d.DeleteNetwork("0123456789abcdef"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := net.InterfaceByName(options["Bridge"]); err == nil {
+		t.Fatalf("Expected bridge %s to be removed", options["Bridge"])
+	}
+	if err := d.DeleteNetwork("default_id"); err == nil {
+		t.Fatal("Expected an error removing the network of the daemon")
+	}
+}
@@ -0,0 +1,93 @@
+package networkdriver
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/docker/docker/engine"
+)
+
+// DefaultOption is set in the options of the networks which containers are
+// attached to with --net. The driver sets them up from the configuration
+// of the daemon rather than from the options.
+const DefaultOption = "Default"
+
+// InitFunc initializes a network driver with the configuration of the
+// daemon, e.g. `BridgeIface` or `EnableIptables`.
+type InitFunc func(config *engine.Env) (Driver, error)
+
+// Driver is the interface for network drivers, which provide the networks
+// containers are connected to.
+type Driver interface {
+	// String returns the name of the driver.
+	String() string
+	// CreateNetwork sets up the network id. The driver completes the
+	// options, such as the Subnet of a bridge network, with the settings
+	// it chose. They are stored along with the network, and CreateNetwork
+	// is called again with them each time the daemon starts.
+	CreateNetwork(id string, options map[string]string) error
+	// DeleteNetwork tears down the network id.
+	DeleteNetwork(id string) error
+	// CreateEndpoint allocates the interface endpointID of a container
+	// on the network. The RequestedIP, RequestedIPv6 and RequestedMac
+	// options ask for specific addresses.
+	CreateEndpoint(networkID, endpointID string, options map[string]string) (*Endpoint, error)
+	// DeleteEndpoint releases the interface endpointID.
+	DeleteEndpoint(networkID, endpointID string) error
+	// Join adds the interface endpointID to the network namespace of the
+	// process pid, and returns its name in the namespace.
+	Join(networkID, endpointID string, pid int) (string, error)
+	// Leave removes the interface endpointID from the network namespace
+	// it was added to by Join.
+	Leave(networkID, endpointID string) error
+}
+
+// Installer is implemented by the drivers which provide engine jobs of
+// their own, such as the port mappings of the bridge driver.
+type Installer interface {
+	Install(eng *engine.Engine) error
+}
+
+// Endpoint describes the interface of a container on a network. It has no
+// address on the networks of the null and host drivers.
+type Endpoint struct {
+	IPAddress   net.IP
+	IPPrefixLen int
+	Gateway     net.IP
+	MacAddress  net.HardwareAddr
+	// The global IPv6 address of the interface, if the network has an IPv6
+	// subnet
+	GlobalIPv6Address   net.IP
+	GlobalIPv6PrefixLen int
+	IPv6Gateway         net.IP
+	// Bridge is the bridge the interface is attached to, if any
+	Bridge string
+}
+
+var (
+	// All registered drivers
+	drivers = make(map[string]InitFunc)
+
+	ErrDriverNotSupported = errors.New("network driver not supported")
+)
+
+// Register makes the network driver initialized by initFunc available as
+// name.
+func Register(name string, initFunc InitFunc) error {
+	if _, exists := drivers[name]; exists {
+		return fmt.Errorf("Name already registered %s", name)
+	}
+	drivers[name] = initFunc
+
+	return nil
+}
+
+// GetDriver initializes the network driver name with the configuration of
+// the daemon.
+func GetDriver(name string, config *engine.Env) (Driver, error) {
+	if initFunc, exists := drivers[name]; exists {
+		return initFunc(config)
+	}
+	return nil, ErrDriverNotSupported
+}
@@ -0,0 +1,53 @@
+// Package host implements the network driver of the containers sharing the
+// network stack of the host, the ones run with --net=host. There is a
+// single host network, and the endpoints on it have no address of their
+// own.
+package host
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/daemon/networkdriver"
+	"github.com/docker/docker/engine"
+)
+
+type driver struct{}
+
+func init() {
+	networkdriver.Register("host", Init)
+}
+
+func Init(config *engine.Env) (networkdriver.Driver, error) {
+	return &driver{}, nil
+}
+
+func (d *driver) String() string {
+	return "host"
+}
+
+func (d *driver) CreateNetwork(id string, options map[string]string) error {
+	if options[networkdriver.DefaultOption] != "true" {
+		return fmt.Errorf("Only one host network can exist")
+	}
+	return nil
+}
+
+func (d *driver) DeleteNetwork(id string) error {
+	return fmt.Errorf("The host network cannot be removed")
+}
+
+func (d *driver) CreateEndpoint(networkID, endpointID string, options map[string]string) (*networkdriver.Endpoint, error) {
+	return &networkdriver.Endpoint{}, nil
+}
+
+func (d *driver) DeleteEndpoint(networkID, endpointID string) error {
+	return nil
+}
+
+func (d *driver) Join(networkID, endpointID string, pid int) (string, error) {
+	return "", fmt.Errorf("Containers can only use the host network with --net=host")
+}
+
+func (d *driver) Leave(networkID, endpointID string) error {
+	return nil
+}
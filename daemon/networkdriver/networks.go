@@ -0,0 +1,588 @@
+package networkdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/engine"
+	"github.com/docker/docker/utils"
+)
+
+// DefaultNetworkName is the network of the containers run with the default
+// network mode, --net=bridge.
+const DefaultNetworkName = "bridge"
+
+var (
+	validNetworkName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+	// The networks of the network modes of containers, along with their
+	// drivers. They are created by the daemon and cannot be managed with
+	// `docker network`.
+	defaultNetworks = []struct{ name, driver string }{
+		{DefaultNetworkName, "bridge"},
+		{"host", "host"},
+		{"none", "null"},
+	}
+)
+
+// network is a network provided by a driver, which containers can join in
+// addition to the network of their network mode.
+type network struct {
+	ID      string
+	Name    string
+	Driver  string
+	Options map[string]string
+	// Containers lists the containers connected to the network with
+	// `docker network connect`
+	Containers []string
+
+	driver Driver
+	// endpoints holds the endpoints of the containers on the network, and
+	// whether they joined the network namespace of their process
+	endpoints map[string]bool
+}
+
+func (n *network) isDefault() bool {
+	return n.Options[DefaultOption] == "true"
+}
+
+func (n *network) hasContainer(id string) bool {
+	for _, c := range n.Containers {
+		if c == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *network) env() *engine.Env {
+	containers := append([]string{}, n.Containers...)
+	sort.Strings(containers)
+	out := &engine.Env{}
+	// Names such as null or 123 would be encoded as other json values
+	out.SetJson("Name", n.Name)
+	out.Set("Id", n.ID)
+	out.SetJson("Driver", n.Driver)
+	out.SetJson("Options", n.Options)
+	out.SetList("Containers", containers)
+	return out
+}
+
+// controller keeps the networks in memory and on disk, one json file per
+// network, and the drivers providing them.
+type controller struct {
+	path     string
+	eng      *engine.Engine
+	config   *engine.Env
+	drivers  map[string]Driver
+	networks map[string]*network
+	sync.Mutex
+}
+
+var networks *controller
+
+func newController(path string, eng *engine.Engine, config *engine.Env) (*controller, error) {
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, err
+	}
+	c := &controller{
+		path:     path,
+		eng:      eng,
+		config:   config,
+		drivers:  make(map[string]Driver),
+		networks: make(map[string]*network),
+	}
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(path, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		n := &network{}
+		if err := json.Unmarshal(data, n); err != nil {
+			log.Errorf("Unable to load network %s: %s", f.Name(), err)
+			continue
+		}
+		if n.Options == nil {
+			n.Options = make(map[string]string)
+		}
+		n.endpoints = make(map[string]bool)
+		c.networks[n.ID] = n
+	}
+	return c, nil
+}
+
+func (c *controller) save(n *network) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(c.path, n.ID+".json"), data, 0600)
+}
+
+// getDriver returns the driver name, initializing it the first time it is
+// used.
+func (c *controller) getDriver(name string) (Driver, error) {
+	if d, exists := c.drivers[name]; exists {
+		return d, nil
+	}
+	d, err := GetDriver(name, c.config)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to initialize network driver %s: %s", name, err)
+	}
+	if installer, ok := d.(Installer); ok {
+		if err := installer.Install(c.eng); err != nil {
+			return nil, err
+		}
+	}
+	c.drivers[name] = d
+	return d, nil
+}
+
+// get returns the network called name, or whose ID is or starts with name.
+// It must be called with the controller locked.
+func (c *controller) get(name string) (*network, error) {
+	for _, n := range c.networks {
+		if n.Name == name {
+			return n, nil
+		}
+	}
+	if n, exists := c.networks[name]; exists {
+		return n, nil
+	}
+	var found *network
+	for id, n := range c.networks {
+		if strings.HasPrefix(id, name) {
+			if found != nil {
+				return nil, fmt.Errorf("Network name %s is ambiguous", name)
+			}
+			found = n
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("No such network: %s", name)
+	}
+	return found, nil
+}
+
+// getUser is like get, but refuses the networks of the network modes.
+func (c *controller) getUser(name string) (*network, error) {
+	n, err := c.get(name)
+	if err != nil {
+		return nil, err
+	}
+	if n.isDefault() {
+		return nil, fmt.Errorf("%s is a pre-defined network and cannot be modified", n.Name)
+	}
+	return n, nil
+}
+
+// setupNetwork sets up the network n with its driver.
+func (c *controller) setupNetwork(n *network) error {
+	d, err := c.getDriver(n.Driver)
+	if err != nil {
+		return err
+	}
+	if err := d.CreateNetwork(n.ID, n.Options); err != nil {
+		return err
+	}
+	n.driver = d
+	return c.save(n)
+}
+
+// restore creates the networks of the network modes if needed, and sets up
+// all the networks with their drivers. The networks whose driver is not
+// available are kept, but cannot be used.
+func (c *controller) restore() error {
+	for _, def := range defaultNetworks {
+		if _, err := c.get(def.name); err == nil {
+			continue
+		}
+		n := &network{
+			ID:        utils.GenerateRandomID(),
+			Name:      def.name,
+			Driver:    def.driver,
+			Options:   map[string]string{DefaultOption: "true"},
+			endpoints: make(map[string]bool),
+		}
+		c.networks[n.ID] = n
+	}
+
+	// The drivers pick the settings of the other networks after the ones
+	// of the daemon
+	for _, n := range c.networks {
+		if n.isDefault() {
+			if err := c.setupNetwork(n); err != nil {
+				return fmt.Errorf("Unable to set up network %s: %s", n.Name, err)
+			}
+		}
+	}
+	for _, n := range c.networks {
+		if !n.isDefault() {
+			if err := c.setupNetwork(n); err != nil {
+				log.Errorf("Unable to set up network %s: %s", n.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// releaseEndpoint removes the endpoint of the container id from the network
+// n. It must be called with the controller locked.
+func (c *controller) releaseEndpoint(n *network, id string) error {
+	joined, exists := n.endpoints[id]
+	if !exists {
+		return nil
+	}
+	if joined {
+		if err := n.driver.Leave(n.ID, id); err != nil {
+			log.Errorf("Unable to remove container %s from network %s: %s", id, n.Name, err)
+		}
+	}
+	if err := n.driver.DeleteEndpoint(n.ID, id); err != nil {
+		return err
+	}
+	delete(n.endpoints, id)
+	return nil
+}
+
+// InitDriver sets up the networks stored at `NetworksPath`, initializing
+// their drivers with the configuration of the daemon, and registers the
+// jobs managing them.
+func InitDriver(job *engine.Job) engine.Status {
+	path := job.Getenv("NetworksPath")
+	if path == "" {
+		return job.Errorf("NetworksPath is required")
+	}
+	c, err := newController(path, job.Eng, job.Env())
+	if err != nil {
+		return job.Error(err)
+	}
+	if err := c.restore(); err != nil {
+		return job.Error(err)
+	}
+	networks = c
+
+	for name, f := range map[string]engine.Handler{
+		"network_create":  CreateNetwork,
+		"network_inspect": InspectNetwork,
+		"network_rm":      RemoveNetwork,
+		"networks":        ListNetworks,
+		"network_join":    JoinNetwork,
+		"network_leave":   LeaveNetwork,
+		"endpoint_create": CreateEndpoint,
+		"endpoint_delete": DeleteEndpoint,
+	} {
+		if err := job.Eng.Register(name, f); err != nil {
+			return job.Error(err)
+		}
+	}
+	return engine.StatusOK
+}
+
+// CreateNetwork creates a network called NAME and prints its ID. The network
+// is provided by the driver given with the `Driver` environment variable,
+// bridge by default, with the `Options` json object.
+//
+// Syntax: network_create NAME
+func CreateNetwork(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("Usage: %s NAME", job.Name)
+	}
+	name := job.Args[0]
+	if !validNetworkName.MatchString(name) {
+		return job.Errorf("Invalid network name %q, only [a-zA-Z0-9][a-zA-Z0-9_.-] are allowed", name)
+	}
+	for _, def := range defaultNetworks {
+		if name == def.name {
+			return job.Errorf("Conflict: network name %s is reserved", name)
+		}
+	}
+	driverName := job.Getenv("Driver")
+	if driverName == "" {
+		driverName = "bridge"
+	}
+	var options map[string]string
+	if err := job.GetenvJson("Options", &options); err != nil {
+		return job.Errorf("Bad parameter: invalid options: %s", err)
+	}
+	if options == nil {
+		options = make(map[string]string)
+	}
+	delete(options, DefaultOption)
+
+	networks.Lock()
+	defer networks.Unlock()
+
+	for _, n := range networks.networks {
+		if n.Name == name {
+			return job.Errorf("Conflict: network name %s is already in use", name)
+		}
+	}
+
+	n := &network{
+		ID:        utils.GenerateRandomID(),
+		Name:      name,
+		Driver:    driverName,
+		Options:   options,
+		endpoints: make(map[string]bool),
+	}
+	if err := networks.setupNetwork(n); err != nil {
+		if n.driver != nil {
+			n.driver.DeleteNetwork(n.ID)
+		}
+		return job.Error(err)
+	}
+	networks.networks[n.ID] = n
+
+	job.Printf("%s\n", n.ID)
+	return engine.StatusOK
+}
+
+// RemoveNetwork removes the network called or with the ID NAME. Networks
+// with connected containers cannot be removed.
+//
+// Syntax: network_rm NAME
+func RemoveNetwork(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("Usage: %s NAME", job.Name)
+	}
+
+	networks.Lock()
+	defer networks.Unlock()
+
+	n, err := networks.getUser(job.Args[0])
+	if err != nil {
+		return job.Error(err)
+	}
+	if len(n.Containers) > 0 {
+		containers := append([]string{}, n.Containers...)
+		sort.Strings(containers)
+		return job.Errorf("Conflict: network %s has connected containers %s", n.Name, strings.Join(containers, ", "))
+	}
+	if n.driver != nil {
+		if err := n.driver.DeleteNetwork(n.ID); err != nil {
+			return job.Error(err)
+		}
+	}
+	if err := os.Remove(filepath.Join(networks.path, n.ID+".json")); err != nil && !os.IsNotExist(err) {
+		return job.Error(err)
+	}
+	delete(networks.networks, n.ID)
+	return engine.StatusOK
+}
+
+// InspectNetwork writes the description of the network called or with the
+// ID NAME to stdout as json.
+//
+// Syntax: network_inspect NAME
+func InspectNetwork(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("Usage: %s NAME", job.Name)
+	}
+
+	networks.Lock()
+	defer networks.Unlock()
+
+	n, err := networks.get(job.Args[0])
+	if err != nil {
+		return job.Error(err)
+	}
+	if _, err := n.env().WriteTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// ListNetworks writes the list of networks to stdout.
+//
+// Syntax: networks
+func ListNetworks(job *engine.Job) engine.Status {
+	networks.Lock()
+	defer networks.Unlock()
+
+	outs := engine.NewTable("Name", 0)
+	for _, n := range networks.networks {
+		outs.Add(n.env())
+	}
+	outs.Sort()
+	if _, err := outs.WriteListTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// JoinNetwork makes the container ID a member of the network called or with
+// the ID NAME. The container gets an interface on the network with
+// endpoint_create.
+//
+// Syntax: network_join NAME ID
+func JoinNetwork(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s NAME ID", job.Name)
+	}
+	id := job.Args[1]
+
+	networks.Lock()
+	defer networks.Unlock()
+
+	n, err := networks.getUser(job.Args[0])
+	if err != nil {
+		return job.Error(err)
+	}
+	if n.hasContainer(id) {
+		return engine.StatusOK
+	}
+	n.Containers = append(n.Containers, id)
+	if err := networks.save(n); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// LeaveNetwork removes the container ID from the members of the network
+// called or with the ID NAME, along with its endpoint on the network.
+//
+// Syntax: network_leave NAME ID
+func LeaveNetwork(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s NAME ID", job.Name)
+	}
+	id := job.Args[1]
+
+	networks.Lock()
+	defer networks.Unlock()
+
+	n, err := networks.getUser(job.Args[0])
+	if err != nil {
+		return job.Error(err)
+	}
+	if !n.hasContainer(id) {
+		return job.Errorf("Container %s is not connected to network %s", id, n.Name)
+	}
+	if err := networks.releaseEndpoint(n, id); err != nil {
+		return job.Error(err)
+	}
+	for i, c := range n.Containers {
+		if c == id {
+			n.Containers = append(n.Containers[:i], n.Containers[i+1:]...)
+			break
+		}
+	}
+	if err := networks.save(n); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// CreateEndpoint allocates the endpoint of the container ID on the network
+// called or with the ID NAME, with the addresses given with the
+// `RequestedIP`, `RequestedIPv6` and `RequestedMac` environment variables if
+// any. If `Pid` is set, the endpoint joins the network namespace of the
+// process of the container. The settings of the endpoint are written to
+// stdout.
+//
+// Syntax: endpoint_create NAME ID
+func CreateEndpoint(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s NAME ID", job.Name)
+	}
+	id := job.Args[1]
+
+	networks.Lock()
+	defer networks.Unlock()
+
+	n, err := networks.get(job.Args[0])
+	if err != nil {
+		return job.Error(err)
+	}
+	if n.driver == nil {
+		return job.Errorf("Network %s is not available, its driver %s failed to set it up", n.Name, n.Driver)
+	}
+	if !n.isDefault() && !n.hasContainer(id) {
+		return job.Errorf("Container %s is not connected to network %s", id, n.Name)
+	}
+	if _, exists := n.endpoints[id]; exists {
+		return job.Errorf("Conflict: container %s already has an endpoint on network %s", id, n.Name)
+	}
+
+	ep, err := n.driver.CreateEndpoint(n.ID, id, map[string]string{
+		"RequestedIP":   job.Getenv("RequestedIP"),
+		"RequestedIPv6": job.Getenv("RequestedIPv6"),
+		"RequestedMac":  job.Getenv("RequestedMac"),
+	})
+	if err != nil {
+		return job.Error(err)
+	}
+	var ifName string
+	pid := job.GetenvInt("Pid")
+	if pid != 0 {
+		if ifName, err = n.driver.Join(n.ID, id, pid); err != nil {
+			n.driver.DeleteEndpoint(n.ID, id)
+			return job.Errorf("Unable to connect container %s to network %s: %s", id, n.Name, err)
+		}
+	}
+	n.endpoints[id] = pid != 0
+
+	out := &engine.Env{}
+	out.Set("NetworkID", n.ID)
+	out.Set("Interface", ifName)
+	out.Set("Bridge", ep.Bridge)
+	if ep.IPAddress != nil {
+		out.Set("IPAddress", ep.IPAddress.String())
+		out.SetInt("IPPrefixLen", ep.IPPrefixLen)
+	}
+	if ep.Gateway != nil {
+		out.Set("Gateway", ep.Gateway.String())
+	}
+	if ep.MacAddress != nil {
+		out.Set("MacAddress", ep.MacAddress.String())
+	}
+	if ep.GlobalIPv6Address != nil {
+		out.Set("GlobalIPv6Address", ep.GlobalIPv6Address.String())
+		out.SetInt("GlobalIPv6PrefixLen", ep.GlobalIPv6PrefixLen)
+	}
+	if ep.IPv6Gateway != nil {
+		out.Set("IPv6Gateway", ep.IPv6Gateway.String())
+	}
+	if _, err := out.WriteTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// DeleteEndpoint releases the endpoint of the container ID on the network
+// called or with the ID NAME, if it has one.
+//
+// Syntax: endpoint_delete NAME ID
+func DeleteEndpoint(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s NAME ID", job.Name)
+	}
+
+	networks.Lock()
+	defer networks.Unlock()
+
+	n, err := networks.get(job.Args[0])
+	if err != nil {
+		return job.Error(err)
+	}
+	if err := networks.releaseEndpoint(n, job.Args[1]); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
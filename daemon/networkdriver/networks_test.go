@@ -0,0 +1,198 @@
+package networkdriver
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/docker/docker/engine"
+)
+
+// fakeDriver records the calls of the controller.
+type fakeDriver struct {
+	networks  map[string]map[string]string
+	endpoints map[string]bool
+}
+
+func (d *fakeDriver) String() string {
+	return "fake"
+}
+
+func (d *fakeDriver) CreateNetwork(id string, options map[string]string) error {
+	if options["Fail"] != "" {
+		return fmt.Errorf("invalid options")
+	}
+	options["Chosen"] = "by the driver"
+	d.networks[id] = options
+	return nil
+}
+
+func (d *fakeDriver) DeleteNetwork(id string) error {
+	delete(d.networks, id)
+	return nil
+}
+
+func (d *fakeDriver) CreateEndpoint(networkID, endpointID string, options map[string]string) (*Endpoint, error) {
+	d.endpoints[endpointID] = false
+	return &Endpoint{
+		IPAddress:   net.ParseIP("10.0.0.2"),
+		IPPrefixLen: 24,
+		Gateway:     net.ParseIP("10.0.0.1"),
+	}, nil
+}
+
+func (d *fakeDriver) DeleteEndpoint(networkID, endpointID string) error {
+	delete(d.endpoints, endpointID)
+	return nil
+}
+
+func (d *fakeDriver) Join(networkID, endpointID string, pid int) (string, error) {
+	d.endpoints[endpointID] = true
+	return "eth1", nil
+}
+
+func (d *fakeDriver) Leave(networkID, endpointID string) error {
+	d.endpoints[endpointID] = false
+	return nil
+}
+
+func initFakeDrivers(t *testing.T, root string) (*engine.Engine, *fakeDriver) {
+	d := &fakeDriver{
+		networks:  make(map[string]map[string]string),
+		endpoints: make(map[string]bool),
+	}
+	drivers = make(map[string]InitFunc)
+	for _, name := range []string{"bridge", "host", "null"} {
+		Register(name, func(config *engine.Env) (Driver, error) { return d, nil })
+	}
+
+	eng := engine.New()
+	eng.Logging = false
+	job := eng.Job("init_networkdriver")
+	job.Setenv("NetworksPath", root)
+	if res := InitDriver(job); res != engine.StatusOK {
+		t.Fatal("Failed to initialize network drivers")
+	}
+	return eng, d
+}
+
+func runNetworkJob(t *testing.T, eng *engine.Engine, name string, env map[string]string, args ...string) *engine.Env {
+	job := eng.Job(name, args...)
+	for k, v := range env {
+		job.Setenv(k, v)
+	}
+	out, err := job.Stdout.AddEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := job.Run(); err != nil {
+		t.Fatalf("%s %v: %s", name, args, err)
+	}
+	return out
+}
+
+func TestNetworkController(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-networks-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	eng, d := initFakeDrivers(t, root)
+	if len(d.networks) != 3 {
+		t.Fatalf("Expected the networks of the network modes to be set up, got %v", d.networks)
+	}
+
+	for _, name := range []string{DefaultNetworkName, "none", "-invalid"} {
+		if err := eng.Job("network_create", name).Run(); err == nil {
+			t.Fatalf("Expected an error creating network %q", name)
+		}
+	}
+	job := eng.Job("network_create", "testnet")
+	job.Setenv("Driver", "unknown")
+	if err := job.Run(); err == nil {
+		t.Fatal("Expected an error creating a network with an unknown driver")
+	}
+	job = eng.Job("network_create", "testnet")
+	job.SetenvJson("Options", map[string]string{"Fail": "true"})
+	if err := job.Run(); err == nil {
+		t.Fatal("Expected an error creating a network refused by the driver")
+	}
+	job = eng.Job("network_create", "testnet")
+	job.SetenvJson("Options", map[string]string{"Subnet": "10.0.0.0/24"})
+	if err := job.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	n := runNetworkJob(t, eng, "network_inspect", nil, "testnet")
+	options := map[string]string{}
+	if err := n.GetJson("Options", &options); err != nil {
+		t.Fatal(err)
+	}
+	if n.Get("Driver") != "bridge" || options["Subnet"] != "10.0.0.0/24" || options["Chosen"] != "by the driver" {
+		t.Fatalf("Unexpected driver %s and options %v", n.Get("Driver"), options)
+	}
+
+	if err := eng.Job("endpoint_create", "testnet", "container_id").Run(); err == nil {
+		t.Fatal("Expected an error creating an endpoint for a container not connected to the network")
+	}
+	if err := eng.Job("network_join", DefaultNetworkName, "container_id").Run(); err == nil {
+		t.Fatal("Expected an error joining the default network")
+	}
+	if err := eng.Job("network_join", "testnet", "container_id").Run(); err != nil {
+		t.Fatal(err)
+	}
+	ep := runNetworkJob(t, eng, "endpoint_create", map[string]string{"Pid": "1"}, "testnet", "container_id")
+	if ep.Get("IPAddress") != "10.0.0.2" || ep.Get("Interface") != "eth1" || ep.Get("NetworkID") != n.Get("Id") {
+		t.Fatalf("Unexpected address %s on interface %s", ep.Get("IPAddress"), ep.Get("Interface"))
+	}
+	if !d.endpoints["container_id"] {
+		t.Fatal("Expected the endpoint to join the namespace of the container")
+	}
+	if err := eng.Job("endpoint_create", "testnet", "container_id").Run(); err == nil {
+		t.Fatal("Expected an error creating an endpoint twice")
+	}
+
+	if err := eng.Job("network_rm", "testnet").Run(); err == nil {
+		t.Fatal("Expected an error removing a network with connected containers")
+	}
+	if err := eng.Job("network_leave", "testnet", "container_id").Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := d.endpoints["container_id"]; exists {
+		t.Fatal("Expected the endpoint to be deleted when the container leaves the network")
+	}
+	if err := eng.Job("network_leave", "testnet", "container_id").Run(); err == nil {
+		t.Fatal("Expected an error leaving a network twice")
+	}
+
+	// Containers get endpoints on the default network without joining it
+	runNetworkJob(t, eng, "endpoint_create", nil, DefaultNetworkName, "container_id")
+	for i := 0; i < 2; i++ {
+		if err := eng.Job("endpoint_delete", DefaultNetworkName, "container_id").Run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Networks survive a restart of the daemon
+	eng, d = initFakeDrivers(t, root)
+	if len(d.networks) != 4 || d.networks[n.Get("Id")]["Subnet"] != "10.0.0.0/24" {
+		t.Fatalf("Expected the networks to be set up again, got %v", d.networks)
+	}
+	restored := runNetworkJob(t, eng, "network_inspect", nil, n.Get("Id")[:12])
+	if restored.Get("Name") != "testnet" {
+		t.Fatalf("Expected restored network testnet, got %s", restored.Get("Name"))
+	}
+
+	if err := eng.Job("network_rm", "testnet").Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := d.networks[n.Get("Id")]; exists {
+		t.Fatal("Expected the driver to delete the network")
+	}
+	if err := eng.Job("network_rm", DefaultNetworkName).Run(); err == nil {
+		t.Fatal("Expected an error removing the default network")
+	}
+}
@@ -0,0 +1,47 @@
+// Package null implements the network driver of the containers without
+// networking, the ones run with --net=none. Their endpoints have no address
+// nor interface.
+package null
+
+import (
+	"github.com/docker/docker/daemon/networkdriver"
+	"github.com/docker/docker/engine"
+)
+
+type driver struct{}
+
+func init() {
+	networkdriver.Register("null", Init)
+}
+
+func Init(config *engine.Env) (networkdriver.Driver, error) {
+	return &driver{}, nil
+}
+
+func (d *driver) String() string {
+	return "null"
+}
+
+func (d *driver) CreateNetwork(id string, options map[string]string) error {
+	return nil
+}
+
+func (d *driver) DeleteNetwork(id string) error {
+	return nil
+}
+
+func (d *driver) CreateEndpoint(networkID, endpointID string, options map[string]string) (*networkdriver.Endpoint, error) {
+	return &networkdriver.Endpoint{}, nil
+}
+
+func (d *driver) DeleteEndpoint(networkID, endpointID string) error {
+	return nil
+}
+
+func (d *driver) Join(networkID, endpointID string, pid int) (string, error) {
+	return "", nil
+}
+
+func (d *driver) Leave(networkID, endpointID string) error {
+	return nil
+}
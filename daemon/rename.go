@@ -0,0 +1,74 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/engine"
+	"github.com/docker/docker/utils"
+)
+
+// ContainerRename changes the name of a container. Links from other
+// containers keep working as they refer to the container by their alias.
+func (daemon *Daemon) ContainerRename(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s OLD_NAME NEW_NAME", job.Name)
+	}
+	oldName, newName := job.Args[0], job.Args[1]
+	container := daemon.Get(oldName)
+	if container == nil {
+		return job.Errorf("No such container: %s", oldName)
+	}
+	if err := daemon.rename(container, newName); err != nil {
+		return job.Errorf("Cannot rename container %s: %s", oldName, err)
+	}
+	container.LogEvent("rename")
+	return engine.StatusOK
+}
+
+func (daemon *Daemon) rename(container *Container, name string) error {
+	if !validContainerNamePattern.MatchString(name) {
+		return fmt.Errorf("Invalid container name (%s), only %s are allowed", name, validContainerNameChars)
+	}
+	if name[0] != '/' {
+		name = "/" + name
+	}
+
+	container.Lock()
+	defer container.Unlock()
+
+	oldName := container.Name
+	if name == oldName {
+		return fmt.Errorf("The container is already named %s", strings.TrimPrefix(name, "/"))
+	}
+	if daemon.containerGraph.Exists(name) {
+		conflicting, err := daemon.GetByName(name)
+		if err != nil {
+			// The name was left behind by a container which no longer exists
+			if err := daemon.containerGraph.Delete(name); err != nil {
+				return err
+			}
+		} else {
+			nameAsKnownByUser := strings.TrimPrefix(name, "/")
+			return fmt.Errorf(
+				"Conflict, The name %s is already assigned to %s. You have to delete (or rename) that container to be able to assign %s to a container again.", nameAsKnownByUser,
+				utils.TruncateID(conflicting.ID), nameAsKnownByUser)
+		}
+	}
+
+	// The links of the container are edges below its own entity, only the
+	// edge from the root holding its name changes
+	if err := daemon.containerGraph.Rename(oldName, name); err != nil {
+		return err
+	}
+	container.Name = name
+	if err := container.toDisk(); err != nil {
+		container.Name = oldName
+		if err := daemon.containerGraph.Rename(name, oldName); err != nil {
+			log.Errorf("Cannot restore the name %s of container %s: %s", oldName, container.ID, err)
+		}
+		return err
+	}
+	return nil
+}
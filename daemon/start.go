@@ -44,9 +44,15 @@ func (daemon *Daemon) ContainerStart(job *engine.Job) engine.Status {
 }
 
 func (daemon *Daemon) setHostConfig(container *Container, hostConfig *runconfig.HostConfig) error {
+	if err := validateLogConfig(daemon.logConfig(hostConfig.LogConfig)); err != nil {
+		return err
+	}
 	if err := parseSecurityOpt(container, hostConfig); err != nil {
 		return err
 	}
+	if err := validateOomScoreAdj(hostConfig.OomScoreAdj); err != nil {
+		return err
+	}
 	// Validate the HostConfig binds. Make sure that:
 	// the source exists
 	for _, bind := range hostConfig.Binds {
@@ -71,3 +77,10 @@ func (daemon *Daemon) setHostConfig(container *Container, hostConfig *runconfig.
 
 	return nil
 }
+
+func validateOomScoreAdj(score int) error {
+	if score < -1000 || score > 1000 {
+		return fmt.Errorf("Invalid value %d, range for oom score adj is [-1000, 1000].", score)
+	}
+	return nil
+}
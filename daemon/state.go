@@ -20,6 +20,7 @@ type State struct {
 	Error      string // contains last known error when starting the container
 	StartedAt  time.Time
 	FinishedAt time.Time
+	Health     *Health // nil when the container has no healthcheck
 	waitChan   chan struct{}
 }
 
@@ -39,6 +40,9 @@ func (s *State) String() string {
 			return fmt.Sprintf("Restarting (%d) %s ago", s.ExitCode, units.HumanDuration(time.Now().UTC().Sub(s.FinishedAt)))
 		}
 
+		if s.Health != nil {
+			return fmt.Sprintf("Up %s (%s)", units.HumanDuration(time.Now().UTC().Sub(s.StartedAt)), s.Health.Status)
+		}
 		return fmt.Sprintf("Up %s", units.HumanDuration(time.Now().UTC().Sub(s.StartedAt)))
 	}
 
@@ -0,0 +1,172 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/stats"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/engine"
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/system"
+)
+
+// statsInterval is the time between two samples sent to a stats client.
+const statsInterval = time.Second
+
+var clockTicks = uint64(system.GetClockTicks())
+
+// ContainerStats streams a JSON encoded stats.Stats sample of the resource
+// usage of a running container every second until the container stops or
+// the client goes away.
+func (daemon *Daemon) ContainerStats(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("Usage: %s CONTAINER", job.Name)
+	}
+	name := job.Args[0]
+	container := daemon.Get(name)
+	if container == nil {
+		return job.Errorf("No such container: %s", name)
+	}
+	if !container.IsRunning() {
+		return job.Errorf("Container %s is not running", name)
+	}
+
+	enc := json.NewEncoder(job.Stdout)
+	for {
+		s, err := daemon.execDriver.Stats(container.ID)
+		if err != nil {
+			if err == execdriver.ErrNotRunning || !container.IsRunning() {
+				return engine.StatusOK
+			}
+			return job.Error(err)
+		}
+		if s.SystemUsage, err = getSystemCpuUsage(); err != nil {
+			return job.Error(err)
+		}
+		if err := enc.Encode(convertToAPITypes(s)); err != nil {
+			// the client is gone, nothing left to do
+			return engine.StatusOK
+		}
+		// WaitStop doubles as the ticker: it only returns without error
+		// once the container is stopped.
+		if _, err := container.WaitStop(statsInterval); err == nil {
+			return engine.StatusOK
+		}
+	}
+}
+
+// convertToAPITypes converts the libcontainer.ContainerStats to the api
+// specific structs. This is done to preserve API compatibility and
+// versioning.
+func convertToAPITypes(rs *execdriver.ResourceStats) *stats.Stats {
+	s := &stats.Stats{
+		Read: rs.Read,
+	}
+	if rs.ContainerStats == nil {
+		return s
+	}
+	if ns := rs.NetworkStats; ns != nil {
+		s.Network = stats.Network{
+			RxBytes:   ns.RxBytes,
+			RxPackets: ns.RxPackets,
+			RxErrors:  ns.RxErrors,
+			RxDropped: ns.RxDropped,
+			TxBytes:   ns.TxBytes,
+			TxPackets: ns.TxPackets,
+			TxErrors:  ns.TxErrors,
+			TxDropped: ns.TxDropped,
+		}
+	}
+	if cs := rs.CgroupStats; cs != nil {
+		s.BlkioStats = stats.BlkioStats{
+			IoServiceBytesRecursive: copyBlkioEntry(cs.BlkioStats.IoServiceBytesRecursive),
+			IoServicedRecursive:     copyBlkioEntry(cs.BlkioStats.IoServicedRecursive),
+			IoQueuedRecursive:       copyBlkioEntry(cs.BlkioStats.IoQueuedRecursive),
+			IoServiceTimeRecursive:  copyBlkioEntry(cs.BlkioStats.IoServiceTimeRecursive),
+			IoWaitTimeRecursive:     copyBlkioEntry(cs.BlkioStats.IoWaitTimeRecursive),
+			IoMergedRecursive:       copyBlkioEntry(cs.BlkioStats.IoMergedRecursive),
+			IoTimeRecursive:         copyBlkioEntry(cs.BlkioStats.IoTimeRecursive),
+			SectorsRecursive:        copyBlkioEntry(cs.BlkioStats.SectorsRecursive),
+		}
+		cpu := cs.CpuStats
+		s.CpuStats = stats.CpuStats{
+			CpuUsage: stats.CpuUsage{
+				TotalUsage:        cpu.CpuUsage.TotalUsage,
+				PercpuUsage:       cpu.CpuUsage.PercpuUsage,
+				UsageInKernelmode: cpu.CpuUsage.UsageInKernelmode,
+				UsageInUsermode:   cpu.CpuUsage.UsageInUsermode,
+			},
+			SystemUsage: rs.SystemUsage,
+			ThrottlingData: stats.ThrottlingData{
+				Periods:          cpu.ThrottlingData.Periods,
+				ThrottledPeriods: cpu.ThrottlingData.ThrottledPeriods,
+				ThrottledTime:    cpu.ThrottlingData.ThrottledTime,
+			},
+		}
+		mem := cs.MemoryStats
+		s.MemoryStats = stats.MemoryStats{
+			Usage:    mem.Usage,
+			MaxUsage: mem.MaxUsage,
+			Stats:    mem.Stats,
+			Failcnt:  mem.Failcnt,
+			Limit:    uint64(rs.MemoryLimit),
+		}
+	}
+	return s
+}
+
+func copyBlkioEntry(entries []cgroups.BlkioStatEntry) []stats.BlkioStatEntry {
+	out := make([]stats.BlkioStatEntry, len(entries))
+	for i, re := range entries {
+		out[i] = stats.BlkioStatEntry{
+			Major: re.Major,
+			Minor: re.Minor,
+			Op:    re.Op,
+			Value: re.Value,
+		}
+	}
+	return out
+}
+
+// getSystemCpuUsage returns the host system's cpu usage in nanoseconds,
+// as read from the first line of /proc/stat.
+func getSystemCpuUsage() (uint64, error) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return parseSystemCpuUsage(bufio.NewScanner(f))
+}
+
+func parseSystemCpuUsage(sc *bufio.Scanner) (uint64, error) {
+	for sc.Scan() {
+		parts := strings.Fields(sc.Text())
+		if len(parts) == 0 || parts[0] != "cpu" {
+			continue
+		}
+		if len(parts) < 8 {
+			return 0, fmt.Errorf("invalid number of cpu fields")
+		}
+		var sum uint64
+		for _, i := range parts[1:8] {
+			v, err := strconv.ParseUint(i, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("Unable to convert value %s to int: %s", i, err)
+			}
+			sum += v
+		}
+		return (sum * uint64(time.Second)) / clockTicks, nil
+	}
+	if err := sc.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("invalid stat format")
+}
@@ -0,0 +1,68 @@
+package daemon
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer"
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/network"
+)
+
+func TestParseSystemCpuUsage(t *testing.T) {
+	procStat := "cpu  100 0 50 800 50 0 0 0 0 0\ncpu0 100 0 50 800 50 0 0 0 0 0\nintr 1234\n"
+	usage, err := parseSystemCpuUsage(bufio.NewScanner(strings.NewReader(procStat)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := 1000 * uint64(time.Second) / clockTicks; usage != expected {
+		t.Fatalf("expected system usage %d, got %d", expected, usage)
+	}
+
+	if _, err := parseSystemCpuUsage(bufio.NewScanner(strings.NewReader("intr 1234\n"))); err == nil {
+		t.Fatal("expected an error without a cpu line")
+	}
+	if _, err := parseSystemCpuUsage(bufio.NewScanner(strings.NewReader("cpu 1 2 3\n"))); err == nil {
+		t.Fatal("expected an error with a short cpu line")
+	}
+}
+
+func TestConvertToAPITypes(t *testing.T) {
+	cgroupStats := cgroups.NewStats()
+	cgroupStats.CpuStats.CpuUsage.TotalUsage = 42
+	cgroupStats.MemoryStats.Usage = 1024
+	cgroupStats.BlkioStats.IoServiceBytesRecursive = []cgroups.BlkioStatEntry{
+		{Major: 8, Minor: 0, Op: "Read", Value: 4096},
+	}
+	rs := &execdriver.ResourceStats{
+		ContainerStats: &libcontainer.ContainerStats{
+			CgroupStats:  cgroupStats,
+			NetworkStats: &network.NetworkStats{RxBytes: 10, TxBytes: 20},
+		},
+		MemoryLimit: 2048,
+		SystemUsage: 100,
+	}
+
+	s := convertToAPITypes(rs)
+	if s.CpuStats.CpuUsage.TotalUsage != 42 || s.CpuStats.SystemUsage != 100 {
+		t.Fatalf("unexpected cpu stats: %+v", s.CpuStats)
+	}
+	if s.MemoryStats.Usage != 1024 || s.MemoryStats.Limit != 2048 {
+		t.Fatalf("unexpected memory stats: %+v", s.MemoryStats)
+	}
+	if s.Network.RxBytes != 10 || s.Network.TxBytes != 20 {
+		t.Fatalf("unexpected network stats: %+v", s.Network)
+	}
+	if len(s.BlkioStats.IoServiceBytesRecursive) != 1 || s.BlkioStats.IoServiceBytesRecursive[0].Value != 4096 {
+		t.Fatalf("unexpected blkio stats: %+v", s.BlkioStats)
+	}
+
+	// drivers without network statistics must not break the conversion
+	rs.NetworkStats = nil
+	if s := convertToAPITypes(rs); s.Network.RxBytes != 0 {
+		t.Fatalf("expected empty network stats, got %+v", s.Network)
+	}
+}
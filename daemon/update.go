@@ -0,0 +1,117 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/engine"
+	"github.com/docker/docker/runconfig"
+)
+
+// ContainerUpdate changes the resource limits and the restart policy of a
+// container. The limits of a running container are applied right away.
+func (daemon *Daemon) ContainerUpdate(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("Usage: %s CONTAINER", job.Name)
+	}
+	name := job.Args[0]
+	container := daemon.Get(name)
+	if container == nil {
+		return job.Errorf("No such container: %s", name)
+	}
+
+	update := runconfig.ContainerConfigFromJob(job)
+	var restartPolicy *runconfig.RestartPolicy
+	if job.EnvExists("RestartPolicy") {
+		restartPolicy = &runconfig.RestartPolicy{}
+		if err := job.GetenvJson("RestartPolicy", restartPolicy); err != nil {
+			return job.Error(err)
+		}
+	}
+
+	warnings, err := container.update(update, restartPolicy)
+	if err != nil {
+		return job.Errorf("Cannot update container %s: %s", name, err)
+	}
+	for _, warning := range warnings {
+		job.Errorf("%s\n", warning)
+	}
+	container.LogEvent("update")
+	return engine.StatusOK
+}
+
+// update sets the non-zero resource limits of config and the restart policy
+// when it is not nil, and saves them.
+func (container *Container) update(config *runconfig.Config, restartPolicy *runconfig.RestartPolicy) ([]string, error) {
+	if restartPolicy != nil {
+		if err := runconfig.ValidateRestartPolicy(*restartPolicy); err != nil {
+			return nil, err
+		}
+	}
+
+	container.Lock()
+	defer container.Unlock()
+
+	updated := *container.Config
+	if config.Memory != 0 {
+		updated.Memory = config.Memory
+	}
+	if config.MemorySwap != 0 {
+		updated.MemorySwap = config.MemorySwap
+	}
+	if config.MemoryReservation != 0 {
+		updated.MemoryReservation = config.MemoryReservation
+	}
+	if config.KernelMemory != 0 {
+		// The kernel only accepts it before any process joins the cgroup
+		if container.Running {
+			return nil, fmt.Errorf("The kernel memory limit of a running container cannot be updated")
+		}
+		updated.KernelMemory = config.KernelMemory
+	}
+	if config.CpuShares != 0 {
+		updated.CpuShares = config.CpuShares
+	}
+	if config.CpuPeriod != 0 {
+		updated.CpuPeriod = config.CpuPeriod
+	}
+	if config.CpuQuota != 0 {
+		updated.CpuQuota = config.CpuQuota
+	}
+	if config.Cpuset != "" {
+		updated.Cpuset = config.Cpuset
+	}
+	if config.BlkioWeight != 0 {
+		updated.BlkioWeight = config.BlkioWeight
+	}
+	if err := verifyResourceLimits(&updated); err != nil {
+		return nil, err
+	}
+	warnings := container.daemon.verifyResources(&updated, nil)
+
+	if container.Running && container.command != nil {
+		resources := *container.command.Resources
+		resources.Memory = updated.Memory
+		resources.MemorySwap = updated.MemorySwap
+		resources.MemoryReservation = updated.MemoryReservation
+		resources.CpuShares = updated.CpuShares
+		resources.CpuPeriod = updated.CpuPeriod
+		resources.CpuQuota = updated.CpuQuota
+		resources.Cpuset = updated.Cpuset
+		resources.BlkioWeight = updated.BlkioWeight
+		previous := container.command.Resources
+		container.command.Resources = &resources
+		if err := container.daemon.execDriver.Update(container.command); err != nil {
+			container.command.Resources = previous
+			return nil, err
+		}
+	}
+
+	container.Config = &updated
+	if restartPolicy != nil {
+		container.hostConfig.RestartPolicy = *restartPolicy
+		if container.monitor != nil {
+			container.monitor.SetRestartPolicy(*restartPolicy)
+		}
+	}
+	return warnings, container.toDisk()
+}
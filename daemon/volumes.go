@@ -90,6 +90,10 @@ func (m *Mount) initialize() error {
 	m.container.Volumes[m.MountToPath] = m.volume.Path
 	m.volume.AddContainer(m.container.ID)
 	if m.Writable && m.copyData {
+		// The volume must be mounted before copying data into it
+		if err := m.container.mountVolume(m.volume); err != nil {
+			return err
+		}
 		// Copy whatever is in the container at the mntToPath to the volume
 		copyExistingContents(containerMntPath, m.volume.Path)
 	}
@@ -111,6 +115,41 @@ func (container *Container) registerVolumes() {
 	}
 }
 
+// mountVolume mounts v for the container unless it is already mounted.
+func (container *Container) mountVolume(v *volumes.Volume) error {
+	if _, exists := container.mountedVolumes[v.Path]; exists {
+		return nil
+	}
+	if err := v.Mount(); err != nil {
+		return err
+	}
+	if container.mountedVolumes == nil {
+		container.mountedVolumes = make(map[string]*volumes.Volume)
+	}
+	container.mountedVolumes[v.Path] = v
+	return nil
+}
+
+// mountVolumes mounts all the volumes of the container which are created
+// by a volume driver.
+func (container *Container) mountVolumes() error {
+	for _, mnt := range container.VolumeMounts() {
+		if err := container.mountVolume(mnt.volume); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (container *Container) unmountVolumes() {
+	for path, v := range container.mountedVolumes {
+		if err := v.Unmount(); err != nil {
+			log.Errorf("%v: Failed to unmount volume %s: %v", container.ID, path, err)
+		}
+		delete(container.mountedVolumes, path)
+	}
+}
+
 func (container *Container) derefVolumes() {
 	for path := range container.VolumePaths() {
 		vol := container.daemon.volumes.Get(path)
@@ -130,8 +169,13 @@ func (container *Container) parseVolumeMountConfig() (map[string]*Mount, error)
 		if err != nil {
 			return nil, err
 		}
-		// Check if a volume already exists for this and use it
-		vol, err := container.daemon.volumes.FindOrCreateVolume(path, writable)
+		var vol *volumes.Volume
+		if filepath.IsAbs(path) {
+			// Check if a volume already exists for this and use it
+			vol, err = container.daemon.volumes.FindOrCreateVolume(path, writable)
+		} else {
+			vol, err = container.daemon.volumes.FindOrCreateNamedVolume(path, container.hostConfig.VolumeDriver)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -140,6 +184,8 @@ func (container *Container) parseVolumeMountConfig() (map[string]*Mount, error)
 			volume:      vol,
 			MountToPath: mountToPath,
 			Writable:    writable,
+			// Named volumes get the content of the image while they are empty
+			copyData: !filepath.IsAbs(path),
 		}
 	}
 
@@ -156,7 +202,7 @@ func (container *Container) parseVolumeMountConfig() (map[string]*Mount, error)
 			continue
 		}
 
-		vol, err := container.daemon.volumes.FindOrCreateVolume("", true)
+		vol, err := container.daemon.volumes.Create("", container.hostConfig.VolumeDriver)
 		if err != nil {
 			return nil, err
 		}
@@ -192,11 +238,14 @@ func parseBindMountSpec(spec string) (string, string, bool, error) {
 		return "", "", false, fmt.Errorf("Invalid volume specification: %s", spec)
 	}
 
+	// Anything which is not an absolute path refers to a named volume
 	if !filepath.IsAbs(path) {
-		return "", "", false, fmt.Errorf("cannot bind mount volume: %s volume paths must be absolute.", path)
+		if !volumes.IsValidName(path) {
+			return "", "", false, fmt.Errorf("cannot bind mount volume: %s volume paths must be absolute or be a valid volume name.", path)
+		}
+	} else {
+		path = filepath.Clean(path)
 	}
-
-	path = filepath.Clean(path)
 	mountToPath = filepath.Clean(mountToPath)
 	return path, mountToPath, writable, nil
 }
@@ -312,18 +361,21 @@ func copyExistingContents(source, destination string) error {
 		return err
 	}
 
+	srcList, err := ioutil.ReadDir(destination)
+	if err != nil {
+		return err
+	}
+	// A volume which is not empty, such as a named volume used before,
+	// keeps its content and ownership
+	if len(srcList) > 0 {
+		return nil
+	}
+
 	if len(volList) > 0 {
-		srcList, err := ioutil.ReadDir(destination)
-		if err != nil {
+		// If the source volume is empty copy files from the root into the volume
+		if err := chrootarchive.CopyWithTar(source, destination); err != nil {
 			return err
 		}
-
-		if len(srcList) == 0 {
-			// If the source volume is empty copy files from the root into the volume
-			if err := chrootarchive.CopyWithTar(source, destination); err != nil {
-				return err
-			}
-		}
 	}
 
 	return copyOwnership(source, destination)
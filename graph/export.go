@@ -11,6 +11,7 @@ import (
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/parsers"
+	"github.com/docker/docker/utils"
 )
 
 // CmdImageExport exports all images with the given tag. All versions
@@ -31,6 +32,10 @@ func (s *TagStore) CmdImageExport(job *engine.Job) engine.Status {
 
 	rootRepoMap := map[string]Repository{}
 	addKey := func(name string, tag string, id string) {
+		// Digests cannot be set again by a load, only by a pull
+		if utils.DigestReference(tag) {
+			return
+		}
 		log.Debugf("add key [%s:%s]", name, tag)
 		if repo, ok := rootRepoMap[name]; !ok {
 			rootRepoMap[name] = Repository{tag: id}
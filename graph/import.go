@@ -1,12 +1,15 @@
 package graph
 
 import (
+	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/url"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
 )
 
@@ -26,6 +29,21 @@ func (s *TagStore) CmdImport(job *engine.Job) engine.Status {
 		tag = job.Args[2]
 	}
 
+	var config *runconfig.Config
+	if changes := job.GetenvList("changes"); len(changes) > 0 {
+		buildConfigJob := job.Eng.Job("build_config")
+		buildConfigJob.SetenvList("changes", changes)
+		stdoutBuffer := bytes.NewBuffer(nil)
+		buildConfigJob.Stdout.Add(stdoutBuffer)
+		if err := buildConfigJob.Run(); err != nil {
+			return job.Error(err)
+		}
+		config = &runconfig.Config{}
+		if err := json.NewDecoder(stdoutBuffer).Decode(config); err != nil {
+			return job.Error(err)
+		}
+	}
+
 	if src == "-" {
 		archive = job.Stdin
 	} else {
@@ -47,7 +65,7 @@ func (s *TagStore) CmdImport(job *engine.Job) engine.Status {
 		defer progressReader.Close()
 		archive = progressReader
 	}
-	img, err := s.graph.Create(archive, "", "", "Imported from "+src, "", nil, nil)
+	img, err := s.graph.Create(archive, "", "", "Imported from "+src, "", nil, config)
 	if err != nil {
 		return job.Error(err)
 	}
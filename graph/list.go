@@ -1,7 +1,6 @@
 package graph
 
 import (
-	"fmt"
 	"log"
 	"path"
 	"strings"
@@ -9,6 +8,7 @@ import (
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/pkg/parsers/filters"
+	"github.com/docker/docker/utils"
 )
 
 func (s *TagStore) CmdImages(job *engine.Job) engine.Status {
@@ -46,16 +46,29 @@ func (s *TagStore) CmdImages(job *engine.Job) engine.Status {
 				continue
 			}
 		}
-		for tag, id := range repository {
+		for ref, id := range repository {
 			image, err := s.graph.Get(id)
 			if err != nil {
-				log.Printf("Warning: couldn't load %s from %s/%s: %s", id, name, tag, err)
+				log.Printf("Warning: couldn't load %s from %s/%s: %s", id, name, ref, err)
 				continue
 			}
 
+			if !imageFilters.MatchKVList("label", imageLabels(image)) {
+				delete(allImages, id)
+				continue
+			}
+
+			// Tags and digests are listed apart, images pulled by digest
+			// may have no tag
+			key := "RepoTags"
+			if utils.DigestReference(ref) {
+				key = "RepoDigests"
+			}
+			imgRef := utils.ImageReference(name, ref)
+
 			if out, exists := lookup[id]; exists {
 				if filt_tagged {
-					out.SetList("RepoTags", append(out.GetList("RepoTags"), fmt.Sprintf("%s:%s", name, tag)))
+					out.SetList(key, append(out.GetList(key), imgRef))
 				}
 			} else {
 				// get the boolean list for if only the untagged images are requested
@@ -63,11 +76,14 @@ func (s *TagStore) CmdImages(job *engine.Job) engine.Status {
 				if filt_tagged {
 					out := &engine.Env{}
 					out.Set("ParentId", image.Parent)
-					out.SetList("RepoTags", []string{fmt.Sprintf("%s:%s", name, tag)})
+					out.SetList("RepoTags", []string{})
+					out.SetList("RepoDigests", []string{})
+					out.SetList(key, []string{imgRef})
 					out.Set("Id", image.ID)
 					out.SetInt64("Created", image.Created.Unix())
 					out.SetInt64("Size", image.Size)
 					out.SetInt64("VirtualSize", image.GetParentsSize(0)+image.Size)
+					out.SetJson("Labels", imageLabels(image))
 					lookup[id] = out
 				}
 			}
@@ -78,19 +94,27 @@ func (s *TagStore) CmdImages(job *engine.Job) engine.Status {
 
 	outs := engine.NewTable("Created", len(lookup))
 	for _, value := range lookup {
+		if len(value.GetList("RepoTags")) == 0 {
+			value.SetList("RepoTags", []string{"<none>:<none>"})
+		}
 		outs.Add(value)
 	}
 
 	// Display images which aren't part of a repository/tag
 	if job.Getenv("filter") == "" {
 		for _, image := range allImages {
+			if !imageFilters.MatchKVList("label", imageLabels(image)) {
+				continue
+			}
 			out := &engine.Env{}
 			out.Set("ParentId", image.Parent)
 			out.SetList("RepoTags", []string{"<none>:<none>"})
+			out.SetList("RepoDigests", []string{})
 			out.Set("Id", image.ID)
 			out.SetInt64("Created", image.Created.Unix())
 			out.SetInt64("Size", image.Size)
 			out.SetInt64("VirtualSize", image.GetParentsSize(0)+image.Size)
+			out.SetJson("Labels", imageLabels(image))
 			outs.Add(out)
 		}
 	}
@@ -101,3 +125,10 @@ func (s *TagStore) CmdImages(job *engine.Job) engine.Status {
 	}
 	return engine.StatusOK
 }
+
+func imageLabels(img *image.Image) map[string]string {
+	if img.Config == nil {
+		return nil
+	}
+	return img.Config.Labels
+}
@@ -2,6 +2,8 @@ package graph
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,27 +22,33 @@ import (
 	"github.com/docker/libtrust"
 )
 
-func (s *TagStore) verifyManifest(eng *engine.Engine, manifestBytes []byte) (*registry.ManifestData, bool, error) {
+// verifyManifest returns the manifest, the digest of its payload and
+// whether it is signed by a key allowed for the repository.
+func (s *TagStore) verifyManifest(eng *engine.Engine, manifestBytes []byte) (*registry.ManifestData, string, bool, error) {
 	sig, err := libtrust.ParsePrettySignature(manifestBytes, "signatures")
 	if err != nil {
-		return nil, false, fmt.Errorf("error parsing payload: %s", err)
+		return nil, "", false, fmt.Errorf("error parsing payload: %s", err)
 	}
 	keys, err := sig.Verify()
 	if err != nil {
-		return nil, false, fmt.Errorf("error verifying payload: %s", err)
+		return nil, "", false, fmt.Errorf("error verifying payload: %s", err)
 	}
 
 	payload, err := sig.Payload()
 	if err != nil {
-		return nil, false, fmt.Errorf("error retrieving payload: %s", err)
+		return nil, "", false, fmt.Errorf("error retrieving payload: %s", err)
 	}
+	// The signatures are not part of the digest, so that signing a manifest
+	// again does not change it
+	sum := sha256.Sum256(payload)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
 
 	var manifest registry.ManifestData
 	if err := json.Unmarshal(payload, &manifest); err != nil {
-		return nil, false, fmt.Errorf("error unmarshalling manifest: %s", err)
+		return nil, "", false, fmt.Errorf("error unmarshalling manifest: %s", err)
 	}
 	if manifest.SchemaVersion != 1 {
-		return nil, false, fmt.Errorf("unsupported schema version: %d", manifest.SchemaVersion)
+		return nil, "", false, fmt.Errorf("unsupported schema version: %d", manifest.SchemaVersion)
 	}
 
 	var verified bool
@@ -48,7 +56,7 @@ func (s *TagStore) verifyManifest(eng *engine.Engine, manifestBytes []byte) (*re
 		job := eng.Job("trust_key_check")
 		b, err := key.MarshalJSON()
 		if err != nil {
-			return nil, false, fmt.Errorf("error marshalling public key: %s", err)
+			return nil, "", false, fmt.Errorf("error marshalling public key: %s", err)
 		}
 		namespace := manifest.Name
 		if namespace[0] != '/' {
@@ -62,7 +70,7 @@ func (s *TagStore) verifyManifest(eng *engine.Engine, manifestBytes []byte) (*re
 		job.SetenvInt("Permission", 0x03)
 		job.Stdout.Add(stdoutBuffer)
 		if err = job.Run(); err != nil {
-			return nil, false, fmt.Errorf("error running key check: %s", err)
+			return nil, "", false, fmt.Errorf("error running key check: %s", err)
 		}
 		result := engine.Tail(stdoutBuffer, 1)
 		log.Debugf("Key check result: %q", result)
@@ -71,7 +79,7 @@ func (s *TagStore) verifyManifest(eng *engine.Engine, manifestBytes []byte) (*re
 		}
 	}
 
-	return &manifest, verified, nil
+	return &manifest, digest, verified, nil
 }
 
 func (s *TagStore) CmdPull(job *engine.Job) engine.Status {
@@ -91,6 +99,11 @@ func (s *TagStore) CmdPull(job *engine.Job) engine.Status {
 	if len(job.Args) > 1 {
 		tag = job.Args[1]
 	}
+	if utils.DigestReference(tag) {
+		if err := ValidateDigest(tag); err != nil {
+			return job.Error(err)
+		}
+	}
 
 	job.GetenvJson("authConfig", authConfig)
 	job.GetenvJson("metaHeaders", &metaHeaders)
@@ -139,7 +152,7 @@ func (s *TagStore) CmdPull(job *engine.Job) engine.Status {
 
 	logName := localName
 	if tag != "" {
-		logName += ":" + tag
+		logName = utils.ImageReference(localName, tag)
 	}
 
 	if len(mirrors) == 0 && (isOfficial || endpoint.Version == registry.APIVersion2) {
@@ -153,11 +166,18 @@ func (s *TagStore) CmdPull(job *engine.Job) engine.Status {
 				log.Errorf("Error logging event 'pull' for %s: %s", logName, err)
 			}
 			return engine.StatusOK
+		} else if utils.DigestReference(tag) {
+			return job.Error(err)
 		} else if err != registry.ErrDoesNotExist {
 			log.Errorf("Error from V2 registry: %s", err)
 		}
 	}
 
+	// Only the manifests of V2 registries have a digest
+	if utils.DigestReference(tag) {
+		return job.Errorf("Cannot pull %s: images can only be pulled by digest from a V2 registry", logName)
+	}
+
 	if err = s.pullRepository(r, job.Stdout, localName, remoteName, tag, sf, job.GetenvBool("parallel"), mirrors); err != nil {
 		return job.Error(err)
 	}
@@ -465,7 +485,7 @@ func (s *TagStore) pullV2Repository(eng *engine.Engine, r *registry.Session, out
 
 	requestedTag := localName
 	if len(tag) > 0 {
-		requestedTag = localName + ":" + tag
+		requestedTag = utils.ImageReference(localName, tag)
 	}
 	WriteStatus(requestedTag, out, sf, layersDownloaded)
 	return nil
@@ -478,17 +498,20 @@ func (s *TagStore) pullV2Tag(eng *engine.Engine, r *registry.Session, out io.Wri
 		return false, err
 	}
 
-	manifest, verified, err := s.verifyManifest(eng, manifestBytes)
+	manifest, digest, verified, err := s.verifyManifest(eng, manifestBytes)
 	if err != nil {
 		return false, fmt.Errorf("error verifying manifest: %s", err)
 	}
+	if utils.DigestReference(tag) && digest != tag {
+		return false, fmt.Errorf("the digest of the manifest %s does not match the requested digest %s", digest, tag)
+	}
 
 	if len(manifest.FSLayers) != len(manifest.History) {
 		return false, fmt.Errorf("length of history not equal to number of layers")
 	}
 
 	if verified {
-		out.Write(sf.FormatStatus(localName+":"+tag, "The image you are pulling has been verified"))
+		out.Write(sf.FormatStatus(utils.ImageReference(localName, tag), "The image you are pulling has been verified"))
 	} else {
 		out.Write(sf.FormatStatus(tag, "Pulling from %s", localName))
 	}
@@ -605,9 +628,15 @@ func (s *TagStore) pullV2Tag(eng *engine.Engine, r *registry.Session, out io.Wri
 
 	}
 
-	if err = s.Set(localName, tag, downloads[0].img.ID, true); err != nil {
+	if !utils.DigestReference(tag) {
+		if err = s.Set(localName, tag, downloads[0].img.ID, true); err != nil {
+			return false, err
+		}
+	}
+	if err = s.SetDigest(localName, digest, downloads[0].img.ID); err != nil {
 		return false, err
 	}
+	out.Write(sf.FormatStatus("", "Digest: %s", digest))
 
 	return layersDownloaded, nil
 }
@@ -201,6 +201,9 @@ func (s *TagStore) CmdPush(job *engine.Job) engine.Status {
 	)
 
 	tag := job.Getenv("tag")
+	if utils.DigestReference(tag) {
+		return job.Errorf("Cannot push %s: images are pushed by tag, not by digest", utils.ImageReference(localName, tag))
+	}
 	job.GetenvJson("authConfig", authConfig)
 	job.GetenvJson("metaHeaders", &metaHeaders)
 	if _, err := s.poolAdd("push", localName); err != nil {
@@ -226,13 +229,21 @@ func (s *TagStore) CmdPush(job *engine.Job) engine.Status {
 	}
 
 	if err != nil {
+		// Digests are only known to the V2 registry the images were pulled
+		// from, only the tags are pushed
+		localRepo := Repository{}
+		for ref, id := range s.Repositories[localName] {
+			if !utils.DigestReference(ref) {
+				localRepo[ref] = id
+			}
+		}
 		reposLen := 1
 		if tag == "" {
-			reposLen = len(s.Repositories[localName])
+			reposLen = len(localRepo)
 		}
 		job.Stdout.Write(sf.FormatStatus("", "The push refers to a repository [%s] (len: %d)", localName, reposLen))
 		// If it fails, try to get the repository
-		if localRepo, exists := s.Repositories[localName]; exists {
+		if _, exists := s.Repositories[localName]; exists {
 			if err := s.pushRepository(r, job.Stdout, localName, remoteName, localRepo, tag, sf); err != nil {
 				return job.Error(err)
 			}
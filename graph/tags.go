@@ -20,6 +20,7 @@ const DEFAULTTAG = "latest"
 
 var (
 	validTagName = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	validDigest  = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-f0-9]{32,}$`)
 )
 
 type TagStore struct {
@@ -35,6 +36,8 @@ type TagStore struct {
 	pushingPool map[string]chan struct{}
 }
 
+// Repository maps the tags and the manifest digests of the images of a
+// repository to their IDs.
 type Repository map[string]string
 
 // update Repository mapping with content of u
@@ -125,14 +128,14 @@ func (store *TagStore) LookupImage(name string) (*image.Image, error) {
 }
 
 // Return a reverse-lookup table of all the names which refer to each image
-// Eg. {"43b5f19b10584": {"base:latest", "base:v1"}}
+// Eg. {"43b5f19b10584": {"base:latest", "base:v1", "base@sha256:e8c1..."}}
 func (store *TagStore) ByID() map[string][]string {
 	store.Lock()
 	defer store.Unlock()
 	byID := make(map[string][]string)
 	for repoName, repository := range store.Repositories {
 		for tag, id := range repository {
-			name := repoName + ":" + tag
+			name := utils.ImageReference(repoName, tag)
 			if _, exists := byID[id]; !exists {
 				byID[id] = []string{name}
 			} else {
@@ -157,15 +160,9 @@ func (store *TagStore) DeleteAll(id string) error {
 		return nil
 	}
 	for _, name := range names {
-		if strings.Contains(name, ":") {
-			nameParts := strings.Split(name, ":")
-			if _, err := store.Delete(nameParts[0], nameParts[1]); err != nil {
-				return err
-			}
-		} else {
-			if _, err := store.Delete(name, ""); err != nil {
-				return err
-			}
+		repoName, tag := parsers.ParseRepositoryTag(name)
+		if _, err := store.Delete(repoName, tag); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -187,7 +184,7 @@ func (store *TagStore) Delete(repoName, tag string) (bool, error) {
 				}
 				deleted = true
 			} else {
-				return false, fmt.Errorf("No such tag: %s:%s", repoName, tag)
+				return false, fmt.Errorf("No such tag: %s", utils.ImageReference(repoName, tag))
 			}
 		} else {
 			delete(store.Repositories, repoName)
@@ -232,6 +229,34 @@ func (store *TagStore) Set(repoName, tag, imageName string, force bool) error {
 	return store.save()
 }
 
+// SetDigest refers to the image imageName by the digest of its manifest in
+// the repository repoName, the image can then be referenced as
+// repoName@digest.
+func (store *TagStore) SetDigest(repoName, digest, imageName string) error {
+	img, err := store.LookupImage(imageName)
+	store.Lock()
+	defer store.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := validateRepoName(repoName); err != nil {
+		return err
+	}
+	if err := ValidateDigest(digest); err != nil {
+		return err
+	}
+	if err := store.reload(); err != nil {
+		return err
+	}
+	repo, exists := store.Repositories[repoName]
+	if !exists {
+		repo = make(map[string]string)
+		store.Repositories[repoName] = repo
+	}
+	repo[digest] = img.ID
+	return store.save()
+}
+
 func (store *TagStore) Get(repoName string) (Repository, error) {
 	store.Lock()
 	defer store.Unlock()
@@ -272,7 +297,7 @@ func (store *TagStore) GetRepoRefs() map[string][]string {
 	for name, repository := range store.Repositories {
 		for tag, id := range repository {
 			shortID := utils.TruncateID(id)
-			reporefs[shortID] = append(reporefs[shortID], fmt.Sprintf("%s:%s", name, tag))
+			reporefs[shortID] = append(reporefs[shortID], utils.ImageReference(name, tag))
 		}
 	}
 	store.Unlock()
@@ -312,6 +337,14 @@ func ValidateTagName(name string) error {
 	return nil
 }
 
+// Validate the digest of a manifest, as algorithm:hex
+func ValidateDigest(digest string) error {
+	if !validDigest.MatchString(digest) {
+		return fmt.Errorf("Invalid digest (%s): expected algorithm:hex, like sha256:e8c1...", digest)
+	}
+	return nil
+}
+
 func (store *TagStore) poolAdd(kind, key string) (chan struct{}, error) {
 	store.Lock()
 	defer store.Unlock()
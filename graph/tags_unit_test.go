@@ -116,6 +116,61 @@ func TestLookupImage(t *testing.T) {
 	}
 }
 
+func TestLookupImageByDigest(t *testing.T) {
+	tmp, err := utils.TestDirectory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	store := mkTestTagStore(tmp, t)
+	defer store.graph.driver.Cleanup()
+
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	if err := store.SetDigest(testImageName, digest, testImageID); err != nil {
+		t.Fatal(err)
+	}
+
+	if img, err := store.LookupImage(testImageName + "@" + digest); err != nil {
+		t.Fatal(err)
+	} else if img == nil || img.ID != testImageID {
+		t.Errorf("Expected image %s, got %v", testImageID, img)
+	}
+
+	names := store.ByID()[testImageID]
+	if len(names) != 2 || names[0] != testImageName+":"+DEFAULTTAG || names[1] != testImageName+"@"+digest {
+		t.Errorf("Unexpected names of the image: %v", names)
+	}
+
+	if err := store.Set(testImageName, digest, testImageID, true); err == nil {
+		t.Errorf("Expected error setting a digest as a tag, none found")
+	}
+
+	if _, err := store.Delete(testImageName, digest); err != nil {
+		t.Fatal(err)
+	}
+	if img, err := store.LookupImage(testImageName + "@" + digest); err == nil {
+		t.Errorf("Expected error, none found")
+	} else if img != nil {
+		t.Errorf("Expected 0 image, 1 found")
+	}
+}
+
+func TestValidDigest(t *testing.T) {
+	digests := map[string]bool{
+		"sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef": true,
+		"tarsum+sha256:0123456789abcdef0123456789abcdef":                          true,
+		"sha256:0123": false,
+		"sha256:0123456789ABCDEF0123456789ABCDEF0123456789ABCDEF0123456789ABCDEF": false,
+		"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef":        false,
+		"latest": false,
+	}
+	for digest, valid := range digests {
+		if err := ValidateDigest(digest); (err == nil) != valid {
+			t.Errorf("Unexpected validity of %s: expected %v, got %v", digest, valid, err)
+		}
+	}
+}
+
 func TestValidTagName(t *testing.T) {
 	validTags := []string{"9", "foo", "foo-test", "bar.baz.boo"}
 	for _, tag := range validTags {
@@ -6,7 +6,9 @@ import (
 	"io"
 	"os/exec"
 	"testing"
+	"time"
 
+	"github.com/docker/docker/api/stats"
 	"github.com/docker/docker/vendor/src/code.google.com/p/go/src/pkg/archive/tar"
 )
 
@@ -120,3 +122,50 @@ func TestContainerApiGetChanges(t *testing.T) {
 
 	logDone("container REST API - check GET containers/changes")
 }
+
+func TestContainerApiGetStats(t *testing.T) {
+	defer deleteAllContainers()
+	name := "statscontainer"
+	runCmd := exec.Command(dockerBinary, "run", "-d", "--name", name, "busybox", "top")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatalf("Error on container creation: %v, output: %q", err, out)
+	}
+	type b struct {
+		body []byte
+		err  error
+	}
+	bc := make(chan b, 1)
+	go func() {
+		body, err := sockRequest("GET", "/containers/"+name+"/stats")
+		bc <- b{body, err}
+	}()
+
+	// allow some time to stream the stats from the container
+	time.Sleep(4 * time.Second)
+	if _, err := runCommand(exec.Command(dockerBinary, "rm", "-f", name)); err != nil {
+		t.Fatal(err)
+	}
+
+	// collect the results from the stats stream or timeout and fail
+	// if the stream was not disconnected.
+	select {
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream was not closed after container was removed")
+	case sr := <-bc:
+		if sr.err != nil {
+			t.Fatal(sr.err)
+		}
+
+		dec := json.NewDecoder(bytes.NewBuffer(sr.body))
+		var s *stats.Stats
+		// decode only one object from the stream
+		if err := dec.Decode(&s); err != nil {
+			t.Fatal(err)
+		}
+		if s.MemoryStats.Limit == 0 {
+			t.Fatal("expected a memory limit in the stats")
+		}
+	}
+	logDone("container REST API - check GET containers/stats")
+}
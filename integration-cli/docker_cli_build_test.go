@@ -2807,6 +2807,97 @@ func TestBuildDockerignore(t *testing.T) {
 	logDone("build - test .dockerignore")
 }
 
+func TestBuildDockerignoreExceptions(t *testing.T) {
+	name := "testbuilddockerignoreexceptions"
+	defer deleteImages(name)
+	dockerfile := `
+        FROM busybox
+        ADD . /bla
+		RUN [[ -f /bla/README.md ]]
+		RUN [[ ! -e /bla/CHANGES.md ]]
+		RUN [[ -f /bla/src/keep ]]
+		RUN [[ ! -e /bla/src/a/b/secret ]]
+		RUN [[ ! -e /bla/secret ]]
+		RUN [[ ! -e /bla/.dockerignore ]]`
+	ctx, err := fakeContext(dockerfile, map[string]string{
+		"README.md":      "readme",
+		"CHANGES.md":     "changes",
+		"src/keep":       "keep",
+		"src/a/b/secret": "secret",
+		"secret":         "secret",
+		".dockerignore":  "*.md\n!README.md\n**/secret\n.dockerignore",
+	})
+	defer ctx.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := buildImageFromContext(name, ctx, true); err != nil {
+		t.Fatal(err)
+	}
+	logDone("build - test .dockerignore with exceptions and **")
+}
+
+func TestBuildDockerignoreContextTar(t *testing.T) {
+	name := "testbuilddockerignorecontexttar"
+	defer deleteImages(name)
+	dockerfile := `
+        FROM busybox
+        ADD . /bla
+		RUN [[ -f /bla/keep ]]
+		RUN [[ ! -e /bla/ignored ]]`
+	ctx, err := fakeContext(dockerfile, map[string]string{
+		"keep":          "keep",
+		"ignored":       "ignored",
+		".dockerignore": "ignored",
+	})
+	defer ctx.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The context is sent as is, the daemon excludes the files
+	build := func() (string, error) {
+		context, err := archive.Tar(ctx.Dir, archive.Uncompressed)
+		if err != nil {
+			t.Fatalf("failed to build context tar: %v", err)
+		}
+		buildCmd := exec.Command(dockerBinary, "build", "-t", name, "-")
+		buildCmd.Stdin = context
+		out, _, err := runCommandWithOutput(buildCmd)
+		return out, err
+	}
+	if out, err := build(); err != nil {
+		t.Fatalf("build failed to complete: %v %v", out, err)
+	}
+	id1, err := getIDByName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ignored files do not invalidate the cache
+	if err := ctx.Add("ignored", "changed"); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := build(); err != nil {
+		t.Fatalf("build failed to complete: %v %v", out, err)
+	}
+	id2, err := getIDByName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id1 != id2 {
+		t.Fatal("The cache should have been used")
+	}
+
+	if err := ctx.Add(".dockerignore", "Dockerfile"); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := build(); err == nil || !strings.Contains(out, "Dockerfile was excluded") {
+		t.Fatalf("Expected an error excluding the Dockerfile: %s", out)
+	}
+	logDone("build - test .dockerignore of a context tar")
+}
+
 func TestBuildDockerignoreCleanPaths(t *testing.T) {
 	name := "testbuilddockerignorecleanpaths"
 	defer deleteImages(name)
@@ -3522,3 +3613,358 @@ func TestBuildWithTabs(t *testing.T) {
 	}
 	logDone("build - with tabs")
 }
+
+func TestBuildLabel(t *testing.T) {
+	name := "testbuildlabel"
+	expected := `{"com.example.from":"image","com.example.key":"value with spaces","com.example.multi":"1"}`
+	defer deleteImages(name)
+	_, err := buildImage(name,
+		`FROM busybox
+		LABEL com.example.key value with spaces
+		LABEL com.example.multi=1 com.example.from=image`,
+		true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := inspectFieldJSON(name, "Config.Labels")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != expected {
+		t.Fatalf("Labels %s, expected %s", res, expected)
+	}
+	logDone("build - label")
+}
+
+func TestBuildLabelsCache(t *testing.T) {
+	name := "testbuildlabelcache"
+	defer deleteImages(name)
+	id1, err := buildImage(name,
+		`FROM busybox
+		LABEL Vendor=Acme`, true)
+	if err != nil {
+		t.Fatalf("Build 1 should have worked: %v", err)
+	}
+	id2, err := buildImage(name,
+		`FROM busybox
+		LABEL Vendor=Acme`, true)
+	if err != nil || id1 != id2 {
+		t.Fatalf("Build 2 should have worked & used cache(%s,%s): %v", id1, id2, err)
+	}
+	id2, err = buildImage(name,
+		`FROM busybox
+		LABEL Vendor=Acme1`, true)
+	if err != nil || id1 == id2 {
+		t.Fatalf("Build 3 should have worked & NOT used cache(%s,%s): %v", id1, id2, err)
+	}
+	logDone("build - label cache")
+}
+
+func buildImageWithBuildArgs(name, dockerfile string, buildArgs ...string) (string, string, error) {
+	args := []string{"build", "-t", name}
+	for _, arg := range buildArgs {
+		args = append(args, "--build-arg", arg)
+	}
+	buildCmd := exec.Command(dockerBinary, append(args, "-")...)
+	buildCmd.Stdin = strings.NewReader(dockerfile)
+	out, exitCode, err := runCommandWithOutput(buildCmd)
+	if err != nil || exitCode != 0 {
+		return "", out, fmt.Errorf("failed to build the image: %s", out)
+	}
+	id, err := getIDByName(name)
+	return id, out, err
+}
+
+func TestBuildBuildArgs(t *testing.T) {
+	name := "testbuildbuildargs"
+	defer deleteImages(name)
+	dockerfile := `FROM busybox
+ARG user
+ARG dir=/tmp
+ENV shadowed=env
+ARG shadowed
+WORKDIR $dir
+RUN [ "$user" = "bob" ] && [ "$dir" = "/tmp" ] && [ "$shadowed" = "env" ] && [ "$(pwd)" = "/tmp" ]`
+
+	id1, _, err := buildImageWithBuildArgs(name, dockerfile, "user=bob", "shadowed=arg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := inspectFieldJSON(name, "Config.Env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(res, "user=") || strings.Contains(res, "dir=") {
+		t.Fatalf("Build arguments should not be committed in the image, got env %s", res)
+	}
+
+	id2, out, err := buildImageWithBuildArgs(name, dockerfile, "user=bob", "shadowed=arg")
+	if err != nil || id1 != id2 || !strings.Contains(out, "Using cache") {
+		t.Fatalf("Build with the same arguments should have used cache(%s,%s): %v", id1, id2, err)
+	}
+	id2, _, err = buildImageWithBuildArgs(name, strings.Replace(dockerfile, `"bob"`, `"alice"`, 1), "user=alice", "shadowed=arg")
+	if err != nil || id1 == id2 {
+		t.Fatalf("Build with other arguments should NOT have used cache(%s,%s): %v", id1, id2, err)
+	}
+	logDone("build - build-time arguments")
+}
+
+func TestBuildBuildArgsNotConsumed(t *testing.T) {
+	name := "testbuildbuildargsnotconsumed"
+	defer deleteImages(name)
+	_, out, err := buildImageWithBuildArgs(name, "FROM busybox\nARG used\nRUN true", "used=1", "unused=2")
+	if err == nil || !strings.Contains(out, "[unused] were not consumed") {
+		t.Fatalf("Expected the build to fail with an unused argument, got %s", out)
+	}
+	logDone("build - build-time arguments not declared")
+}
+
+func TestBuildBuildArgsScopedToStage(t *testing.T) {
+	name := "testbuildbuildargsscopedtostage"
+	defer deleteImages(name)
+	dockerfile := `FROM busybox
+ARG user
+ARG dir=/tmp
+RUN [ "$user" = "bob" ] && [ "$dir" = "/tmp" ]
+FROM busybox
+ARG dir
+RUN [ -z "$user" ] && [ -z "$dir" ]`
+
+	if _, out, err := buildImageWithBuildArgs(name, dockerfile, "user=bob"); err != nil {
+		t.Fatalf("Expected the arguments of the first stage to be unset in the second one: %s", out)
+	}
+	logDone("build - build-time arguments scoped to their build stage")
+}
+
+func TestBuildRenamedDockerfile(t *testing.T) {
+	name := "testbuildrenameddockerfile"
+	defer deleteImages(name)
+	ctx, err := fakeContext("FROM busybox\nRUN echo from-Dockerfile", map[string]string{
+		"files/Dockerfile.dev": "FROM busybox\nRUN echo from-Dockerfile.dev",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.Close()
+
+	out, _, err := dockerCmdInDir(t, ctx.Dir, "build", "-t", name, "-f", "files/Dockerfile.dev", ".")
+	if err != nil || !strings.Contains(out, "from-Dockerfile.dev") {
+		t.Fatalf("Expected the build to use files/Dockerfile.dev: %s", out)
+	}
+
+	// The path is relative to the current directory
+	out, _, err = dockerCmdInDir(t, filepath.Join(ctx.Dir, "files"), "build", "-t", name, "-f", "Dockerfile.dev", "..")
+	if err != nil || !strings.Contains(out, "from-Dockerfile.dev") {
+		t.Fatalf("Expected the build to use files/Dockerfile.dev: %s", out)
+	}
+
+	buildCmd := exec.Command(dockerBinary, "build", "-t", name, "-f", "/etc/passwd", ".")
+	buildCmd.Dir = ctx.Dir
+	if out, _, err := runCommandWithOutput(buildCmd); err == nil || !strings.Contains(out, "must be within the build context") {
+		t.Fatalf("Expected an error building with a Dockerfile outside of the context: %s", out)
+	}
+
+	ctx.Add(".dockerignore", "files\n")
+	buildCmd = exec.Command(dockerBinary, "build", "-t", name, "-f", "files/Dockerfile.dev", ".")
+	buildCmd.Dir = ctx.Dir
+	if out, _, err := runCommandWithOutput(buildCmd); err == nil || !strings.Contains(out, "Dockerfile was excluded") {
+		t.Fatalf("Expected an error excluding the directory of the Dockerfile: %s", out)
+	}
+	logDone("build - renamed Dockerfile")
+}
+
+func TestBuildMultiStage(t *testing.T) {
+	name := "testbuildmultistage"
+	defer deleteImages(name)
+	dockerfile := `FROM busybox AS builder
+RUN echo built > /artifact && mkdir /out && echo out > /out/file
+FROM busybox
+COPY --from=builder /artifact /copied
+COPY --from=0 /out/ /out/
+COPY --from=busybox /bin/true /true
+RUN [ "$(cat /copied)" = "built" ] && [ "$(cat /out/file)" = "out" ] && [ -x /true ] && [ ! -e /artifact ]`
+
+	id1, err := buildImage(name, dockerfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id2, out, err := buildImageWithOut(name, dockerfile, true)
+	if err != nil || id1 != id2 || !strings.Contains(out, "Using cache") {
+		t.Fatalf("Build 2 should have worked & used cache(%s,%s): %v", id1, id2, err)
+	}
+
+	for _, dockerfile := range []string{
+		"FROM busybox AS one\nCOPY --from=one /bin/true /true",
+		"FROM busybox\nCOPY --from=1 /bin/true /true",
+		"FROM busybox\nFROM 0",
+		"FROM busybox AS one\nFROM busybox AS one",
+		"FROM busybox AS\n",
+	} {
+		if _, out, err := buildImageWithOut(name, dockerfile, true); err == nil {
+			t.Fatalf("Expected an error building %q: %s", dockerfile, out)
+		}
+	}
+	logDone("build - multi-stage")
+}
+
+func TestBuildStopSignal(t *testing.T) {
+	name := "testbuildstopsignal"
+	defer deleteImages(name)
+	defer deleteAllContainers()
+	_, err := buildImage(name,
+		`FROM busybox
+		 STOPSIGNAL SIGKILL`,
+		true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := inspectField(name, "Config.StopSignal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "SIGKILL" {
+		t.Fatalf("Expected the stop signal to be SIGKILL, got %s", res)
+	}
+
+	// The stop signal of the image is used by its containers
+	out, _, err := dockerCmd(t, "run", "-d", "--name", "buildstopsignal", name, "sh", "-c", "trap '' TERM; while true; do sleep 1; done")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	start := time.Now()
+	if out, _, err := dockerCmd(t, "stop", "-t", "60", "buildstopsignal"); err != nil {
+		t.Fatal(out, err)
+	}
+	if elapsed := time.Since(start); elapsed > 30*time.Second {
+		t.Fatalf("Expected the container to be killed right away, it took %v", elapsed)
+	}
+
+	if _, err := buildImage(name, "FROM busybox\nSTOPSIGNAL SIGFOO", true); err == nil {
+		t.Fatal("Expected an error with an invalid signal")
+	}
+	logDone("build - stopsignal")
+}
+
+func TestBuildSquash(t *testing.T) {
+	name := "testbuildsquash"
+	defer deleteImages(name)
+	ctx, err := fakeContext(`FROM busybox
+		COPY file /file
+		RUN cat /file > /copy
+		RUN rm /file
+		ENV FOO bar
+		CMD ["cat", "/copy"]`,
+		map[string]string{
+			"file": "squashed",
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.Close()
+
+	buildCmd := exec.Command(dockerBinary, "build", "--squash", "-t", name, ".")
+	buildCmd.Dir = ctx.Dir
+	if out, _, err := runCommandWithOutput(buildCmd); err != nil {
+		t.Fatalf("build failed to complete: %s, %v", out, err)
+	}
+
+	// The squashed image has a single layer on top of busybox
+	parent, err := inspectField(name, "Parent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	busybox, err := inspectField("busybox", "Id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent != busybox {
+		t.Fatalf("Expected the parent of the squashed image to be %s, got %s", busybox, parent)
+	}
+
+	// It keeps the config of the last step and the changes of every step
+	env, err := inspectFieldJSON(name, "Config.Env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(env, "FOO=bar") {
+		t.Fatalf("Expected the config of the build to be kept, got env %s", env)
+	}
+	out, _, err := dockerCmd(t, "run", "--rm", name)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if strings.TrimSpace(out) != "squashed" {
+		t.Fatalf("Expected the output of the command to be 'squashed', got %q", out)
+	}
+	out, _, err = dockerCmd(t, "run", "--rm", name, "ls", "/")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if strings.Contains(out, "file") {
+		t.Fatalf("Expected /file to be removed in the squashed image, got %s", out)
+	}
+	logDone("build - squash")
+}
+
+func TestBuildCacheFrom(t *testing.T) {
+	name := "testbuildcachefrom"
+	defer deleteImages(name, name+"2")
+	dockerfile := `FROM busybox
+		RUN echo cached > /cached
+		ENV FOO bar`
+	id, err := buildImage(name, dockerfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Load the image back as if it came from another machine
+	saveCmd := exec.Command("bash", "-c", fmt.Sprintf("%s save %s > /tmp/%s.tar", dockerBinary, name, name))
+	if out, _, err := runCommandWithOutput(saveCmd); err != nil {
+		t.Fatalf("failed to save the image: %s, %v", out, err)
+	}
+	defer os.Remove("/tmp/" + name + ".tar")
+	deleteImages(name)
+	loadCmd := exec.Command("bash", "-c", fmt.Sprintf("%s load < /tmp/%s.tar", dockerBinary, name))
+	if out, _, err := runCommandWithOutput(loadCmd); err != nil {
+		t.Fatalf("failed to load the image: %s, %v", out, err)
+	}
+
+	ctx, err := fakeContext(dockerfile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.Close()
+	buildCmd := exec.Command(dockerBinary, "build", "--cache-from", name+",doesnotexist", "-t", name+"2", ".")
+	buildCmd.Dir = ctx.Dir
+	out, _, err := runCommandWithOutput(buildCmd)
+	if err != nil {
+		t.Fatalf("build failed to complete: %s, %v", out, err)
+	}
+	if strings.Count(out, "Using cache") != 2 {
+		t.Fatalf("Expected the layers of the loaded image to be used as cache: %s", out)
+	}
+	if !strings.Contains(out, "Cannot use doesnotexist as a cache source") {
+		t.Fatalf("Expected a warning for a missing cache source: %s", out)
+	}
+	cachedID, err := getIDByName(name + "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cachedID != id {
+		t.Fatalf("Expected the build to produce %s, got %s", id, cachedID)
+	}
+
+	// Only the layers of the cache sources are used, not the ones which
+	// were built locally
+	buildCmd = exec.Command(dockerBinary, "build", "--cache-from", "busybox", "-t", name+"3", ".")
+	buildCmd.Dir = ctx.Dir
+	out, _, err = runCommandWithOutput(buildCmd)
+	if err != nil {
+		t.Fatalf("build failed to complete: %s, %v", out, err)
+	}
+	defer deleteImages(name + "3")
+	if strings.Contains(out, "Using cache") {
+		t.Fatalf("Expected only the layers of busybox to be used as cache: %s", out)
+	}
+	logDone("build - cache from a loaded image")
+}
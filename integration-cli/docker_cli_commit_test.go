@@ -202,3 +202,142 @@ func TestCommitWithHostBindMount(t *testing.T) {
 
 	logDone("commit - commit bind mounted file")
 }
+
+func TestCommitKeepsLabels(t *testing.T) {
+	cmd := exec.Command(dockerBinary, "run", "--name", "label-commit", "-l", "com.example.key=value", "busybox", "true")
+	if out, _, err := runCommandWithOutput(cmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	cmd = exec.Command(dockerBinary, "commit", "label-commit", "labeltest")
+	imageID, _, err := runCommandWithOutput(cmd)
+	if err != nil {
+		t.Fatal(imageID, err)
+	}
+	imageID = strings.Trim(imageID, "\r\n")
+	defer deleteImages(imageID)
+	defer deleteAllContainers()
+
+	res, err := inspectFieldJSON(imageID, "Config.Labels")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"com.example.key":"value"}`; res != expected {
+		t.Fatalf("Labels %s, expected %s", res, expected)
+	}
+
+	logDone("commit - commit keeps labels")
+}
+
+func TestCommitChange(t *testing.T) {
+	cmd := exec.Command(dockerBinary, "run", "--name", "test", "busybox", "true")
+	if out, _, err := runCommandWithOutput(cmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	cmd = exec.Command(dockerBinary, "commit",
+		"--change", "EXPOSE 8080",
+		"--change", "ENV DEBUG true",
+		"--change", "ENV test 1",
+		"--change", "WORKDIR /opt",
+		"--change", "CMD [\"/bin/sh\"]",
+		"--change", "USER testuser",
+		"--change", "VOLUME /data",
+		"--change", "ONBUILD RUN true",
+		"test", "test-commit")
+	imageID, _, err := runCommandWithOutput(cmd)
+	if err != nil {
+		t.Fatal(imageID, err)
+	}
+	imageID = strings.Trim(imageID, "\r\n")
+	defer deleteImages(imageID)
+	defer deleteAllContainers()
+
+	expected := map[string]string{
+		"Config.ExposedPorts": `{"8080/tcp":{}}`,
+		"Config.WorkingDir":   `"/opt"`,
+		"Config.Cmd":          `["/bin/sh"]`,
+		"Config.User":         `"testuser"`,
+		"Config.Volumes":      `{"/data":{}}`,
+		"Config.OnBuild":      `["RUN true"]`,
+	}
+	for field, value := range expected {
+		res, err := inspectFieldJSON(imageID, field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res != value {
+			t.Errorf("%s is %s, expected %s", field, res, value)
+		}
+	}
+
+	env, err := inspectFieldJSON(imageID, "Config.Env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(env, `"DEBUG=true","test=1"`) {
+		t.Errorf("Config.Env is %s, expected it to contain DEBUG=true and test=1", env)
+	}
+
+	logDone("commit - commit with --change")
+}
+
+func TestCommitChangeKeepsContainerConfig(t *testing.T) {
+	cmd := exec.Command(dockerBinary, "run", "--name", "test", "-e", "FOO=bar", "--entrypoint", "sh", "busybox", "-c", "true")
+	if out, _, err := runCommandWithOutput(cmd); err != nil {
+		t.Fatal(out, err)
+	}
+
+	cmd = exec.Command(dockerBinary, "commit", "--change", "ENV A b", "test", "test-commit")
+	imageID, _, err := runCommandWithOutput(cmd)
+	if err != nil {
+		t.Fatal(imageID, err)
+	}
+	imageID = strings.Trim(imageID, "\r\n")
+	defer deleteImages(imageID)
+	defer deleteAllContainers()
+
+	expected := map[string]string{
+		"Config.Entrypoint": `["sh"]`,
+		"Config.Cmd":        `["-c","true"]`,
+	}
+	for field, value := range expected {
+		res, err := inspectFieldJSON(imageID, field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res != value {
+			t.Errorf("%s is %s, expected %s", field, res, value)
+		}
+	}
+
+	env, err := inspectFieldJSON(imageID, "Config.Env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(env, `"FOO=bar"`) || !strings.Contains(env, `"A=b"`) {
+		t.Errorf("Config.Env is %s, expected it to contain FOO=bar and A=b", env)
+	}
+
+	logDone("commit - commit with --change keeps the config of the container")
+}
+
+func TestCommitInvalidChange(t *testing.T) {
+	cmd := exec.Command(dockerBinary, "run", "--name", "test", "busybox", "true")
+	if out, _, err := runCommandWithOutput(cmd); err != nil {
+		t.Fatal(out, err)
+	}
+	defer deleteAllContainers()
+
+	cmd = exec.Command(dockerBinary, "commit", "--change", "RUN true", "test", "test-commit")
+	out, _, err := runCommandWithOutput(cmd)
+	if err == nil {
+		deleteImages("test-commit")
+		t.Fatalf("commit with --change 'RUN true' should have failed: %s", out)
+	}
+	if !strings.Contains(out, "RUN is not a valid change command") {
+		t.Fatalf("unexpected error: %s", out)
+	}
+
+	logDone("commit - commit with an invalid --change")
+}
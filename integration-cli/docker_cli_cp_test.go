@@ -1,12 +1,14 @@
 package main
 
 import (
+	"archive/tar"
 	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -478,3 +480,192 @@ func TestCpVolumePath(t *testing.T) {
 
 	logDone("cp - volume path")
 }
+
+// Check that files are copied from the host to a directory of the container
+func TestCpToContainer(t *testing.T) {
+	out, exitCode, err := dockerCmd(t, "run", "-d", "-v", "/foo", "busybox", "top")
+	if err != nil || exitCode != 0 {
+		t.Fatal("failed to create a container", out, err)
+	}
+
+	cleanedContainerID := stripTrailingCharacters(out)
+	defer deleteContainer(cleanedContainerID)
+
+	tmpdir, err := ioutil.TempDir("", "docker-integration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	if err := ioutil.WriteFile(filepath.Join(tmpdir, cpTestName), []byte(cpHostContents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range []string{"/tmp", "/foo"} {
+		if out, _, err := dockerCmd(t, "cp", filepath.Join(tmpdir, cpTestName), cleanedContainerID+":"+dir); err != nil {
+			t.Fatalf("couldn't copy to %s: %s %s", dir, out, err)
+		}
+		out, _, err := dockerCmd(t, "exec", cleanedContainerID, "cat", filepath.Join(dir, cpTestName))
+		if err != nil {
+			t.Fatal(out, err)
+		}
+		if out != cpHostContents {
+			t.Errorf("output doesn't match the input for %s: %q", dir, out)
+		}
+	}
+
+	// A file of the container is not replaced by a directory
+	out, _, err = runCommandWithOutput(exec.Command(dockerBinary, "cp", tmpdir, cleanedContainerID+":/tmp/"+cpTestName))
+	if err == nil {
+		t.Fatalf("expected the copy of a directory over a file to fail: %s", out)
+	}
+
+	logDone("cp - from the host to a container")
+}
+
+// Check that, like cp, a destination which does not exist is the new name
+// of the copy, and that the copied files keep their owner
+func TestCpToContainerNewName(t *testing.T) {
+	out, exitCode, err := dockerCmd(t, "run", "-d", "-v", "/foo", "busybox", "top")
+	if err != nil || exitCode != 0 {
+		t.Fatal("failed to create a container", out, err)
+	}
+
+	cleanedContainerID := stripTrailingCharacters(out)
+	defer deleteContainer(cleanedContainerID)
+
+	tmpdir, err := ioutil.TempDir("", "docker-integration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	hostFile := filepath.Join(tmpdir, cpTestName)
+	if err := ioutil.WriteFile(hostFile, []byte(cpHostContents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chown(hostFile, 1234, 4321); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dst := range []string{"/tmp/renamed", "/foo/renamed"} {
+		if out, _, err := dockerCmd(t, "cp", hostFile, cleanedContainerID+":"+dst); err != nil {
+			t.Fatalf("couldn't copy to %s: %s %s", dst, out, err)
+		}
+		out, _, err := dockerCmd(t, "exec", cleanedContainerID, "cat", dst)
+		if err != nil {
+			t.Fatal(out, err)
+		}
+		if out != cpHostContents {
+			t.Errorf("output doesn't match the input for %s: %q", dst, out)
+		}
+		out, _, err = dockerCmd(t, "exec", cleanedContainerID, "stat", "-c", "%u:%g", dst)
+		if err != nil {
+			t.Fatal(out, err)
+		}
+		if owner := strings.TrimSpace(out); owner != "1234:4321" {
+			t.Errorf("expected %s to be owned by 1234:4321, got %s", dst, owner)
+		}
+	}
+
+	// The directory itself is copied under the new name
+	if out, _, err := dockerCmd(t, "cp", tmpdir, cleanedContainerID+":/tmp/renameddir"); err != nil {
+		t.Fatalf("couldn't copy the directory: %s %s", out, err)
+	}
+	if out, _, err := dockerCmd(t, "exec", cleanedContainerID, "cat", "/tmp/renameddir/"+cpTestName); err != nil || out != cpHostContents {
+		t.Fatalf("expected the directory to be copied to /tmp/renameddir: %s %v", out, err)
+	}
+
+	// The parent directory of the new name must exist
+	out, _, err = runCommandWithOutput(exec.Command(dockerBinary, "cp", hostFile, cleanedContainerID+":/missing/renamed"))
+	if err == nil {
+		t.Fatalf("expected the copy into a missing directory to fail: %s", out)
+	}
+
+	logDone("cp - from the host to a new name in a container")
+}
+
+// Check that symlinks of the container cannot make files copied to it
+// escape its rootfs
+func TestCpToContainerSymlinkEscape(t *testing.T) {
+	out, exitCode, err := dockerCmd(t, "run", "-d", "busybox", "/bin/sh", "-c", "ln -s / /escape && ln -s ../../../../.. /up && top")
+	if err != nil || exitCode != 0 {
+		t.Fatal("failed to create a container", out, err)
+	}
+
+	cleanedContainerID := stripTrailingCharacters(out)
+	defer deleteContainer(cleanedContainerID)
+
+	tmpdir, err := ioutil.TempDir("", "docker-integration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	name := "docker-cp-escape-" + filepath.Base(tmpdir)
+	if err := ioutil.WriteFile(filepath.Join(tmpdir, name), []byte(cpHostContents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, link := range []string{"/escape", "/up/tmp"} {
+		if out, _, err := dockerCmd(t, "cp", filepath.Join(tmpdir, name), cleanedContainerID+":"+link); err != nil {
+			t.Fatalf("couldn't copy to %s: %s %s", link, out, err)
+		}
+	}
+	for _, dir := range []string{"/", "/tmp"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			os.Remove(filepath.Join(dir, name))
+			t.Fatalf("copy through a symlink escaped the container rootfs to %s", dir)
+		}
+		if out, _, err := dockerCmd(t, "exec", cleanedContainerID, "cat", filepath.Join(dir, name)); err != nil || out != cpHostContents {
+			t.Fatalf("expected the file in %s of the container: %s %v", dir, out, err)
+		}
+	}
+
+	logDone("cp - symlinks of the container do not escape its rootfs")
+}
+
+// Check that tar archives are read from stdin and written to stdout
+func TestCpTarStreams(t *testing.T) {
+	out, exitCode, err := dockerCmd(t, "run", "-d", "busybox", "top")
+	if err != nil || exitCode != 0 {
+		t.Fatal("failed to create a container", out, err)
+	}
+
+	cleanedContainerID := stripTrailingCharacters(out)
+	defer deleteContainer(cleanedContainerID)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: cpTestName, Mode: 0644, Size: int64(len(cpHostContents))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte(cpHostContents)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cpCmd := exec.Command(dockerBinary, "cp", "-", cleanedContainerID+":/tmp")
+	cpCmd.Stdin = &buf
+	if out, _, err := runCommandWithOutput(cpCmd); err != nil {
+		t.Fatalf("couldn't copy from stdin: %s %s", out, err)
+	}
+
+	out, _, err = runCommandWithOutput(exec.Command(dockerBinary, "cp", cleanedContainerID+":/tmp/"+cpTestName, "-"))
+	if err != nil {
+		t.Fatalf("couldn't copy to stdout: %s %s", out, err)
+	}
+	tr := tar.NewReader(bytes.NewBufferString(out))
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadAll(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hdr.Name != cpTestName || string(content) != cpHostContents {
+		t.Fatalf("unexpected archive on stdout: %s %q", hdr.Name, content)
+	}
+
+	logDone("cp - tar archives on stdin and stdout")
+}
@@ -52,3 +52,42 @@ func TestExportContainerAndImportImage(t *testing.T) {
 	logDone("export - export a container")
 	logDone("import - import an image")
 }
+
+// export a container and import it with changes to the config of the image
+func TestExportContainerAndImportImageWithChanges(t *testing.T) {
+	runCmd := exec.Command(dockerBinary, "run", "-d", "busybox", "true")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatal("failed to create a container", out, err)
+	}
+
+	cleanedContainerID := stripTrailingCharacters(out)
+	defer deleteContainer(cleanedContainerID)
+
+	exportImportCmd := fmt.Sprintf(`%v export %v | %v import --change 'CMD ["/bin/sh"]' --change 'ENV DEBUG true' - repo/testexp:v1`, dockerBinary, cleanedContainerID, dockerBinary)
+	importCmd := exec.Command("bash", "-c", exportImportCmd)
+	out, _, err = runCommandWithOutput(importCmd)
+	if err != nil {
+		t.Fatalf("failed to import image: %s, %v", out, err)
+	}
+	defer deleteImages("repo/testexp:v1")
+
+	cleanedImageID := stripTrailingCharacters(out)
+
+	res, err := inspectFieldJSON(cleanedImageID, "Config.Cmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `["/bin/sh"]`; res != expected {
+		t.Fatalf("Config.Cmd is %s, expected %s", res, expected)
+	}
+	res, err = inspectFieldJSON(cleanedImageID, "Config.Env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `["DEBUG=true"]`; res != expected {
+		t.Fatalf("Config.Env is %s, expected %s", res, expected)
+	}
+
+	logDone("export - export a container and import it with changes")
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func waitForHealthStatus(t *testing.T, name, prev, expected string) {
+	for i := 0; i < 100; i++ {
+		status, err := inspectField(name, "State.Health.Status")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status == expected {
+			return
+		}
+		if status != prev {
+			t.Fatalf("Expected health status %s or %s, got %s", prev, expected, status)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for the health status %s of %s", expected, name)
+}
+
+func TestHealthRun(t *testing.T) {
+	defer deleteAllContainers()
+	out, _, err := dockerCmd(t, "run", "-d", "--name", "fatal_healthcheck",
+		"--health-interval=500ms", "--health-retries=2", "--health-cmd=cat /status",
+		"busybox", "sh", "-c", "echo OK > /status && top")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	id := strings.TrimSpace(out)
+
+	waitForHealthStatus(t, "fatal_healthcheck", "starting", "healthy")
+	out, _, err = dockerCmd(t, "ps", "-q", "--no-trunc", "--filter=health=healthy")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if strings.TrimSpace(out) != id {
+		t.Fatalf("Expected the container to be listed as healthy, got %q", out)
+	}
+
+	if out, _, err := dockerCmd(t, "exec", "fatal_healthcheck", "rm", "/status"); err != nil {
+		t.Fatal(out, err)
+	}
+	waitForHealthStatus(t, "fatal_healthcheck", "healthy", "unhealthy")
+
+	output, err := inspectField("fatal_healthcheck", "State.Health.Log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "No such file or directory") {
+		t.Fatalf("Expected the output of the failing probe in the log, got %s", output)
+	}
+
+	out, _, err = dockerCmd(t, "ps", "-q", "--filter=health=healthy")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if strings.TrimSpace(out) != "" {
+		t.Fatalf("Expected no healthy container, got %q", out)
+	}
+
+	logDone("health - run with a healthcheck")
+}
+
+func TestHealthBuild(t *testing.T) {
+	name := "testhealthbuild"
+	defer deleteImages(name)
+	defer deleteAllContainers()
+	_, err := buildImage(name, `FROM busybox
+		RUN echo OK > /status
+		CMD ["/bin/sleep", "120"]
+		HEALTHCHECK --interval=500ms --timeout=1s --retries=3 CMD cat /status`, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test, err := inspectFieldJSON(name, "Config.Healthcheck.Test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if test != `["CMD-SHELL","cat /status"]` {
+		t.Fatalf("Unexpected healthcheck test %s", test)
+	}
+
+	out, _, err := dockerCmd(t, "run", "-d", "--name", "buildhealth", name)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	waitForHealthStatus(t, "buildhealth", "starting", "healthy")
+
+	// Disabled in a child image
+	_, err = buildImage(name, "FROM "+name+"\nHEALTHCHECK NONE", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test, err = inspectFieldJSON(name, "Config.Healthcheck.Test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if test != `["NONE"]` {
+		t.Fatalf("Unexpected healthcheck test %s", test)
+	}
+	out, _, err = dockerCmd(t, "run", "-d", "--name", "nohealth", name)
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if health, err := inspectFieldJSON("nohealth", "State.Health"); err != nil || health != "null" {
+		t.Fatalf("Expected no health, got %s (%v)", health, err)
+	}
+
+	logDone("health - build an image with a healthcheck")
+}
@@ -335,3 +335,87 @@ func TestLogsFollowSlowStdoutConsumer(t *testing.T) {
 
 	logDone("logs - follow slow consumer")
 }
+
+func TestLogsNoneDriver(t *testing.T) {
+	runCmd := exec.Command(dockerBinary, "run", "-d", "--log-driver=none", "busybox", "echo", "hello")
+	out, _, _, err := runCommandWithStdoutStderr(runCmd)
+	if err != nil {
+		t.Fatalf("run failed with errors: %s, %v", out, err)
+	}
+
+	cleanedContainerID := stripTrailingCharacters(out)
+	exec.Command(dockerBinary, "wait", cleanedContainerID).Run()
+	defer deleteContainer(cleanedContainerID)
+
+	inspectCmd := exec.Command(dockerBinary, "inspect", "--format", "{{.HostConfig.LogConfig.Type}}", cleanedContainerID)
+	out, _, err = runCommandWithOutput(inspectCmd)
+	if err != nil {
+		t.Fatalf("failed to inspect container: %s, %v", out, err)
+	}
+	if logType := strings.TrimSpace(out); logType != "none" {
+		t.Fatalf("expected log driver none, got %q", logType)
+	}
+
+	logsCmd := exec.Command(dockerBinary, "logs", cleanedContainerID)
+	out, _, err = runCommandWithOutput(logsCmd)
+	if err == nil {
+		t.Fatalf("logs should fail for a container without logging, got: %s", out)
+	}
+	if !strings.Contains(out, "json-file") {
+		t.Fatalf("expected an error about the logging driver, got: %s", out)
+	}
+
+	logDone("logs - logs fail for containers using the none driver")
+}
+
+func TestLogsUnknownDriver(t *testing.T) {
+	runCmd := exec.Command(dockerBinary, "run", "--log-driver=invalid", "busybox", "true")
+	out, _, err := runCommandWithOutput(runCmd)
+	if err == nil {
+		t.Fatalf("run with an unknown log driver should fail, got: %s", out)
+	}
+	if !strings.Contains(out, "invalid") {
+		t.Fatalf("expected an error about the log driver, got: %s", out)
+	}
+
+	logDone("logs - run fails with an unknown log driver")
+}
+
+func TestLogsRotatedFiles(t *testing.T) {
+	runCmd := exec.Command(dockerBinary, "run", "-d", "--log-opt", "max-size=1k", "--log-opt", "max-file=3", "busybox", "sh", "-c", "for i in $(seq 1 500); do echo line$i; done")
+	out, _, _, err := runCommandWithStdoutStderr(runCmd)
+	if err != nil {
+		t.Fatalf("run failed with errors: %s, %v", out, err)
+	}
+
+	cleanedContainerID := stripTrailingCharacters(out)
+	exec.Command(dockerBinary, "wait", cleanedContainerID).Run()
+	defer deleteContainer(cleanedContainerID)
+
+	logsCmd := exec.Command(dockerBinary, "logs", cleanedContainerID)
+	out, _, _, err = runCommandWithStdoutStderr(logsCmd)
+	if err != nil {
+		t.Fatalf("failed to log container: %s, %v", out, err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) >= 500 {
+		t.Fatalf("expected old lines to be dropped by rotation, got %d lines", len(lines))
+	}
+	first := 500 - len(lines) + 1
+	for i, l := range lines {
+		if expected := fmt.Sprintf("line%d", first+i); l != expected {
+			t.Fatalf("expected %q, got %q", expected, l)
+		}
+	}
+
+	logsCmd = exec.Command(dockerBinary, "logs", "--tail", "3", cleanedContainerID)
+	out, _, _, err = runCommandWithStdoutStderr(logsCmd)
+	if err != nil {
+		t.Fatalf("failed to log container: %s, %v", out, err)
+	}
+	if out != "line498\nline499\nline500\n" {
+		t.Fatalf("unexpected tail of rotated logs: %q", out)
+	}
+
+	logDone("logs - logs read across rotated files")
+}
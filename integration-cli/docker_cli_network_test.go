@@ -0,0 +1,182 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestNetworkCreateLsRm(t *testing.T) {
+	out, _, err := dockerCmd(t, "network", "create", "--subnet", "10.211.0.0/24", "testnet")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	id := strings.TrimSpace(out)
+
+	out, _, err = dockerCmd(t, "network", "ls")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if !strings.Contains(out, "testnet") || !strings.Contains(out, "10.211.0.0/24") || !strings.Contains(out, "bridge") {
+		t.Fatalf("Expected the new and the default networks to be listed, got %s", out)
+	}
+
+	out, _, err = dockerCmd(t, "network", "inspect", "--format", "{{ .Options.Gateway }}", id[:12])
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if gw := strings.TrimSpace(out); gw != "10.211.0.1" {
+		t.Fatalf("Expected gateway 10.211.0.1, got %q", gw)
+	}
+
+	if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "network", "create", "testnet")); err == nil {
+		t.Fatalf("Expected an error creating a network with a name in use: %s", out)
+	}
+	for _, name := range []string{"bridge", "host", "none"} {
+		if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "network", "rm", name)); err == nil {
+			t.Fatalf("Expected an error removing the pre-defined network %s: %s", name, out)
+		}
+	}
+
+	if out, _, err := dockerCmd(t, "network", "rm", "testnet"); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "network", "inspect", "testnet")); err == nil {
+		t.Fatalf("Expected an error inspecting a removed network: %s", out)
+	}
+
+	logDone("network - create, list and remove a network")
+}
+
+func TestNetworkConnectDisconnect(t *testing.T) {
+	defer func() {
+		deleteAllContainers()
+		exec.Command(dockerBinary, "network", "rm", "frontnet", "backnet").Run()
+	}()
+
+	if out, _, err := dockerCmd(t, "network", "create", "--subnet", "10.212.0.0/24", "frontnet"); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, _, err := dockerCmd(t, "network", "create", "--subnet", "10.213.0.0/24", "backnet"); err != nil {
+		t.Fatal(out, err)
+	}
+
+	// A stopped container gets its interfaces when it starts
+	if out, _, err := dockerCmd(t, "create", "--name", "multinet", "busybox", "top"); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, _, err := dockerCmd(t, "network", "connect", "frontnet", "multinet"); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, _, err := dockerCmd(t, "start", "multinet"); err != nil {
+		t.Fatal(out, err)
+	}
+	// A running container gets its interface right away
+	if out, _, err := dockerCmd(t, "network", "connect", "backnet", "multinet"); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "network", "connect", "backnet", "multinet")); err == nil {
+		t.Fatalf("Expected an error connecting a container twice to a network: %s", out)
+	}
+
+	out, _, err := dockerCmd(t, "inspect", "--format", "{{ .NetworkSettings.Networks.frontnet.IPAddress }} {{ .NetworkSettings.Networks.backnet.IPAddress }}", "multinet")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if addrs := strings.TrimSpace(out); addrs != "10.212.0.2 10.213.0.2" {
+		t.Fatalf("Expected an address on both networks, got %q", addrs)
+	}
+
+	out, _, err = dockerCmd(t, "exec", "multinet", "ip", "-o", "-4", "addr", "show")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if !strings.Contains(out, "10.212.0.2/24") || !strings.Contains(out, "10.213.0.2/24") {
+		t.Fatalf("Expected the container to have interfaces on both networks, got %s", out)
+	}
+
+	if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "network", "rm", "frontnet")); err == nil {
+		t.Fatalf("Expected an error removing a network with connected containers: %s", out)
+	}
+
+	if out, _, err := dockerCmd(t, "network", "disconnect", "frontnet", "multinet"); err != nil {
+		t.Fatal(out, err)
+	}
+	out, _, err = dockerCmd(t, "exec", "multinet", "ip", "-o", "-4", "addr", "show")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if strings.Contains(out, "10.212.0.2/24") {
+		t.Fatalf("Expected the interface on the disconnected network to be removed, got %s", out)
+	}
+
+	logDone("network - connect and disconnect a container to several networks")
+}
+
+func TestNetworkCreateDriverOptions(t *testing.T) {
+	defer exec.Command(dockerBinary, "network", "rm", "gwnet", "nullnet").Run()
+
+	if out, _, err := dockerCmd(t, "network", "create", "--subnet", "10.215.0.0/24", "-o", "Gateway=10.215.0.254", "gwnet"); err != nil {
+		t.Fatal(out, err)
+	}
+	out, _, err := dockerCmd(t, "network", "inspect", "--format", "{{ .Driver }} {{ .Options.Gateway }}", "gwnet")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if res := strings.TrimSpace(out); res != "bridge 10.215.0.254" {
+		t.Fatalf("Expected a bridge network with gateway 10.215.0.254, got %q", res)
+	}
+
+	if out, _, err := dockerCmd(t, "network", "create", "-d", "null", "nullnet"); err != nil {
+		t.Fatal(out, err)
+	}
+	out, _, err = dockerCmd(t, "network", "inspect", "--format", "{{ .Driver }}", "nullnet")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if res := strings.TrimSpace(out); res != "null" {
+		t.Fatalf("Expected a network of the null driver, got %q", res)
+	}
+
+	if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "network", "create", "-d", "unknown", "badnet")); err == nil {
+		t.Fatalf("Expected an error creating a network with an unknown driver: %s", out)
+	}
+
+	logDone("network - create networks with a driver and options")
+}
+
+func TestNetworkIsolation(t *testing.T) {
+	defer func() {
+		deleteAllContainers()
+		exec.Command(dockerBinary, "network", "rm", "isolated").Run()
+	}()
+
+	if out, _, err := dockerCmd(t, "network", "create", "--subnet", "10.214.0.0/24", "isolated"); err != nil {
+		t.Fatal(out, err)
+	}
+
+	if out, _, err := dockerCmd(t, "run", "-d", "--name", "server", "busybox", "top"); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, _, err := dockerCmd(t, "network", "connect", "isolated", "server"); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, _, err := dockerCmd(t, "run", "-d", "--name", "member", "busybox", "top"); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, _, err := dockerCmd(t, "network", "connect", "isolated", "member"); err != nil {
+		t.Fatal(out, err)
+	}
+
+	// Members of the network reach each other on it
+	if out, _, err := dockerCmd(t, "exec", "member", "ping", "-c", "1", "-W", "2", "10.214.0.2"); err != nil {
+		t.Fatal(out, err)
+	}
+
+	// A container on the default bridge only cannot reach the network
+	if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "run", "busybox", "ping", "-c", "1", "-W", "2", "10.214.0.2")); err == nil {
+		t.Fatalf("Expected the network to be isolated from the default bridge: %s", out)
+	}
+
+	logDone("network - networks are isolated from each other")
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRenameRunningContainer(t *testing.T) {
+	defer deleteAllContainers()
+	out, _, err := dockerCmd(t, "run", "-d", "--name", "first_name", "busybox", "top")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	id := strings.TrimSpace(out)
+
+	if out, _, err := dockerCmd(t, "rename", "first_name", "new_name"); err != nil {
+		t.Fatal(out, err)
+	}
+	name, err := inspectField(id, "Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "/new_name" {
+		t.Fatalf("Expected the container to be named /new_name, got %s", name)
+	}
+	if _, err := inspectField("first_name", "Name"); err == nil {
+		t.Fatal("Expected the old name to be released")
+	}
+
+	logDone("rename - running container")
+}
+
+func TestRenameLinkedContainer(t *testing.T) {
+	defer deleteAllContainers()
+	if out, _, err := dockerCmd(t, "run", "-d", "--name", "rename_db", "busybox", "top"); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, _, err := dockerCmd(t, "run", "-d", "--name", "rename_web", "--link", "rename_db:db", "busybox", "top"); err != nil {
+		t.Fatal(out, err)
+	}
+
+	if out, _, err := dockerCmd(t, "rename", "rename_db", "renamed_db"); err != nil {
+		t.Fatal(out, err)
+	}
+	links, err := inspectField("rename_web", "HostConfig.Links")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if links != "[/renamed_db:/rename_web/db]" {
+		t.Fatalf("Expected the link to follow the renamed container, got %s", links)
+	}
+
+	// The hosts file of the parent is updated when the renamed container
+	// restarts
+	if out, _, err := dockerCmd(t, "restart", "renamed_db"); err != nil {
+		t.Fatal(out, err)
+	}
+	ip, err := inspectField("renamed_db", "NetworkSettings.IPAddress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _, err := dockerCmd(t, "exec", "rename_web", "cat", "/etc/hosts")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if !strings.Contains(out, ip+"\tdb\n") {
+		t.Fatalf("Expected the address %s of the link in /etc/hosts, got %s", ip, out)
+	}
+
+	logDone("rename - links to the renamed container")
+}
+
+func TestRenameConflict(t *testing.T) {
+	defer deleteAllContainers()
+	for _, name := range []string{"rename_first", "rename_second"} {
+		if out, _, err := dockerCmd(t, "run", "-d", "--name", name, "busybox", "top"); err != nil {
+			t.Fatal(out, err)
+		}
+	}
+
+	out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "rename", "rename_first", "rename_second"))
+	if err == nil || !strings.Contains(out, "Conflict") {
+		t.Fatalf("Expected the rename to a used name to fail, got %s", out)
+	}
+	name, err := inspectField("rename_first", "Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "/rename_first" {
+		t.Fatalf("Expected the container to keep its name, got %s", name)
+	}
+
+	logDone("rename - name already in use")
+}
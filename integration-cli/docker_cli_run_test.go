@@ -2701,3 +2701,102 @@ func TestRunTLSverify(t *testing.T) {
 
 	logDone("run - verify tls is set for --tlsverify")
 }
+
+func TestRunStopSignal(t *testing.T) {
+	defer deleteAllContainers()
+
+	out, _, err := dockerCmd(t, "run", "-d", "--name", "stopsignal", "--stop-signal=SIGKILL", "busybox", "sh", "-c", "trap '' TERM; while true; do sleep 1; done")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	sig, err := inspectField("stopsignal", "Config.StopSignal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig != "SIGKILL" {
+		t.Fatalf("Expected the stop signal to be SIGKILL, got %s", sig)
+	}
+
+	// The container ignores SIGTERM, so it only stops right away if it got SIGKILL
+	start := time.Now()
+	if out, _, err := dockerCmd(t, "stop", "-t", "60", "stopsignal"); err != nil {
+		t.Fatal(out, err)
+	}
+	if elapsed := time.Since(start); elapsed > 30*time.Second {
+		t.Fatalf("Expected the container to be killed right away, it took %v", elapsed)
+	}
+
+	out, _, err = runCommandWithOutput(exec.Command(dockerBinary, "run", "--stop-signal=SIGFOO", "busybox", "true"))
+	if err == nil || !strings.Contains(out, "Invalid signal: SIGFOO") {
+		t.Fatalf("Expected an error with an invalid signal, got %s", out)
+	}
+
+	logDone("run - stop a container with --stop-signal")
+}
+
+func TestRunWithUlimits(t *testing.T) {
+	defer deleteAllContainers()
+
+	out, _, err := dockerCmd(t, "run", "--name=testulimits", "--ulimit", "nofile=42", "busybox", "/bin/sh", "-c", "ulimit -n")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if ul := strings.TrimSpace(out); ul != "42" {
+		t.Fatalf("expected `ulimit -n` to be 42, got %s", ul)
+	}
+
+	ulimits, err := inspectFieldJSON("testulimits", "HostConfig.Ulimits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ulimits != `[{"Hard":42,"Name":"nofile","Soft":42}]` {
+		t.Fatalf("Unexpected ulimits %s", ulimits)
+	}
+
+	logDone("run - ulimits are set")
+}
+
+func TestRunWithOomScoreAdj(t *testing.T) {
+	defer deleteAllContainers()
+
+	out, _, err := dockerCmd(t, "run", "--oom-score-adj=200", "busybox", "cat", "/proc/self/oom_score_adj")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if score := strings.TrimSpace(out); score != "200" {
+		t.Fatalf("expected the OOM score adjustment to be 200, got %s", score)
+	}
+
+	out, _, err = runCommandWithOutput(exec.Command(dockerBinary, "run", "--oom-score-adj=1001", "busybox", "true"))
+	if err == nil || !strings.Contains(out, "range for oom score adj is [-1000, 1000]") {
+		t.Fatalf("expected an error with an invalid OOM score adjustment, got %s", out)
+	}
+
+	logDone("run - oom score adj is set")
+}
+
+func TestRunWithCpuQuota(t *testing.T) {
+	defer deleteAllContainers()
+
+	out, _, err := dockerCmd(t, "run", "--name=testcpuquota", "--cpu-quota=8000", "--cpu-period=100000", "busybox", "true")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	// The limits are discarded when the kernel does not support them
+	if !strings.Contains(out, "CPU cfs") {
+		quota, err := inspectField("testcpuquota", "Config.CpuQuota")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if quota != "8000" {
+			t.Fatalf("expected the CPU quota to be 8000, got %s", quota)
+		}
+	}
+
+	out, _, err = runCommandWithOutput(exec.Command(dockerBinary, "run", "--cpu-quota=10", "busybox", "true"))
+	if err == nil || !strings.Contains(out, "CPU cfs quota can not be less than 1ms") {
+		t.Fatalf("expected an error with a CPU quota under 1ms, got %s", out)
+	}
+
+	logDone("run - cpu quota and period are set")
+}
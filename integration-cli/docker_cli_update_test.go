@@ -0,0 +1,126 @@
+package main
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readCgroupFile reads a file of the cgroup of a running container for
+// subsystem, which is found through its init process.
+func readCgroupFile(t *testing.T, name, subsystem, file string) string {
+	pid, err := inspectField(name, "State.Pid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cgroups, err := ioutil.ReadFile(filepath.Join("/proc", pid, "cgroup"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(string(cgroups), "\n") {
+		// Lines are formatted as id:subsystems:path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		for _, s := range strings.Split(parts[1], ",") {
+			if s == subsystem {
+				content, err := ioutil.ReadFile(filepath.Join("/sys/fs/cgroup", subsystem, parts[2], file))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return strings.TrimSpace(string(content))
+			}
+		}
+	}
+	t.Fatalf("No %s cgroup found for %s", subsystem, name)
+	return ""
+}
+
+func TestUpdateRunningContainer(t *testing.T) {
+	defer deleteAllContainers()
+	out, _, err := dockerCmd(t, "run", "-d", "--name", "update_running", "-m", "64m", "busybox", "top")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+
+	out, _, err = dockerCmd(t, "update", "-m", "128m", "--cpu-shares", "512", "--restart", "on-failure:3", "update_running")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if strings.TrimSpace(out) != "update_running" {
+		t.Fatalf("Expected the name of the updated container, got %q", out)
+	}
+
+	for field, expected := range map[string]string{
+		"Config.Memory":            "134217728",
+		"Config.CpuShares":         "512",
+		"HostConfig.RestartPolicy": `{"MaximumRetryCount":3,"Name":"on-failure"}`,
+	} {
+		value, err := inspectFieldJSON("update_running", field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != expected {
+			t.Fatalf("Expected %s to be %s, got %s", field, expected, value)
+		}
+	}
+
+	// The limits are applied to the cgroups of the running container
+	for file, expected := range map[string]string{
+		"memory.limit_in_bytes": "134217728",
+		"cpu.shares":            "512",
+	} {
+		subsystem := strings.SplitN(file, ".", 2)[0]
+		if value := readCgroupFile(t, "update_running", subsystem, file); value != expected {
+			t.Fatalf("Expected %s to be %s, got %s", file, expected, value)
+		}
+	}
+
+	// The limits are kept when the container is restarted
+	if out, _, err := dockerCmd(t, "restart", "update_running"); err != nil {
+		t.Fatal(out, err)
+	}
+	memory, err := inspectFieldJSON("update_running", "Config.Memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if memory != "134217728" {
+		t.Fatalf("Expected the memory limit to be kept after a restart, got %s", memory)
+	}
+
+	logDone("update - resource limits of a running container")
+}
+
+func TestUpdateInvalidLimits(t *testing.T) {
+	defer deleteAllContainers()
+	out, _, err := dockerCmd(t, "run", "-d", "--name", "update_invalid", "busybox", "top")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+
+	out, _, err = runCommandWithOutput(exec.Command(dockerBinary, "update", "-m", "1m", "update_invalid"))
+	if err == nil || !strings.Contains(out, "Minimum memory limit allowed is 4MB") {
+		t.Fatalf("Expected the update to fail with a too low memory limit, got %s", out)
+	}
+	out, _, err = runCommandWithOutput(exec.Command(dockerBinary, "update", "--kernel-memory", "32m", "update_invalid"))
+	if err == nil || !strings.Contains(out, "cannot be updated") {
+		t.Fatalf("Expected the update of the kernel memory of a running container to fail, got %s", out)
+	}
+
+	out, _, err = runCommandWithOutput(exec.Command(dockerBinary, "update", "--restart", "on-failure:-1", "update_invalid"))
+	if err == nil || !strings.Contains(out, "maximum restart count must be a positive integer") {
+		t.Fatalf("Expected the update to fail with a negative retry count, got %s", out)
+	}
+	policy, err := inspectFieldJSON("update_invalid", "HostConfig.RestartPolicy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy != `{"MaximumRetryCount":0,"Name":""}` {
+		t.Fatalf("Expected the restart policy to be unchanged, got %s", policy)
+	}
+
+	logDone("update - invalid limits are rejected")
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// startVolumePlugin starts a stand-in volume driver plugin called name
+// which stores its volumes in a temporary directory, and returns the
+// directory and the list of calls it received.
+func startVolumePlugin(t *testing.T, name string) (string, *[]string, func()) {
+	root, err := ioutil.TempDir("", "docker-test-volume-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll("/run/docker/plugins", 0755); err != nil {
+		t.Fatal(err)
+	}
+	socket := filepath.Join("/run/docker/plugins", name+".sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/Plugin.Activate", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"Implements": ["VolumeDriver"]}`)
+	})
+	for _, method := range []string{"Create", "Remove", "Path", "Mount", "Unmount"} {
+		method := method
+		mux.HandleFunc("/VolumeDriver."+method, func(w http.ResponseWriter, r *http.Request) {
+			var req struct{ Name string }
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			mu.Lock()
+			calls = append(calls, method+" "+req.Name)
+			mu.Unlock()
+
+			path := filepath.Join(root, req.Name)
+			resp := map[string]string{}
+			switch method {
+			case "Create":
+				if err := os.MkdirAll(path, 0755); err != nil {
+					resp["Err"] = err.Error()
+				}
+			case "Remove":
+				if err := os.RemoveAll(path); err != nil {
+					resp["Err"] = err.Error()
+				}
+			case "Path", "Mount":
+				resp["Mountpoint"] = path
+			}
+			json.NewEncoder(w).Encode(resp)
+		})
+	}
+	go http.Serve(l, mux)
+
+	return root, &calls, func() {
+		l.Close()
+		os.Remove(socket)
+		os.RemoveAll(root)
+	}
+}
+
+func TestVolumeDriverNamedVolume(t *testing.T) {
+	root, calls, cleanup := startVolumePlugin(t, "test-external-volume-driver")
+	defer cleanup()
+	defer deleteAllContainers()
+
+	out, _, err := dockerCmd(t, "run", "--rm", "--volume-driver", "test-external-volume-driver", "-v", "external-volume-test:/tmp/external-volume-test", "busybox", "sh", "-c", "echo hello > /tmp/external-volume-test/test")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(root, "external-volume-test", "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(content)) != "hello" {
+		t.Fatalf("Expected the data to be written to the plugin's volume, got %q", content)
+	}
+
+	out, _, err = dockerCmd(t, "volume", "inspect", "--format", "{{ .Driver }}", "external-volume-test")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if strings.TrimSpace(out) != "test-external-volume-driver" {
+		t.Fatalf("Expected the volume to use the plugin, got driver %q", out)
+	}
+
+	if out, _, err := dockerCmd(t, "volume", "rm", "external-volume-test"); err != nil {
+		t.Fatal(out, err)
+	}
+
+	expected := []string{
+		"Create external-volume-test",
+		"Path external-volume-test",
+		"Mount external-volume-test",
+		"Unmount external-volume-test",
+		"Remove external-volume-test",
+	}
+	if fmt.Sprint(*calls) != fmt.Sprint(expected) {
+		t.Fatalf("Expected calls %v to the plugin, got %v", expected, *calls)
+	}
+
+	logDone("volume driver - run with a named volume of a volume driver plugin")
+}
+
+func TestVolumeDriverMissing(t *testing.T) {
+	defer deleteAllContainers()
+
+	out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "run", "--volume-driver", "no-such-volume-driver", "-v", "missing-driver-volume:/data", "busybox", "true"))
+	if err == nil || !strings.Contains(out, "No such volume driver") {
+		t.Fatalf("Expected an error running with a missing volume driver, got %s (%v)", out, err)
+	}
+
+	logDone("volume driver - run with a missing volume driver")
+}
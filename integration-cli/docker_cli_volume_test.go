@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestVolumeCreateInspectRm(t *testing.T) {
+	out, _, err := dockerCmd(t, "volume", "create", "--name", "testvolume")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if name := strings.TrimSpace(out); name != "testvolume" {
+		t.Fatalf("Expected volume name testvolume, got %q", name)
+	}
+
+	out, _, err = dockerCmd(t, "volume", "inspect", "--format", "{{ .Name }}", "testvolume")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if name := strings.TrimSpace(out); name != "testvolume" {
+		t.Fatalf("Expected volume name testvolume, got %q", name)
+	}
+
+	if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "volume", "create", "--name", "testvolume")); err == nil {
+		t.Fatalf("Expected an error creating a volume with a name in use: %s", out)
+	}
+
+	if out, _, err := dockerCmd(t, "volume", "rm", "testvolume"); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "volume", "inspect", "testvolume")); err == nil {
+		t.Fatalf("Expected an error inspecting a removed volume: %s", out)
+	}
+
+	logDone("volume - create, inspect and remove a named volume")
+}
+
+func TestVolumeNamedVolumeSharedBetweenContainers(t *testing.T) {
+	defer deleteAllContainers()
+
+	if out, _, err := dockerCmd(t, "run", "-v", "shared:/data", "busybox", "sh", "-c", "echo hello > /data/file"); err != nil {
+		t.Fatal(out, err)
+	}
+	out, _, err := dockerCmd(t, "run", "-v", "shared:/other:ro", "busybox", "cat", "/other/file")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if strings.TrimSpace(out) != "hello" {
+		t.Fatalf("Expected the named volume to be shared, got %q", out)
+	}
+
+	// The volume is in use, so it can not be removed
+	if out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "volume", "rm", "shared")); err == nil {
+		t.Fatalf("Expected an error removing a volume in use: %s", out)
+	}
+
+	// Named volumes are not removed along with the containers using them
+	if err := deleteAllContainers(); err != nil {
+		t.Fatal(err)
+	}
+	if out, _, err := dockerCmd(t, "volume", "inspect", "shared"); err != nil {
+		t.Fatal(out, err)
+	}
+	if out, _, err := dockerCmd(t, "volume", "rm", "shared"); err != nil {
+		t.Fatal(out, err)
+	}
+
+	logDone("volume - named volume shared between containers")
+}
+
+func TestVolumeNamedVolumeCopiesImageContent(t *testing.T) {
+	defer func() {
+		deleteAllContainers()
+		exec.Command(dockerBinary, "volume", "rm", "e").Run()
+	}()
+
+	// An empty named volume gets the content of the image
+	out, _, err := dockerCmd(t, "run", "-v", "e:/etc", "busybox", "sh", "-c", "cat /etc/passwd && rm /etc/passwd && touch /etc/new")
+	if err != nil || !strings.Contains(out, "root:") {
+		t.Fatalf("Expected the content of the image in the volume, got %q: %v", out, err)
+	}
+	// Once used, it keeps its own content
+	out, _, err = dockerCmd(t, "run", "-v", "e:/etc", "busybox", "ls", "/etc")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if strings.Contains(out, "passwd") || !strings.Contains(out, "new") {
+		t.Fatalf("Expected the volume to keep its content, got %q", out)
+	}
+
+	logDone("volume - named volume gets the content of the image")
+}
+
+func TestVolumeLsDanglingFilter(t *testing.T) {
+	defer func() {
+		deleteAllContainers()
+		exec.Command(dockerBinary, "volume", "rm", "testinuse", "testdangling").Run()
+	}()
+
+	for _, name := range []string{"testinuse", "testdangling"} {
+		if out, _, err := dockerCmd(t, "volume", "create", "--name", name); err != nil {
+			t.Fatal(out, err)
+		}
+	}
+	if out, _, err := dockerCmd(t, "run", "-v", "testinuse:/data", "busybox", "true"); err != nil {
+		t.Fatal(out, err)
+	}
+
+	out, _, err := dockerCmd(t, "volume", "ls", "-q", "--filter", "dangling=true")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if !strings.Contains(out, "testdangling\n") || strings.Contains(out, "testinuse\n") {
+		t.Fatalf("Expected only the dangling volume to be listed, got %q", out)
+	}
+
+	out, _, err = dockerCmd(t, "volume", "ls", "-q", "--filter", "dangling=false")
+	if err != nil {
+		t.Fatal(out, err)
+	}
+	if strings.Contains(out, "testdangling\n") || !strings.Contains(out, "testinuse\n") {
+		t.Fatalf("Expected only the volume in use to be listed, got %q", out)
+	}
+
+	logDone("volume - list volumes with the dangling filter")
+}
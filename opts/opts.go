@@ -47,6 +47,10 @@ func LabelListVar(values *[]string, names []string, usage string) {
 	flag.Var(newListOptsRef(values, ValidateLabel), names, usage)
 }
 
+func LogOptsVar(values map[string]string, names []string, usage string) {
+	flag.Var(NewMapOpts(values, ValidateLogOpt), names, usage)
+}
+
 // ListOpts type
 type ListOpts struct {
 	values    *[]string
@@ -125,6 +129,50 @@ func (opts *ListOpts) Len() int {
 	return len((*opts.values))
 }
 
+// MapOpts type
+type MapOpts struct {
+	values    map[string]string
+	validator ValidatorFctType
+}
+
+func NewMapOpts(values map[string]string, validator ValidatorFctType) *MapOpts {
+	if values == nil {
+		values = make(map[string]string)
+	}
+	return &MapOpts{
+		values:    values,
+		validator: validator,
+	}
+}
+
+// Set validates if needed the input value and adds the key=value pair it
+// holds to the internal map.
+func (opts *MapOpts) Set(value string) error {
+	if opts.validator != nil {
+		v, err := opts.validator(value)
+		if err != nil {
+			return err
+		}
+		value = v
+	}
+	vals := strings.SplitN(value, "=", 2)
+	if len(vals) == 1 {
+		(opts.values)[vals[0]] = ""
+	} else {
+		(opts.values)[vals[0]] = vals[1]
+	}
+	return nil
+}
+
+func (opts *MapOpts) String() string {
+	return fmt.Sprintf("%v", map[string]string((opts.values)))
+}
+
+// GetAll returns the values' map.
+func (opts *MapOpts) GetAll() map[string]string {
+	return opts.values
+}
+
 // Validators
 type ValidatorFctType func(val string) (string, error)
 
@@ -232,6 +280,14 @@ func ValidateMirror(val string) (string, error) {
 	return fmt.Sprintf("%s://%s/v1/", uri.Scheme, uri.Host), nil
 }
 
+// ValidateLogOpt checks that a log driver option has the key=value form
+func ValidateLogOpt(val string) (string, error) {
+	if strings.Count(val, "=") < 1 {
+		return "", fmt.Errorf("bad log opt format: %s", val)
+	}
+	return val, nil
+}
+
 func ValidateLabel(val string) (string, error) {
 	if strings.Count(val, "=") != 1 {
 		return "", fmt.Errorf("bad attribute format: %s", val)
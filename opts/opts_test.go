@@ -2,6 +2,8 @@ package opts
 
 import (
 	"testing"
+
+	"github.com/docker/docker/pkg/ulimit"
 )
 
 func TestValidateIPAddress(t *testing.T) {
@@ -33,6 +35,25 @@ func TestListOpts(t *testing.T) {
 	o.String()
 }
 
+func TestMapOpts(t *testing.T) {
+	tmpMap := make(map[string]string)
+	o := NewMapOpts(tmpMap, ValidateLogOpt)
+	o.Set("max-size=1")
+	if o.String() != "map[max-size:1]" {
+		t.Errorf("%s != [map[max-size:1]", o.String())
+	}
+	o.Set("max-file=2=3")
+	if tmpMap["max-file"] != "2=3" {
+		t.Errorf("max-file = %s != 2=3", tmpMap["max-file"])
+	}
+	if err := o.Set("dummy"); err == nil {
+		t.Errorf("expected an error for an option without a value")
+	}
+	if len(tmpMap) != 2 {
+		t.Errorf("expected 2 options, got %v", tmpMap)
+	}
+}
+
 func TestValidateDnsSearch(t *testing.T) {
 	valid := []string{
 		`.`,
@@ -88,3 +109,28 @@ func TestValidateDnsSearch(t *testing.T) {
 		}
 	}
 }
+
+func TestUlimitOpt(t *testing.T) {
+	ulimits := map[string]*ulimit.Ulimit{
+		"nofile": {Name: "nofile", Soft: 512, Hard: 1024},
+	}
+	o := NewUlimitOpt(ulimits)
+	if o.String() != "[nofile=512:1024]" {
+		t.Fatalf("Expected [nofile=512:1024], got %s", o.String())
+	}
+
+	// Setting a limit again overrides it
+	if err := o.Set("nofile=2048:4096"); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Set("core=0"); err != nil {
+		t.Fatal(err)
+	}
+	if o.String() != "[core=0:0 nofile=2048:4096]" {
+		t.Fatalf("Expected [core=0:0 nofile=2048:4096], got %s", o.String())
+	}
+
+	if err := o.Set("nofile=2048:1024"); err == nil {
+		t.Fatal("Expected an error with a soft limit greater than the hard one")
+	}
+}
@@ -0,0 +1,57 @@
+package opts
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/docker/docker/pkg/ulimit"
+)
+
+// UlimitOpt holds the limits given with --ulimit or --default-ulimit, by
+// name. A limit given twice keeps the last value.
+type UlimitOpt struct {
+	values map[string]*ulimit.Ulimit
+}
+
+func NewUlimitOpt(ref map[string]*ulimit.Ulimit) *UlimitOpt {
+	if ref == nil {
+		ref = make(map[string]*ulimit.Ulimit)
+	}
+	return &UlimitOpt{ref}
+}
+
+func (o *UlimitOpt) Set(val string) error {
+	l, err := ulimit.Parse(val)
+	if err != nil {
+		return err
+	}
+
+	o.values[l.Name] = l
+
+	return nil
+}
+
+func (o *UlimitOpt) String() string {
+	var out []string
+	for _, v := range o.GetList() {
+		out = append(out, v.String())
+	}
+
+	return fmt.Sprintf("%v", out)
+}
+
+// GetList returns the limits sorted by name.
+func (o *UlimitOpt) GetList() []*ulimit.Ulimit {
+	var names []string
+	for name := range o.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var ulimits []*ulimit.Ulimit
+	for _, name := range names {
+		ulimits = append(ulimits, o.values[name])
+	}
+
+	return ulimits
+}
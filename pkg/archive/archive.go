@@ -35,6 +35,9 @@ type (
 		Compression Compression
 		NoLchown    bool
 		Name        string
+		// NoOverwriteDirNonDir makes Untar fail rather than replace an
+		// existing directory with a non-directory or the opposite
+		NoOverwriteDirNonDir bool
 	}
 
 	// Archiver allows the reuse of most utility functions of this package
@@ -380,6 +383,11 @@ func escapeName(name string) string {
 // TarWithOptions creates an archive from the directory at `path`, only including files whose relative
 // paths are included in `options.Includes` (if non-nil) or not in `options.Excludes`.
 func TarWithOptions(srcPath string, options *TarOptions) (io.ReadCloser, error) {
+	pm, err := fileutils.NewPatternMatcher(options.Excludes)
+	if err != nil {
+		return nil, err
+	}
+
 	pipeReader, pipeWriter := io.Pipe()
 
 	compressWriter, err := CompressStream(pipeWriter, options.Compression)
@@ -420,14 +428,9 @@ func TarWithOptions(srcPath string, options *TarOptions) (io.ReadCloser, error)
 					return nil
 				}
 
-				skip, err := fileutils.Matches(relFilePath, options.Excludes)
-				if err != nil {
-					log.Debugf("Error matching %s", relFilePath, err)
-					return err
-				}
-
-				if skip {
-					if f.IsDir() {
+				if pm.Matches(relFilePath) {
+					// Files of an excluded directory can be included back
+					if f.IsDir() && !pm.HasExceptions() {
 						return filepath.SkipDir
 					}
 					return nil
@@ -544,6 +547,9 @@ loop:
 			if fi.IsDir() && hdr.Name == "." {
 				continue
 			}
+			if options.NoOverwriteDirNonDir && fi.IsDir() != (hdr.Typeflag == tar.TypeDir) {
+				return fmt.Errorf("Cannot overwrite %s with %s, only one of them is a directory", path, hdr.Name)
+			}
 			if !(fi.IsDir() && hdr.Typeflag == tar.TypeDir) {
 				if err := os.RemoveAll(path); err != nil {
 					return err
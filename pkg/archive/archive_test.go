@@ -210,6 +210,44 @@ func TestTarWithOptions(t *testing.T) {
 	}
 }
 
+func TestUntarNoOverwriteDirNonDir(t *testing.T) {
+	origin, err := ioutil.TempDir("", "docker-test-untar-origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(origin)
+	if err := ioutil.WriteFile(path.Join(origin, "1"), []byte("hello world"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	dest, err := ioutil.TempDir("", "docker-test-untar-dest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dest)
+	if err := os.Mkdir(path.Join(dest, "1"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, noOverwrite := range []bool{true, false} {
+		archive, err := TarWithOptions(origin, &TarOptions{Includes: []string{"1"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = Untar(archive, dest, &TarOptions{NoOverwriteDirNonDir: noOverwrite})
+		archive.Close()
+		if noOverwrite && err == nil {
+			t.Fatal("Expected an error when replacing a directory with a file")
+		}
+		if !noOverwrite && err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fi, err := os.Lstat(path.Join(dest, "1")); err != nil || !fi.Mode().IsRegular() {
+		t.Fatalf("Expected the directory to be replaced with a file: %v", err)
+	}
+}
+
 // Some tar archives such as http://haproxy.1wt.eu/download/1.5/src/devel/haproxy-1.5-dev21.tar.gz
 // use PAX Global Extended Headers.
 // Failing prevents the archives from being uncompressed during ADD
@@ -0,0 +1,235 @@
+// Package dnsserver implements a small DNS server answering A and AAAA
+// queries for a set of local names and forwarding every other query to
+// upstream servers.
+package dnsserver
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	headerLen = 12
+
+	typeA    = 1
+	typeAAAA = 28
+	typeANY  = 255
+	classIN  = 1
+
+	rcodeServFail = 2
+
+	// maxUDPLen is the size of the messages clients accept without EDNS.
+	maxUDPLen = 512
+	// ForwardTimeout is how long an upstream server has to answer.
+	ForwardTimeout = 2 * time.Second
+)
+
+var errInvalidQuery = errors.New("invalid DNS query")
+
+// ResolveFunc returns the addresses of name for the client, or nil to
+// forward the query upstream. Names are given without their trailing dot.
+type ResolveFunc func(client net.IP, name string) []net.IP
+
+// UpstreamFunc returns the servers queries are forwarded to, as IP or
+// IP:port.
+type UpstreamFunc func() []string
+
+type Server struct {
+	conn     *net.UDPConn
+	resolve  ResolveFunc
+	upstream UpstreamFunc
+}
+
+// New listens on the UDP address addr. Queries are answered by Serve.
+func New(addr string, resolve ResolveFunc, upstream UpstreamFunc) (*Server, error) {
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		conn:     conn,
+		resolve:  resolve,
+		upstream: upstream,
+	}, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() *net.UDPAddr {
+	return s.conn.LocalAddr().(*net.UDPAddr)
+}
+
+// Serve answers queries until the server is closed.
+func (s *Server) Serve() error {
+	for {
+		buf := make([]byte, maxUDPLen)
+		n, from, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			if strings.Contains(err.Error(), "use of closed network connection") {
+				return nil
+			}
+			return err
+		}
+		go s.handle(buf[:n], from)
+	}
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	return s.conn.Close()
+}
+
+func (s *Server) handle(query []byte, from *net.UDPAddr) {
+	reply, err := s.answer(query, from.IP)
+	if err == errInvalidQuery {
+		return
+	}
+	if reply == nil {
+		if reply, err = s.forward(query); err != nil {
+			log.Debugf("Failed to forward DNS query from %s: %s", from, err)
+			reply = failure(query)
+		}
+	}
+	if _, err := s.conn.WriteToUDP(reply, from); err != nil {
+		log.Debugf("Failed to answer DNS query from %s: %s", from, err)
+	}
+}
+
+// answer builds the reply to a query for a local name. It returns a nil
+// reply for queries which must be forwarded.
+func (s *Server) answer(query []byte, client net.IP) ([]byte, error) {
+	if len(query) < headerLen || query[2]&0x80 != 0 {
+		return nil, errInvalidQuery
+	}
+	// Only standard queries with a single question are answered locally
+	if query[2]&0x78 != 0 || binary.BigEndian.Uint16(query[4:]) != 1 {
+		return nil, nil
+	}
+	name, end, err := parseName(query, headerLen)
+	if err != nil || end+4 > len(query) {
+		return nil, errInvalidQuery
+	}
+	qtype := binary.BigEndian.Uint16(query[end:])
+	qclass := binary.BigEndian.Uint16(query[end+2:])
+	end += 4
+
+	ips := s.resolve(client, name)
+	if len(ips) == 0 || qclass != classIN {
+		return nil, nil
+	}
+
+	reply := make([]byte, end, maxUDPLen)
+	copy(reply, query[:end])
+	// QR, AA and RA are set, RD is copied from the query
+	reply[2] = 0x84 | query[2]&0x01
+	reply[3] = 0x80
+	binary.BigEndian.PutUint16(reply[8:], 0)
+	binary.BigEndian.PutUint16(reply[10:], 0)
+
+	var count uint16
+	for _, ip := range ips {
+		rtype, data := uint16(typeA), ip.To4()
+		if data == nil {
+			rtype, data = typeAAAA, ip.To16()
+		}
+		if data == nil || (qtype != rtype && qtype != typeANY) || len(reply)+12+len(data) > maxUDPLen {
+			continue
+		}
+		record := make([]byte, 12, 12+len(data))
+		// The name is a pointer to the question
+		binary.BigEndian.PutUint16(record[0:], 0xc000|headerLen)
+		binary.BigEndian.PutUint16(record[2:], rtype)
+		binary.BigEndian.PutUint16(record[4:], classIN)
+		// A TTL of 0 keeps clients from caching addresses which change
+		// when containers restart
+		binary.BigEndian.PutUint32(record[6:], 0)
+		binary.BigEndian.PutUint16(record[10:], uint16(len(data)))
+		reply = append(append(reply, record...), data...)
+		count++
+	}
+	binary.BigEndian.PutUint16(reply[6:], count)
+	return reply, nil
+}
+
+func (s *Server) forward(query []byte) ([]byte, error) {
+	var err error
+	servers := s.upstream()
+	if len(servers) == 0 {
+		return nil, errors.New("no upstream DNS server")
+	}
+	for _, server := range servers {
+		if _, _, e := net.SplitHostPort(server); e != nil {
+			server = net.JoinHostPort(server, "53")
+		}
+		var reply []byte
+		if reply, err = exchange(server, query); err == nil {
+			return reply, nil
+		}
+	}
+	return nil, err
+}
+
+func exchange(server string, query []byte) ([]byte, error) {
+	conn, err := net.DialTimeout("udp", server, ForwardTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(ForwardTimeout))
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		// Drop replies which do not match the ID of the query
+		if n >= headerLen && buf[0] == query[0] && buf[1] == query[1] {
+			return buf[:n], nil
+		}
+	}
+}
+
+// failure builds a SERVFAIL reply echoing the header of the query.
+func failure(query []byte) []byte {
+	reply := make([]byte, headerLen)
+	copy(reply, query[:headerLen])
+	reply[2] = 0x80 | query[2]&0x79
+	reply[3] = 0x80 | rcodeServFail
+	for i := 4; i < headerLen; i++ {
+		reply[i] = 0
+	}
+	return reply
+}
+
+// parseName reads the uncompressed name at offset off of msg and returns it
+// with the offset following it.
+func parseName(msg []byte, off int) (string, int, error) {
+	var labels []string
+	for {
+		if off >= len(msg) {
+			return "", 0, errInvalidQuery
+		}
+		l := int(msg[off])
+		off++
+		if l == 0 {
+			break
+		}
+		if l&0xc0 != 0 || off+l > len(msg) {
+			return "", 0, errInvalidQuery
+		}
+		labels = append(labels, string(msg[off:off+l]))
+		off += l
+	}
+	return strings.Join(labels, "."), off, nil
+}
@@ -0,0 +1,137 @@
+package dnsserver
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newQuery(name string, qtype uint16) []byte {
+	query := []byte{0x12, 0x34, 0x01, 0x00, 0, 1, 0, 0, 0, 0, 0, 0}
+	for _, label := range strings.Split(name, ".") {
+		query = append(query, byte(len(label)))
+		query = append(query, label...)
+	}
+	query = append(query, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint16(query[len(query)-4:], qtype)
+	binary.BigEndian.PutUint16(query[len(query)-2:], classIN)
+	return query
+}
+
+func exchangeWith(t *testing.T, s *Server, name string, qtype uint16) []byte {
+	conn, err := net.DialUDP("udp", nil, s.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(3 * ForwardTimeout))
+	if _, err := conn.Write(newQuery(name, qtype)); err != nil {
+		t.Fatal(err)
+	}
+	reply := make([]byte, maxUDPLen)
+	n, err := conn.Read(reply)
+	if err != nil {
+		t.Fatalf("Query for %s failed: %s", name, err)
+	}
+	return reply[:n]
+}
+
+// answers returns the addresses of the records of a reply to newQuery.
+func answers(t *testing.T, reply []byte) []string {
+	_, off, err := parseName(reply, headerLen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	off += 4
+	var ips []string
+	for i := 0; i < int(binary.BigEndian.Uint16(reply[6:])); i++ {
+		l := int(binary.BigEndian.Uint16(reply[off+10:]))
+		ips = append(ips, net.IP(reply[off+12:off+12+l]).String())
+		off += 12 + l
+	}
+	return ips
+}
+
+func startServer(t *testing.T, names map[string][]net.IP, upstream ...string) *Server {
+	resolve := func(client net.IP, name string) []net.IP {
+		if !client.IsLoopback() {
+			t.Errorf("Unexpected client %s", client)
+		}
+		return names[name]
+	}
+	s, err := New("127.0.0.1:0", resolve, func() []string { return upstream })
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve()
+	return s
+}
+
+func TestAnswerLocalNames(t *testing.T) {
+	s := startServer(t, map[string][]net.IP{
+		"db":     {net.ParseIP("172.17.0.5"), net.ParseIP("2001:db8::5")},
+		"WebApp": {net.ParseIP("172.17.0.6")},
+	})
+	defer s.Close()
+
+	reply := exchangeWith(t, s, "db", typeA)
+	if reply[3]&0x0f != 0 || reply[2]&0x04 == 0 {
+		t.Fatalf("Expected an authoritative answer, got flags %x", reply[2:4])
+	}
+	if ips := answers(t, reply); len(ips) != 1 || ips[0] != "172.17.0.5" {
+		t.Fatalf("Unexpected A records %v", ips)
+	}
+	if ips := answers(t, exchangeWith(t, s, "db", typeAAAA)); len(ips) != 1 || ips[0] != "2001:db8::5" {
+		t.Fatalf("Unexpected AAAA records %v", ips)
+	}
+	if ips := answers(t, exchangeWith(t, s, "db", typeANY)); len(ips) != 2 {
+		t.Fatalf("Expected both addresses, got %v", ips)
+	}
+
+	// The name is resolved as queried and the question of the reply keeps
+	// its case
+	reply = exchangeWith(t, s, "WebApp", typeA)
+	if ips := answers(t, reply); len(ips) != 1 || ips[0] != "172.17.0.6" {
+		t.Fatalf("Unexpected A records for a mixed case name %v", ips)
+	}
+	if !strings.Contains(string(reply[headerLen:]), "WebApp") {
+		t.Fatalf("Expected the question of the query in the reply, got %q", reply[headerLen:])
+	}
+
+	// Without upstream servers other names fail
+	if reply := exchangeWith(t, s, "example.com", typeA); reply[3]&0x0f != rcodeServFail {
+		t.Fatalf("Expected SERVFAIL, got flags %x", reply[2:4])
+	}
+}
+
+func TestForwardQueries(t *testing.T) {
+	upstream := startServer(t, map[string][]net.IP{
+		"example.com": {net.ParseIP("93.184.216.34")},
+	})
+	defer upstream.Close()
+
+	// The first server does not answer and is skipped after a timeout
+	dead, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dead.Close()
+
+	s := startServer(t, map[string][]net.IP{
+		"db": {net.ParseIP("172.17.0.5")},
+	}, dead.LocalAddr().String(), upstream.Addr().String())
+	defer s.Close()
+
+	start := time.Now()
+	if ips := answers(t, exchangeWith(t, s, "example.com", typeA)); len(ips) != 1 || ips[0] != "93.184.216.34" {
+		t.Fatalf("Unexpected forwarded records %v", ips)
+	}
+	if time.Since(start) < ForwardTimeout {
+		t.Fatal("Expected the dead server to be tried first")
+	}
+	if ips := answers(t, exchangeWith(t, s, "db", typeA)); len(ips) != 1 || ips[0] != "172.17.0.5" {
+		t.Fatalf("Unexpected A records %v", ips)
+	}
+}
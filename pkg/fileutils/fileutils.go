@@ -1,26 +1,145 @@
 package fileutils
 
 import (
-	log "github.com/Sirupsen/logrus"
 	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
 )
 
-// Matches returns true if relFilePath matches any of the patterns
-func Matches(relFilePath string, patterns []string) (bool, error) {
-	for _, exclude := range patterns {
-		matched, err := filepath.Match(exclude, relFilePath)
+// PatternMatcher matches paths against a list of patterns compiled once,
+// such as the patterns of a .dockerignore file. Patterns starting with "!"
+// are exceptions which include back the paths matched by earlier patterns;
+// the last pattern matching a path decides whether it is excluded. Besides
+// the syntax of filepath.Match, "**" matches any number of directories.
+type PatternMatcher struct {
+	patterns   []*pattern
+	exceptions bool
+}
+
+type pattern struct {
+	re       *regexp.Regexp
+	negative bool
+}
+
+// NewPatternMatcher compiles patterns. It fails on the first pattern which
+// filepath.Match would reject.
+func NewPatternMatcher(patterns []string) (*PatternMatcher, error) {
+	pm := &PatternMatcher{}
+	for _, p := range patterns {
+		negative := strings.HasPrefix(p, "!")
+		if negative {
+			p = p[1:]
+			pm.exceptions = true
+		}
+		re, err := patternRegexp(p)
 		if err != nil {
-			log.Errorf("Error matching: %s (pattern: %s)", relFilePath, exclude)
-			return false, err
+			return nil, err
+		}
+		pm.patterns = append(pm.patterns, &pattern{re: re, negative: negative})
+	}
+	return pm, nil
+}
+
+// Matches returns true if relFilePath, or one of its parent directories,
+// matches the patterns.
+func (pm *PatternMatcher) Matches(relFilePath string) bool {
+	relFilePath = filepath.Clean(relFilePath)
+	if relFilePath == "." {
+		return false
+	}
+	matched := false
+	for _, p := range pm.patterns {
+		if p.matchesOrParent(relFilePath) {
+			matched = !p.negative
+		}
+	}
+	if matched {
+		log.Debugf("Skipping excluded path: %s", relFilePath)
+	}
+	return matched
+}
+
+// HasExceptions returns true if one of the patterns is an exception. An
+// excluded directory must then be walked as some of its files can be
+// included.
+func (pm *PatternMatcher) HasExceptions() bool {
+	return pm.exceptions
+}
+
+// Matches returns true if relFilePath, or one of its parent directories,
+// matches the patterns. It compiles the patterns on every call; callers
+// matching many paths should use a PatternMatcher.
+func Matches(relFilePath string, patterns []string) (bool, error) {
+	pm, err := NewPatternMatcher(patterns)
+	if err != nil {
+		log.Errorf("Error matching: %s (patterns: %s)", relFilePath, patterns)
+		return false, err
+	}
+	return pm.Matches(relFilePath), nil
+}
+
+func (p *pattern) matchesOrParent(path string) bool {
+	for ; path != "." && path != string(filepath.Separator); path = filepath.Dir(path) {
+		if p.re.MatchString(path) {
+			return true
 		}
-		if matched {
-			if filepath.Clean(relFilePath) == "." {
-				log.Errorf("Can't exclude whole path, excluding pattern: %s", exclude)
-				continue
+	}
+	return false
+}
+
+// patternRegexp converts a pattern to a regular expression matching a
+// whole path.
+func patternRegexp(pattern string) (*regexp.Regexp, error) {
+	// Check the syntax with the rules of filepath.Match first
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	sep := regexp.QuoteMeta(string(filepath.Separator))
+	expr := "^"
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '*' && strings.HasPrefix(pattern[i:], "**"):
+			i++
+			if strings.HasPrefix(pattern[i+1:], string(filepath.Separator)) {
+				// "**/" also matches no directory at all
+				i++
+				expr += "(.*" + sep + ")?"
+			} else {
+				expr += ".*"
+			}
+		case c == '*':
+			expr += "[^" + sep + "]*"
+		case c == '?':
+			expr += "[^" + sep + "]"
+		case c == '[':
+			expr += "["
+			i++
+			if i < len(pattern) && pattern[i] == '^' {
+				expr += "^"
+				i++
+			}
+			for ; i < len(pattern) && pattern[i] != ']'; i++ {
+				if pattern[i] == '\\' && i+1 < len(pattern) {
+					i++
+				}
+				if pattern[i] == '-' {
+					expr += "-"
+				} else {
+					expr += regexp.QuoteMeta(string(pattern[i]))
+				}
+			}
+			if i == len(pattern) {
+				return nil, filepath.ErrBadPattern
 			}
-			log.Debugf("Skipping excluded path: %s", relFilePath)
-			return true, nil
+			expr += "]"
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			expr += regexp.QuoteMeta(string(pattern[i]))
+		default:
+			expr += regexp.QuoteMeta(string(c))
 		}
 	}
-	return false, nil
+	return regexp.Compile(expr + "$")
 }
@@ -0,0 +1,69 @@
+package fileutils
+
+import (
+	"testing"
+)
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		path     string
+		patterns []string
+		matches  bool
+	}{
+		{"file.md", []string{"*.md"}, true},
+		{"dir/file.md", []string{"*.md"}, false},
+		{"dir/file.md", []string{"dir"}, true},
+		{"dir/sub/file", []string{"dir/*"}, true},
+		{"file", []string{"fil?"}, true},
+		{"file", []string{"[e-g]ile"}, true},
+		{"file", []string{"[^f]ile"}, false},
+		{"a/b/c/file.go", []string{"**/*.go"}, true},
+		{"file.go", []string{"**/*.go"}, true},
+		{"a/b/file.go", []string{"a/**/file.go"}, true},
+		{"a/file.go", []string{"a/**/file.go"}, true},
+		{"b/a/file.go", []string{"a/**"}, false},
+		{"a/b/file.go", []string{"a/**"}, true},
+		{"README.md", []string{"*.md", "!README.md"}, false},
+		{"CHANGES.md", []string{"*.md", "!README.md"}, true},
+		{"README.md", []string{"*.md", "!README.md", "README*"}, true},
+		{"dir/keep", []string{"dir", "!dir/keep"}, false},
+		{"dir/other", []string{"dir", "!dir/keep"}, true},
+		{".", []string{"*"}, false},
+	}
+	for _, test := range tests {
+		matches, err := Matches(test.path, test.patterns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if matches != test.matches {
+			t.Errorf("Expected Matches(%q, %q) to be %v", test.path, test.patterns, test.matches)
+		}
+	}
+}
+
+func TestMatchesBadPattern(t *testing.T) {
+	for _, pattern := range []string{"[", "dir/[a-", "!["} {
+		if _, err := Matches("file", []string{pattern}); err == nil {
+			t.Errorf("Expected an error matching the pattern %q", pattern)
+		}
+	}
+}
+
+func TestPatternMatcherHasExceptions(t *testing.T) {
+	tests := []struct {
+		patterns   []string
+		exceptions bool
+	}{
+		{[]string{"*.md", "dir"}, false},
+		{[]string{"*.md", "!README.md"}, true},
+	}
+	for _, test := range tests {
+		pm, err := NewPatternMatcher(test.patterns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pm.HasExceptions() != test.exceptions {
+			t.Errorf("Expected HasExceptions of %q to be %v", test.patterns, test.exceptions)
+		}
+	}
+}
@@ -20,9 +20,16 @@ const (
 )
 
 var (
-	nat                 = []string{"-t", "nat"}
-	supportsXlock       = false
-	ErrIptablesNotFound = errors.New("Iptables not found")
+	nat                  = []string{"-t", "nat"}
+	supportsXlock        = false
+	supportsXlock6       = false
+	ErrIptablesNotFound  = errors.New("Iptables not found")
+	ErrIp6tablesNotFound = errors.New("Ip6tables not found")
+
+	// regexes to replace the addresses in the rules compared with the
+	// output of iptables-save and ip6tables-save
+	ipv4Re = regexp.MustCompile(`[0-9]{1,3}\.[0-9]{1,3}\.[0-9]{1,3}\.[0-9]{1,3}\/[0-9]{1,2}`)
+	ipv6Re = regexp.MustCompile(`[0-9a-fA-F]*:[0-9a-fA-F:.]*\/[0-9]{1,3}`)
 )
 
 type Chain struct {
@@ -41,6 +48,7 @@ func (e *ChainError) Error() string {
 
 func init() {
 	supportsXlock = exec.Command("iptables", "--wait", "-L", "-n").Run() == nil
+	supportsXlock6 = exec.Command("ip6tables", "--wait", "-L", "-n").Run() == nil
 }
 
 func NewChain(name, bridge string) (*Chain, error) {
@@ -152,36 +160,56 @@ func (c *Chain) Remove() error {
 
 // Check if an existing rule exists
 func Exists(args ...string) bool {
+	return exists("iptables", ipv4Re, args...)
+}
+
+// Exists6 checks if an existing ip6tables rule exists
+func Exists6(args ...string) bool {
+	return exists("ip6tables", ipv6Re, args...)
+}
+
+func exists(cmd string, addrRe *regexp.Regexp, args ...string) bool {
 	// iptables -C, --check option was added in v.1.4.11
 	// http://ftp.netfilter.org/pub/iptables/changes-iptables-1.4.11.txt
 
 	// try -C
 	// if exit status is 0 then return true, the rule exists
-	if _, err := Raw(append([]string{"-C"}, args...)...); err == nil {
+	if _, err := raw(cmd, append([]string{"-C"}, args...)...); err == nil {
 		return true
 	}
 
 	// parse iptables-save for the rule
 	rule := strings.Replace(strings.Join(args, " "), "-t nat ", "", -1)
-	existingRules, _ := exec.Command("iptables-save").Output()
+	existingRules, _ := exec.Command(cmd + "-save").Output()
 
-	// regex to replace ips in rule
+	// replace ips in rule
 	// because MASQUERADE rule will not be exactly what was passed
-	re := regexp.MustCompile(`[0-9]{1,3}\.[0-9]{1,3}\.[0-9]{1,3}\.[0-9]{1,3}\/[0-9]{1,2}`)
-
 	return strings.Contains(
-		re.ReplaceAllString(string(existingRules), "?"),
-		re.ReplaceAllString(rule, "?"),
+		addrRe.ReplaceAllString(string(existingRules), "?"),
+		addrRe.ReplaceAllString(rule, "?"),
 	)
 }
 
 func Raw(args ...string) ([]byte, error) {
-	path, err := exec.LookPath("iptables")
+	return raw("iptables", args...)
+}
+
+// Raw6 calls ip6tables with the given arguments, to set up the rules of the
+// IPv6 traffic.
+func Raw6(args ...string) ([]byte, error) {
+	return raw("ip6tables", args...)
+}
+
+func raw(cmd string, args ...string) ([]byte, error) {
+	path, err := exec.LookPath(cmd)
 	if err != nil {
+		if cmd == "ip6tables" {
+			return nil, ErrIp6tablesNotFound
+		}
 		return nil, ErrIptablesNotFound
 	}
 
-	if supportsXlock {
+	if (cmd == "iptables" && supportsXlock) || (cmd == "ip6tables" && supportsXlock6) {
 		args = append([]string{"--wait"}, args...)
 	}
 
@@ -189,7 +217,7 @@ func Raw(args ...string) ([]byte, error) {
 
 	output, err := exec.Command(path, args...).CombinedOutput()
 	if err != nil {
-		return nil, fmt.Errorf("iptables failed: iptables %v: %s (%s)", strings.Join(args, " "), output, err)
+		return nil, fmt.Errorf("%s failed: %s %v: %s (%s)", cmd, cmd, strings.Join(args, " "), output, err)
 	}
 
 	// ignore iptables' message about xtables lock
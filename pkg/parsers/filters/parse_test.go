@@ -76,3 +76,26 @@ func TestEmpty(t *testing.T) {
 		t.Errorf("these should both be empty sets")
 	}
 }
+
+func TestMatchKVList(t *testing.T) {
+	labels := map[string]string{"com.example.key": "value", "com.example.flag": ""}
+	cases := []struct {
+		filters Args
+		labels  map[string]string
+		match   bool
+	}{
+		{Args{}, labels, true},
+		{Args{}, nil, true},
+		{Args{"label": {"com.example.key"}}, labels, true},
+		{Args{"label": {"com.example.key=value"}}, labels, true},
+		{Args{"label": {"com.example.key=other"}}, labels, false},
+		{Args{"label": {"com.example.key=value", "com.example.flag"}}, labels, true},
+		{Args{"label": {"com.example.key=value", "com.example.missing"}}, labels, false},
+		{Args{"label": {"com.example.key"}}, nil, false},
+	}
+	for _, c := range cases {
+		if match := c.filters.MatchKVList("label", c.labels); match != c.match {
+			t.Errorf("expected MatchKVList(%v, %v) to be %v", c.filters, c.labels, c.match)
+		}
+	}
+}
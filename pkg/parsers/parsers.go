@@ -65,7 +65,13 @@ func ParseTCPAddr(addr string, defaultAddr string) (string, error) {
 // Get a repos name and returns the right reposName + tag
 // The tag can be confusing because of a port in a repository name.
 //     Ex: localhost.localdomain:5000/samalba/hipache:latest
+// The tag is a digest when the name refers to an image by the digest of its
+// manifest.
+//     Ex: samalba/hipache@sha256:e8c1...
 func ParseRepositoryTag(repos string) (string, string) {
+	if n := strings.Index(repos, "@"); n >= 0 {
+		return repos[:n], repos[n+1:]
+	}
 	n := strings.LastIndex(repos, ":")
 	if n < 0 {
 		return repos, ""
@@ -60,6 +60,12 @@ func TestParseRepositoryTag(t *testing.T) {
 	if repo, tag := ParseRepositoryTag("url:5000/repo:tag"); repo != "url:5000/repo" || tag != "tag" {
 		t.Errorf("Expected repo: '%s' and tag: '%s', got '%s' and '%s'", "url:5000/repo", "tag", repo, tag)
 	}
+	if repo, digest := ParseRepositoryTag("root@sha256:abcdef"); repo != "root" || digest != "sha256:abcdef" {
+		t.Errorf("Expected repo: '%s' and digest: '%s', got '%s' and '%s'", "root", "sha256:abcdef", repo, digest)
+	}
+	if repo, digest := ParseRepositoryTag("url:5000/repo@sha256:abcdef"); repo != "url:5000/repo" || digest != "sha256:abcdef" {
+		t.Errorf("Expected repo: '%s' and digest: '%s', got '%s' and '%s'", "url:5000/repo", "sha256:abcdef", repo, digest)
+	}
 }
 
 func TestParsePortMapping(t *testing.T) {
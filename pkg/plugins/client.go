@@ -0,0 +1,72 @@
+package plugins
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	versionMimetype = "application/vnd.docker.plugins.v1+json"
+	defaultTimeout  = 30 * time.Second
+)
+
+// Client talks to a plugin listening on a unix socket. Every call is a
+// POST of a json encoded request to /<service>.<method>, answered with a
+// json encoded response.
+type Client struct {
+	http *http.Client
+	addr string
+}
+
+// NewClient returns a client for the plugin listening on the unix socket
+// at addr. Calls which take longer than defaultTimeout fail.
+func NewClient(addr string) *Client {
+	tr := &http.Transport{
+		Dial: func(_, _ string) (net.Conn, error) {
+			return net.DialTimeout("unix", addr, defaultTimeout)
+		},
+	}
+	return &Client{
+		http: &http.Client{Transport: tr, Timeout: defaultTimeout},
+		addr: addr,
+	}
+}
+
+// Call invokes serviceMethod on the plugin with args, and decodes the
+// response into ret unless it is nil.
+func (c *Client) Call(serviceMethod string, args interface{}, ret interface{}) error {
+	var buf bytes.Buffer
+	if args != nil {
+		if err := json.NewEncoder(&buf).Encode(args); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest("POST", "http://plugin/"+serviceMethod, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Accept", versionMimetype)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("Error calling %s on plugin at %s: %v", serviceMethod, c.addr, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("%s: %s", serviceMethod, http.StatusText(resp.StatusCode))
+		}
+		return fmt.Errorf("%s: %s", serviceMethod, strings.TrimSpace(string(body)))
+	}
+	if ret == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(ret)
+}
@@ -0,0 +1,108 @@
+// Package plugins discovers and activates out-of-process plugins.
+//
+// A plugin is a process listening on a unix socket called NAME.sock in
+// SocketsPath. When a plugin is first used it is activated: it must answer
+// the Plugin.Activate call with the list of subsystems it implements, such
+// as "VolumeDriver".
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrNotFound is returned when no plugin with the requested name exists.
+	ErrNotFound = errors.New("Plugin not found")
+
+	// SocketsPath is the directory in which plugins create their sockets.
+	SocketsPath = "/run/docker/plugins"
+
+	plugins     = make(map[string]*Plugin)
+	pluginsLock sync.Mutex
+)
+
+// Manifest is the answer of a plugin to the Plugin.Activate call.
+type Manifest struct {
+	// Implements lists the subsystems implemented by the plugin.
+	Implements []string
+}
+
+// Plugin is an activated plugin.
+type Plugin struct {
+	Name     string
+	Addr     string
+	Client   *Client
+	Manifest *Manifest
+}
+
+// Implements returns whether the plugin implements the subsystem kind.
+func (p *Plugin) Implements(kind string) bool {
+	for _, imp := range p.Manifest.Implements {
+		if imp == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the plugin called name, activating it on first use. It fails
+// if the plugin does not implement the subsystem kind. The activation call
+// is made without holding the lock, so a plugin which is slow to answer
+// does not block the lookup of the other plugins.
+func Get(name, kind string) (*Plugin, error) {
+	pluginsLock.Lock()
+	p, exists := plugins[name]
+	pluginsLock.Unlock()
+
+	if !exists {
+		activated, err := activate(name)
+		if err != nil {
+			return nil, err
+		}
+		pluginsLock.Lock()
+		// Another caller may have activated the plugin in the meantime;
+		// keep the first one so that every caller shares the same client.
+		if p, exists = plugins[name]; !exists {
+			p = activated
+			plugins[name] = p
+		}
+		pluginsLock.Unlock()
+	}
+	if !p.Implements(kind) {
+		return nil, fmt.Errorf("Plugin %s does not implement %s", name, kind)
+	}
+	return p, nil
+}
+
+func activate(name string) (*Plugin, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return nil, fmt.Errorf("Invalid plugin name: %s", name)
+	}
+	addr := filepath.Join(SocketsPath, name+".sock")
+	fi, err := os.Stat(addr)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return nil, fmt.Errorf("Plugin %s: %s is not a socket", name, addr)
+	}
+
+	p := &Plugin{
+		Name:     name,
+		Addr:     addr,
+		Client:   NewClient(addr),
+		Manifest: &Manifest{},
+	}
+	if err := p.Client.Call("Plugin.Activate", nil, p.Manifest); err != nil {
+		return nil, fmt.Errorf("Could not activate plugin %s: %v", name, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,139 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func setupPlugin(t *testing.T, name string, mux *http.ServeMux) (func(), error) {
+	dir, err := ioutil.TempDir("", "docker-plugins-test")
+	if err != nil {
+		return nil, err
+	}
+	oldPath := SocketsPath
+	SocketsPath = dir
+
+	l, err := net.Listen("unix", filepath.Join(dir, name+".sock"))
+	if err != nil {
+		return nil, err
+	}
+	go http.Serve(l, mux)
+
+	return func() {
+		l.Close()
+		os.RemoveAll(dir)
+		SocketsPath = oldPath
+		pluginsLock.Lock()
+		delete(plugins, name)
+		pluginsLock.Unlock()
+	}, nil
+}
+
+func TestGetPlugin(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/Plugin.Activate", func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get("Accept"); accept != versionMimetype {
+			t.Errorf("Expected Accept %s, got %s", versionMimetype, accept)
+		}
+		fmt.Fprintln(w, `{"Implements": ["TestDriver"]}`)
+	})
+	mux.HandleFunc("/TestDriver.Echo", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(req)
+	})
+	mux.HandleFunc("/TestDriver.Fail", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+	})
+	cleanup, err := setupPlugin(t, "echo", mux)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	p, err := Get("echo", "TestDriver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ret map[string]string
+	if err := p.Client.Call("TestDriver.Echo", map[string]string{"Name": "hello"}, &ret); err != nil {
+		t.Fatal(err)
+	}
+	if ret["Name"] != "hello" {
+		t.Fatalf("Expected the plugin to echo hello, got %v", ret)
+	}
+	if err := p.Client.Call("TestDriver.Fail", nil, nil); err == nil || err.Error() != "TestDriver.Fail: something went wrong" {
+		t.Fatalf("Expected the error of the plugin, got %v", err)
+	}
+
+	if _, err := Get("echo", "OtherDriver"); err == nil {
+		t.Fatal("Expected an error getting a plugin for a subsystem it does not implement")
+	}
+	if _, err := Get("missing", "TestDriver"); err != ErrNotFound {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+	if _, err := Get("../echo", "TestDriver"); err == nil {
+		t.Fatal("Expected an error getting a plugin with an invalid name")
+	}
+}
+
+func TestGetPluginWhileActivating(t *testing.T) {
+	activating := make(chan struct{})
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/Plugin.Activate", func(w http.ResponseWriter, r *http.Request) {
+		close(activating)
+		<-release
+		fmt.Fprintln(w, `{"Implements": ["TestDriver"]}`)
+	})
+	cleanup, err := setupPlugin(t, "slow", mux)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	pluginsLock.Lock()
+	plugins["fast"] = &Plugin{Name: "fast", Manifest: &Manifest{Implements: []string{"TestDriver"}}}
+	pluginsLock.Unlock()
+	defer func() {
+		pluginsLock.Lock()
+		delete(plugins, "fast")
+		pluginsLock.Unlock()
+	}()
+
+	errs := make(chan error)
+	go func() {
+		_, err := Get("slow", "TestDriver")
+		errs <- err
+	}()
+	<-activating
+
+	got := make(chan error)
+	go func() {
+		_, err := Get("fast", "TestDriver")
+		got <- err
+	}()
+	select {
+	case err := <-got:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Getting an activated plugin blocked on the activation of another one")
+	}
+
+	close(release)
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
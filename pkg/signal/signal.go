@@ -1,10 +1,18 @@
 package signal
 
 import (
+	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 )
 
+// DefaultStopSignal is the signal sent to stop a container which does not
+// set its own.
+const DefaultStopSignal = "SIGTERM"
+
 func CatchAll(sigc chan os.Signal) {
 	handledSigs := []os.Signal{}
 	for _, s := range SignalMap {
@@ -17,3 +25,20 @@ func StopCatch(sigc chan os.Signal) {
 	signal.Stop(sigc)
 	close(sigc)
 }
+
+// ParseSignal returns the signal rawSignal, given either as a number or as a
+// name like "KILL" or "SIGKILL".
+func ParseSignal(rawSignal string) (syscall.Signal, error) {
+	// The largest legal signal is 31, so let's parse on 5 bits
+	if s, err := strconv.ParseUint(rawSignal, 10, 5); err == nil {
+		if s == 0 {
+			return -1, fmt.Errorf("Invalid signal: %s", rawSignal)
+		}
+		return syscall.Signal(s), nil
+	}
+	s, ok := SignalMap[strings.TrimPrefix(strings.ToUpper(rawSignal), "SIG")]
+	if !ok {
+		return -1, fmt.Errorf("Invalid signal: %s", rawSignal)
+	}
+	return s, nil
+}
@@ -0,0 +1,29 @@
+package signal
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestParseSignal(t *testing.T) {
+	for raw, expected := range map[string]syscall.Signal{
+		"9":        syscall.SIGKILL,
+		"KILL":     syscall.SIGKILL,
+		"SIGQUIT":  syscall.SIGQUIT,
+		"sigwinch": syscall.SIGWINCH,
+	} {
+		s, err := ParseSignal(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != expected {
+			t.Fatalf("Expected %s to be parsed as %d, got %d", raw, expected, s)
+		}
+	}
+
+	for _, raw := range []string{"0", "", "SIGFOO", "32", "-1"} {
+		if _, err := ParseSignal(raw); err == nil {
+			t.Fatalf("Expected an error parsing %q", raw)
+		}
+	}
+}
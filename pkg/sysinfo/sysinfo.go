@@ -12,6 +12,15 @@ import (
 type SysInfo struct {
 	MemoryLimit            bool
 	SwapLimit              bool
+	KernelMemory           bool
+	OomKillDisable         bool
+	CpuCfsPeriod           bool
+	CpuCfsQuota            bool
+	BlkioWeight            bool
+	BlkioReadBpsDevice     bool
+	BlkioWriteBpsDevice    bool
+	BlkioReadIOpsDevice    bool
+	BlkioWriteIOpsDevice   bool
 	IPv4ForwardingDisabled bool
 	AppArmor               bool
 }
@@ -35,6 +44,51 @@ func New(quiet bool) *SysInfo {
 		if !sysInfo.SwapLimit && !quiet {
 			log.Printf("WARNING: Your kernel does not support cgroup swap limit.")
 		}
+
+		sysInfo.KernelMemory = cgroupFileExists(cgroupMemoryMountpoint, "memory.kmem.limit_in_bytes")
+		if !sysInfo.KernelMemory && !quiet {
+			log.Printf("WARNING: Your kernel does not support cgroup kernel memory limit.")
+		}
+
+		sysInfo.OomKillDisable = cgroupFileExists(cgroupMemoryMountpoint, "memory.oom_control")
+		if !sysInfo.OomKillDisable && !quiet {
+			log.Printf("WARNING: Your kernel does not support cgroup oom kill disable.")
+		}
+	}
+
+	if cgroupCpuMountpoint, err := cgroups.FindCgroupMountpoint("cpu"); err != nil {
+		if !quiet {
+			log.Printf("WARNING: %s\n", err)
+		}
+	} else {
+		sysInfo.CpuCfsPeriod = cgroupFileExists(cgroupCpuMountpoint, "cpu.cfs_period_us")
+		if !sysInfo.CpuCfsPeriod && !quiet {
+			log.Printf("WARNING: Your kernel does not support cgroup cfs period.")
+		}
+
+		sysInfo.CpuCfsQuota = cgroupFileExists(cgroupCpuMountpoint, "cpu.cfs_quota_us")
+		if !sysInfo.CpuCfsQuota && !quiet {
+			log.Printf("WARNING: Your kernel does not support cgroup cfs quota.")
+		}
+	}
+
+	if cgroupBlkioMountpoint, err := cgroups.FindCgroupMountpoint("blkio"); err != nil {
+		if !quiet {
+			log.Printf("WARNING: %s\n", err)
+		}
+	} else {
+		sysInfo.BlkioWeight = cgroupFileExists(cgroupBlkioMountpoint, "blkio.weight")
+		if !sysInfo.BlkioWeight && !quiet {
+			log.Printf("WARNING: Your kernel does not support cgroup blkio weight.")
+		}
+
+		sysInfo.BlkioReadBpsDevice = cgroupFileExists(cgroupBlkioMountpoint, "blkio.throttle.read_bps_device")
+		sysInfo.BlkioWriteBpsDevice = cgroupFileExists(cgroupBlkioMountpoint, "blkio.throttle.write_bps_device")
+		sysInfo.BlkioReadIOpsDevice = cgroupFileExists(cgroupBlkioMountpoint, "blkio.throttle.read_iops_device")
+		sysInfo.BlkioWriteIOpsDevice = cgroupFileExists(cgroupBlkioMountpoint, "blkio.throttle.write_iops_device")
+		if !(sysInfo.BlkioReadBpsDevice && sysInfo.BlkioWriteBpsDevice && sysInfo.BlkioReadIOpsDevice && sysInfo.BlkioWriteIOpsDevice) && !quiet {
+			log.Printf("WARNING: Your kernel does not support cgroup blkio throttling.")
+		}
 	}
 
 	// Check if AppArmor seems to be enabled on this system.
@@ -45,3 +99,8 @@ func New(quiet bool) *SysInfo {
 	}
 	return sysInfo
 }
+
+func cgroupFileExists(mountpoint, file string) bool {
+	_, err := os.Stat(path.Join(mountpoint, file))
+	return err == nil
+}
@@ -51,6 +51,13 @@ type TarSum interface {
 	Hash() THash
 }
 
+// BuilderContext is the TarSum of a build context, which files can be
+// removed from after being excluded.
+type BuilderContext interface {
+	TarSum
+	Remove(string)
+}
+
 // tarSum struct is the structure for a Version0 checksum calculation
 type tarSum struct {
 	io.Reader
@@ -63,6 +70,7 @@ type tarSum struct {
 	h                  hash.Hash
 	tHash              THash
 	sums               FileInfoSums
+	removed            map[string]bool // names removed from sums, see Remove
 	fileCounter        int64
 	currentFile        string
 	finished           bool
@@ -222,6 +230,7 @@ func (ts *tarSum) Read(buf []byte) (int, error) {
 }
 
 func (ts *tarSum) Sum(extra []byte) string {
+	ts.compactSums()
 	ts.sums.SortBySums()
 	h := ts.tHash.Hash()
 	if extra != nil {
@@ -237,5 +246,30 @@ func (ts *tarSum) Sum(extra []byte) string {
 }
 
 func (ts *tarSum) GetSums() FileInfoSums {
+	ts.compactSums()
 	return ts.sums
 }
+
+// Remove removes the sums of the file filename. It only records the name,
+// so that removing many files does not scan the sums every time; they are
+// dropped together on the next call to Sum or GetSums.
+func (ts *tarSum) Remove(filename string) {
+	if ts.removed == nil {
+		ts.removed = make(map[string]bool)
+	}
+	ts.removed[filename] = true
+}
+
+func (ts *tarSum) compactSums() {
+	if len(ts.removed) == 0 {
+		return
+	}
+	sums := ts.sums[:0]
+	for _, fis := range ts.sums {
+		if !ts.removed[fis.Name()] {
+			sums = append(sums, fis)
+		}
+	}
+	ts.sums = sums
+	ts.removed = nil
+}
@@ -443,6 +443,31 @@ func TestIteration(t *testing.T) {
 
 }
 
+func TestRemove(t *testing.T) {
+	ts, err := NewTarSum(sizedTar(sizedOptions{3, 8, false, false}), true, Version0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(ioutil.Discard, ts); err != nil {
+		t.Fatal(err)
+	}
+	sum := ts.Sum(nil)
+
+	bc := ts.(BuilderContext)
+	bc.Remove("/testdata1")
+	bc.Remove("/missing")
+	sums := bc.GetSums()
+	if len(sums) != 2 {
+		t.Fatalf("Expected 2 sums after removing a file, got %d", len(sums))
+	}
+	if sums.GetFile("/testdata1") != nil {
+		t.Fatal("Expected the sum of /testdata1 to be removed")
+	}
+	if bc.Sum(nil) == sum {
+		t.Fatal("Expected the checksum to change after removing a file")
+	}
+}
+
 func renderSumForHeader(v Version, h *tar.Header, data []byte) (string, error) {
 	buf := bytes.NewBuffer(nil)
 	// first build our test tar
@@ -0,0 +1,52 @@
+package ulimit
+
+import "testing"
+
+func TestParseValid(t *testing.T) {
+	u1 := &Ulimit{"nofile", 1024, 512}
+	if u2, _ := Parse("nofile=512:1024"); *u1 != *u2 {
+		t.Fatalf("expected %q, but got %q", u1, u2)
+	}
+
+	u1 = &Ulimit{"nproc", 100, 100}
+	if u2, _ := Parse("nproc=100"); *u1 != *u2 {
+		t.Fatalf("expected %q, but got %q", u1, u2)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, val := range []string{
+		"notreal=1024:1024",
+		"nofile",
+		"nofile=",
+		"nofile=1024:",
+		"nofile=asdf",
+		"nofile=1024:asdf",
+		"nofile=1024:512",
+	} {
+		if _, err := Parse(val); err == nil {
+			t.Fatalf("expected an error parsing %q", val)
+		}
+	}
+}
+
+func TestGetRlimit(t *testing.T) {
+	u := &Ulimit{"nofile", 2048, 1024}
+	r, err := u.GetRlimit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Type != rlimitNofile || r.Soft != 1024 || r.Hard != 2048 {
+		t.Fatalf("unexpected rlimit %v", r)
+	}
+	if r.Name() != "nofile" {
+		t.Fatalf("expected the name of the rlimit to be nofile, got %s", r.Name())
+	}
+}
+
+func TestString(t *testing.T) {
+	u := &Ulimit{"nofile", 1024, 512}
+	if s := u.String(); s != "nofile=512:1024" {
+		t.Fatalf("expected String to return nofile=512:1024, but got %s", s)
+	}
+}
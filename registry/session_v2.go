@@ -21,8 +21,8 @@ func newV2RegistryRouter() *mux.Router {
 	// Version Info
 	v2Router.Path("/version").Name("version")
 
-	// Image Manifests
-	v2Router.Path("/manifest/{imagename:[a-z0-9-._/]+}/{tagname:[a-zA-Z0-9-._]+}").Name("manifests")
+	// Image Manifests, by tag or by digest
+	v2Router.Path("/manifest/{imagename:[a-z0-9-._/]+}/{tagname:[a-zA-Z0-9-._:+]+}").Name("manifests")
 
 	// List Image Tags
 	v2Router.Path("/tags/{imagename:[a-z0-9-._/]+}").Name("tags")
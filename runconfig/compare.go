@@ -12,9 +12,16 @@ func Compare(a, b *Config) bool {
 		a.User != b.User ||
 		a.Memory != b.Memory ||
 		a.MemorySwap != b.MemorySwap ||
+		a.MemoryReservation != b.MemoryReservation ||
+		a.KernelMemory != b.KernelMemory ||
+		a.OomKillDisable != b.OomKillDisable ||
 		a.CpuShares != b.CpuShares ||
+		a.CpuQuota != b.CpuQuota ||
+		a.CpuPeriod != b.CpuPeriod ||
+		a.BlkioWeight != b.BlkioWeight ||
 		a.OpenStdin != b.OpenStdin ||
-		a.Tty != b.Tty {
+		a.Tty != b.Tty ||
+		a.StopSignal != b.StopSignal {
 		return false
 	}
 	if len(a.Cmd) != len(b.Cmd) ||
@@ -22,7 +29,8 @@ func Compare(a, b *Config) bool {
 		len(a.PortSpecs) != len(b.PortSpecs) ||
 		len(a.ExposedPorts) != len(b.ExposedPorts) ||
 		len(a.Entrypoint) != len(b.Entrypoint) ||
-		len(a.Volumes) != len(b.Volumes) {
+		len(a.Volumes) != len(b.Volumes) ||
+		len(a.Labels) != len(b.Labels) {
 		return false
 	}
 
@@ -56,5 +64,26 @@ func Compare(a, b *Config) bool {
 			return false
 		}
 	}
+	for k, v := range a.Labels {
+		if bv, exists := b.Labels[k]; !exists || bv != v {
+			return false
+		}
+	}
+	if (a.Healthcheck == nil) != (b.Healthcheck == nil) {
+		return false
+	}
+	if a.Healthcheck != nil {
+		if a.Healthcheck.Interval != b.Healthcheck.Interval ||
+			a.Healthcheck.Timeout != b.Healthcheck.Timeout ||
+			a.Healthcheck.Retries != b.Healthcheck.Retries ||
+			len(a.Healthcheck.Test) != len(b.Healthcheck.Test) {
+			return false
+		}
+		for i := 0; i < len(a.Healthcheck.Test); i++ {
+			if a.Healthcheck.Test[i] != b.Healthcheck.Test[i] {
+				return false
+			}
+		}
+	}
 	return true
 }
@@ -1,6 +1,8 @@
 package runconfig
 
 import (
+	"time"
+
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/nat"
 )
@@ -9,54 +11,88 @@ import (
 // Here, "portable" means "independent from the host we are running on".
 // Non-portable information *should* appear in HostConfig.
 type Config struct {
-	Hostname        string
-	Domainname      string
-	User            string
-	Memory          int64  // Memory limit (in bytes)
-	MemorySwap      int64  // Total memory usage (memory + swap); set `-1' to disable swap
-	CpuShares       int64  // CPU shares (relative weight vs. other containers)
-	Cpuset          string // Cpuset 0-2, 0,1
-	AttachStdin     bool
-	AttachStdout    bool
-	AttachStderr    bool
-	PortSpecs       []string // Deprecated - Can be in the format of 8080/tcp
-	ExposedPorts    map[nat.Port]struct{}
-	Tty             bool // Attach standard streams to a tty, including stdin if it is not closed.
-	OpenStdin       bool // Open stdin
-	StdinOnce       bool // If true, close stdin after the 1 attached client disconnects.
-	Env             []string
-	Cmd             []string
-	Image           string // Name of the image as it was passed by the operator (eg. could be symbolic)
-	Volumes         map[string]struct{}
-	WorkingDir      string
-	Entrypoint      []string
-	NetworkDisabled bool
-	MacAddress      string
-	OnBuild         []string
+	Hostname          string
+	Domainname        string
+	User              string
+	Memory            int64  // Memory limit (in bytes)
+	MemorySwap        int64  // Total memory usage (memory + swap); set `-1' to disable swap
+	MemoryReservation int64  // Memory soft limit (in bytes)
+	KernelMemory      int64  // Kernel memory limit (in bytes)
+	OomKillDisable    bool   // Whether to disable the OOM killer for the container
+	CpuShares         int64  // CPU shares (relative weight vs. other containers)
+	CpuQuota          int64  // CPU time allowed in each period (in microseconds)
+	CpuPeriod         int64  // Length of a CPU period (in microseconds)
+	Cpuset            string // Cpuset 0-2, 0,1
+	BlkioWeight       int64  // Block IO weight (relative weight vs. other containers)
+	AttachStdin       bool
+	AttachStdout      bool
+	AttachStderr      bool
+	PortSpecs         []string // Deprecated - Can be in the format of 8080/tcp
+	ExposedPorts      map[nat.Port]struct{}
+	Tty               bool // Attach standard streams to a tty, including stdin if it is not closed.
+	OpenStdin         bool // Open stdin
+	StdinOnce         bool // If true, close stdin after the 1 attached client disconnects.
+	Env               []string
+	Cmd               []string
+	Image             string // Name of the image as it was passed by the operator (eg. could be symbolic)
+	Volumes           map[string]struct{}
+	WorkingDir        string
+	Entrypoint        []string
+	NetworkDisabled   bool
+	MacAddress        string
+	OnBuild           []string
+	Labels            map[string]string
+	Healthcheck       *HealthConfig // Probe checking that the container is healthy
+	StopSignal        string        // Signal to stop the container, SIGTERM if empty
+}
+
+// HealthConfig holds the configuration of the probe run periodically in a
+// container to check that it is healthy.
+type HealthConfig struct {
+	// Test is the probe to run:
+	// {} inherits the probe of the image,
+	// {"NONE"} disables the probe,
+	// {"CMD", args...} executes the arguments,
+	// {"CMD-SHELL", command} runs the command with /bin/sh -c.
+	Test []string
+
+	// Zero values inherit the setting of the image, or the defaults.
+	Interval time.Duration // Time between the end of a probe and the start of the next one
+	Timeout  time.Duration // Time after which a probe is considered to have failed
+	Retries  int           // Consecutive failures before the container is unhealthy
 }
 
 func ContainerConfigFromJob(job *engine.Job) *Config {
 	config := &Config{
-		Hostname:        job.Getenv("Hostname"),
-		Domainname:      job.Getenv("Domainname"),
-		User:            job.Getenv("User"),
-		Memory:          job.GetenvInt64("Memory"),
-		MemorySwap:      job.GetenvInt64("MemorySwap"),
-		CpuShares:       job.GetenvInt64("CpuShares"),
-		Cpuset:          job.Getenv("Cpuset"),
-		AttachStdin:     job.GetenvBool("AttachStdin"),
-		AttachStdout:    job.GetenvBool("AttachStdout"),
-		AttachStderr:    job.GetenvBool("AttachStderr"),
-		Tty:             job.GetenvBool("Tty"),
-		OpenStdin:       job.GetenvBool("OpenStdin"),
-		StdinOnce:       job.GetenvBool("StdinOnce"),
-		Image:           job.Getenv("Image"),
-		WorkingDir:      job.Getenv("WorkingDir"),
-		NetworkDisabled: job.GetenvBool("NetworkDisabled"),
-		MacAddress:      job.Getenv("MacAddress"),
+		Hostname:          job.Getenv("Hostname"),
+		Domainname:        job.Getenv("Domainname"),
+		User:              job.Getenv("User"),
+		Memory:            job.GetenvInt64("Memory"),
+		MemorySwap:        job.GetenvInt64("MemorySwap"),
+		MemoryReservation: job.GetenvInt64("MemoryReservation"),
+		KernelMemory:      job.GetenvInt64("KernelMemory"),
+		OomKillDisable:    job.GetenvBool("OomKillDisable"),
+		CpuShares:         job.GetenvInt64("CpuShares"),
+		CpuQuota:          job.GetenvInt64("CpuQuota"),
+		CpuPeriod:         job.GetenvInt64("CpuPeriod"),
+		Cpuset:            job.Getenv("Cpuset"),
+		BlkioWeight:       job.GetenvInt64("BlkioWeight"),
+		AttachStdin:       job.GetenvBool("AttachStdin"),
+		AttachStdout:      job.GetenvBool("AttachStdout"),
+		AttachStderr:      job.GetenvBool("AttachStderr"),
+		Tty:               job.GetenvBool("Tty"),
+		OpenStdin:         job.GetenvBool("OpenStdin"),
+		StdinOnce:         job.GetenvBool("StdinOnce"),
+		Image:             job.Getenv("Image"),
+		WorkingDir:        job.Getenv("WorkingDir"),
+		NetworkDisabled:   job.GetenvBool("NetworkDisabled"),
+		MacAddress:        job.Getenv("MacAddress"),
+		StopSignal:        job.Getenv("StopSignal"),
 	}
 	job.GetenvJson("ExposedPorts", &config.ExposedPorts)
 	job.GetenvJson("Volumes", &config.Volumes)
+	job.GetenvJson("Labels", &config.Labels)
+	job.GetenvJson("Healthcheck", &config.Healthcheck)
 	if PortSpecs := job.GetenvList("PortSpecs"); PortSpecs != nil {
 		config.PortSpecs = PortSpecs
 	}
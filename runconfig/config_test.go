@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/nat"
 )
@@ -187,6 +188,15 @@ func TestCompare(t *testing.T) {
 	if Compare(&config1, &config5) {
 		t.Fatalf("Compare should return false, Volumes are different")
 	}
+	config6 := Config{
+		PortSpecs: []string{"1111:1111", "2222:2222"},
+		Env:       []string{"VAR1=1", "VAR2=2"},
+		Volumes:   volumes1,
+		Labels:    map[string]string{"com.example.key": "value"},
+	}
+	if Compare(&config1, &config6) {
+		t.Fatalf("Compare should return false, Labels are different")
+	}
 	if !Compare(&config1, &config1) {
 		t.Fatalf("Compare should return true")
 	}
@@ -200,6 +210,7 @@ func TestMerge(t *testing.T) {
 		PortSpecs: []string{"1111:1111", "2222:2222"},
 		Env:       []string{"VAR1=1", "VAR2=2"},
 		Volumes:   volumesImage,
+		Labels:    map[string]string{"image": "1", "shared": "image"},
 	}
 
 	volumesUser := make(map[string]struct{})
@@ -208,6 +219,7 @@ func TestMerge(t *testing.T) {
 		PortSpecs: []string{"3333:2222", "3333:3333"},
 		Env:       []string{"VAR2=3", "VAR3=3"},
 		Volumes:   volumesUser,
+		Labels:    map[string]string{"user": "1", "shared": "user"},
 	}
 
 	if err := Merge(configUser, configImage); err != nil {
@@ -240,6 +252,10 @@ func TestMerge(t *testing.T) {
 		}
 	}
 
+	if len(configUser.Labels) != 3 || configUser.Labels["image"] != "1" || configUser.Labels["user"] != "1" || configUser.Labels["shared"] != "user" {
+		t.Fatalf("Expected labels image=1, user=1 and shared=user, found %v", configUser.Labels)
+	}
+
 	ports, _, err := nat.ParsePortSpecs([]string{"0000"})
 	if err != nil {
 		t.Error(err)
@@ -262,3 +278,35 @@ func TestMerge(t *testing.T) {
 	}
 
 }
+
+func TestMergeHealthcheck(t *testing.T) {
+	configImage := &Config{
+		Healthcheck: &HealthConfig{
+			Test:     []string{"CMD-SHELL", "true"},
+			Interval: time.Minute,
+			Retries:  5,
+		},
+	}
+
+	configUser := &Config{}
+	if err := Merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	if configUser.Healthcheck != configImage.Healthcheck {
+		t.Fatalf("Expected the healthcheck of the image, found %v", configUser.Healthcheck)
+	}
+
+	configUser = &Config{
+		Healthcheck: &HealthConfig{Interval: time.Second},
+	}
+	if err := Merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	health := configUser.Healthcheck
+	if len(health.Test) != 2 || health.Interval != time.Second || health.Retries != 5 {
+		t.Fatalf("Expected the probe of the image with an interval of 1s, found %v", health)
+	}
+	if configImage.Healthcheck.Interval != time.Minute {
+		t.Fatal("The healthcheck of the image should not be modified")
+	}
+}
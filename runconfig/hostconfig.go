@@ -5,6 +5,7 @@ import (
 
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/nat"
+	"github.com/docker/docker/pkg/ulimit"
 	"github.com/docker/docker/utils"
 )
 
@@ -72,11 +73,25 @@ type DeviceMapping struct {
 	CgroupPermissions string
 }
 
+// ThrottleDevice limits the bytes or the operations per second of the block
+// device at Path.
+type ThrottleDevice struct {
+	Path string
+	Rate uint64
+}
+
 type RestartPolicy struct {
 	Name              string
 	MaximumRetryCount int
 }
 
+// LogConfig selects the log driver of a container and holds its options.
+// An empty Type means the daemon's default driver is used.
+type LogConfig struct {
+	Type   string
+	Config map[string]string
+}
+
 type HostConfig struct {
 	Binds           []string
 	ContainerIDFile string
@@ -96,6 +111,15 @@ type HostConfig struct {
 	CapDrop         []string
 	RestartPolicy   RestartPolicy
 	SecurityOpt     []string
+	LogConfig       LogConfig
+	VolumeDriver    string
+	Ulimits         []*ulimit.Ulimit
+	OomScoreAdj     int // Adjusts the preference of the OOM killer, from -1000 to 1000
+
+	BlkioDeviceReadBps   []ThrottleDevice // Bytes per second read from a device
+	BlkioDeviceWriteBps  []ThrottleDevice // Bytes per second written to a device
+	BlkioDeviceReadIOps  []ThrottleDevice // Read operations per second from a device
+	BlkioDeviceWriteIOps []ThrottleDevice // Write operations per second to a device
 }
 
 // This is used by the create command when you want to set both the
@@ -125,12 +149,20 @@ func ContainerHostConfigFromJob(job *engine.Job) *HostConfig {
 		PublishAllPorts: job.GetenvBool("PublishAllPorts"),
 		NetworkMode:     NetworkMode(job.Getenv("NetworkMode")),
 		IpcMode:         IpcMode(job.Getenv("IpcMode")),
+		VolumeDriver:    job.Getenv("VolumeDriver"),
+		OomScoreAdj:     job.GetenvInt("OomScoreAdj"),
 	}
 
 	job.GetenvJson("LxcConf", &hostConfig.LxcConf)
 	job.GetenvJson("PortBindings", &hostConfig.PortBindings)
 	job.GetenvJson("Devices", &hostConfig.Devices)
 	job.GetenvJson("RestartPolicy", &hostConfig.RestartPolicy)
+	job.GetenvJson("LogConfig", &hostConfig.LogConfig)
+	job.GetenvJson("Ulimits", &hostConfig.Ulimits)
+	job.GetenvJson("BlkioDeviceReadBps", &hostConfig.BlkioDeviceReadBps)
+	job.GetenvJson("BlkioDeviceWriteBps", &hostConfig.BlkioDeviceWriteBps)
+	job.GetenvJson("BlkioDeviceReadIOps", &hostConfig.BlkioDeviceReadIOps)
+	job.GetenvJson("BlkioDeviceWriteIOps", &hostConfig.BlkioDeviceWriteIOps)
 	hostConfig.SecurityOpt = job.GetenvList("SecurityOpt")
 	if Binds := job.GetenvList("Binds"); Binds != nil {
 		hostConfig.Binds = Binds
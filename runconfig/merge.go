@@ -84,6 +84,17 @@ func Merge(userConf, imageConf *Config) error {
 		}
 	}
 
+	if len(imageConf.Labels) > 0 {
+		if userConf.Labels == nil {
+			userConf.Labels = make(map[string]string)
+		}
+		for k, v := range imageConf.Labels {
+			if _, exists := userConf.Labels[k]; !exists {
+				userConf.Labels[k] = v
+			}
+		}
+	}
+
 	if len(userConf.Entrypoint) == 0 {
 		if len(userConf.Cmd) == 0 {
 			userConf.Cmd = imageConf.Cmd
@@ -96,6 +107,29 @@ func Merge(userConf, imageConf *Config) error {
 	if userConf.WorkingDir == "" {
 		userConf.WorkingDir = imageConf.WorkingDir
 	}
+	if userConf.StopSignal == "" {
+		userConf.StopSignal = imageConf.StopSignal
+	}
+	if imageConf.Healthcheck != nil {
+		if userConf.Healthcheck == nil {
+			userConf.Healthcheck = imageConf.Healthcheck
+		} else {
+			healthcheck := *userConf.Healthcheck
+			if len(healthcheck.Test) == 0 {
+				healthcheck.Test = imageConf.Healthcheck.Test
+			}
+			if healthcheck.Interval == 0 {
+				healthcheck.Interval = imageConf.Healthcheck.Interval
+			}
+			if healthcheck.Timeout == 0 {
+				healthcheck.Timeout = imageConf.Healthcheck.Timeout
+			}
+			if healthcheck.Retries == 0 {
+				healthcheck.Retries = imageConf.Healthcheck.Retries
+			}
+			userConf.Healthcheck = &healthcheck
+		}
+	}
 	if len(userConf.Volumes) == 0 {
 		userConf.Volumes = imageConf.Volumes
 	} else {
@@ -10,6 +10,7 @@ import (
 	"github.com/docker/docker/opts"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/parsers"
+	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/pkg/units"
 	"github.com/docker/docker/utils"
 )
@@ -43,33 +44,56 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		flCapAdd      = opts.NewListOpts(nil)
 		flCapDrop     = opts.NewListOpts(nil)
 		flSecurityOpt = opts.NewListOpts(nil)
-
-		flNetwork         = cmd.Bool([]string{"#n", "#-networking"}, true, "Enable networking for this container")
-		flPrivileged      = cmd.Bool([]string{"#privileged", "-privileged"}, false, "Give extended privileges to this container")
-		flPublishAll      = cmd.Bool([]string{"P", "-publish-all"}, false, "Publish all exposed ports to the host interfaces")
-		flStdin           = cmd.Bool([]string{"i", "-interactive"}, false, "Keep STDIN open even if not attached")
-		flTty             = cmd.Bool([]string{"t", "-tty"}, false, "Allocate a pseudo-TTY")
-		flContainerIDFile = cmd.String([]string{"#cidfile", "-cidfile"}, "", "Write the container ID to the file")
-		flEntrypoint      = cmd.String([]string{"#entrypoint", "-entrypoint"}, "", "Overwrite the default ENTRYPOINT of the image")
-		flHostname        = cmd.String([]string{"h", "-hostname"}, "", "Container host name")
-		flMemoryString    = cmd.String([]string{"m", "-memory"}, "", "Memory limit (format: <number><optional unit>, where unit = b, k, m or g)")
-		flUser            = cmd.String([]string{"u", "-user"}, "", "Username or UID")
-		flWorkingDir      = cmd.String([]string{"w", "-workdir"}, "", "Working directory inside the container")
-		flCpuShares       = cmd.Int64([]string{"c", "-cpu-shares"}, 0, "CPU shares (relative weight)")
-		flCpuset          = cmd.String([]string{"-cpuset"}, "", "CPUs in which to allow execution (0-3, 0,1)")
-		flNetMode         = cmd.String([]string{"-net"}, "bridge", "Set the Network mode for the container\n'bridge': creates a new network stack for the container on the docker bridge\n'none': no networking for this container\n'container:<name|id>': reuses another container network stack\n'host': use the host network stack inside the container.  Note: the host mode gives the container full access to local system services such as D-bus and is therefore considered insecure.")
-		flMacAddress      = cmd.String([]string{"-mac-address"}, "", "Container MAC address (e.g. 92:d0:c6:0a:29:33)")
-		flIpcMode         = cmd.String([]string{"-ipc"}, "", "Default is to create a private IPC namespace (POSIX SysV IPC) for the container\n'container:<name|id>': reuses another container shared memory, semaphores and message queues\n'host': use the host shared memory,semaphores and message queues inside the container.  Note: the host mode gives the container full access to local shared memory and is therefore considered insecure.")
-		flRestartPolicy   = cmd.String([]string{"-restart"}, "", "Restart policy to apply when a container exits (no, on-failure[:max-retry], always)")
+		flLogOpts     = opts.NewListOpts(opts.ValidateLogOpt)
+		flLabels      = opts.NewListOpts(nil)
+		flUlimits     = opts.NewUlimitOpt(nil)
+
+		flDeviceReadBps   = opts.NewListOpts(nil)
+		flDeviceWriteBps  = opts.NewListOpts(nil)
+		flDeviceReadIOps  = opts.NewListOpts(nil)
+		flDeviceWriteIOps = opts.NewListOpts(nil)
+
+		flNetwork           = cmd.Bool([]string{"#n", "#-networking"}, true, "Enable networking for this container")
+		flPrivileged        = cmd.Bool([]string{"#privileged", "-privileged"}, false, "Give extended privileges to this container")
+		flPublishAll        = cmd.Bool([]string{"P", "-publish-all"}, false, "Publish all exposed ports to the host interfaces")
+		flStdin             = cmd.Bool([]string{"i", "-interactive"}, false, "Keep STDIN open even if not attached")
+		flTty               = cmd.Bool([]string{"t", "-tty"}, false, "Allocate a pseudo-TTY")
+		flContainerIDFile   = cmd.String([]string{"#cidfile", "-cidfile"}, "", "Write the container ID to the file")
+		flEntrypoint        = cmd.String([]string{"#entrypoint", "-entrypoint"}, "", "Overwrite the default ENTRYPOINT of the image")
+		flHostname          = cmd.String([]string{"h", "-hostname"}, "", "Container host name")
+		flMemoryString      = cmd.String([]string{"m", "-memory"}, "", "Memory limit (format: <number><optional unit>, where unit = b, k, m or g)")
+		flMemoryReservation = cmd.String([]string{"-memory-reservation"}, "", "Memory soft limit (format: <number><optional unit>, where unit = b, k, m or g)")
+		flKernelMemory      = cmd.String([]string{"-kernel-memory"}, "", "Kernel memory limit (format: <number><optional unit>, where unit = b, k, m or g)")
+		flOomKillDisable    = cmd.Bool([]string{"-oom-kill-disable"}, false, "Disable the OOM killer for the container")
+		flOomScoreAdj       = cmd.Int([]string{"-oom-score-adj"}, 0, "Tune the OOM preference of the container on the host (-1000 to 1000)")
+		flUser              = cmd.String([]string{"u", "-user"}, "", "Username or UID")
+		flWorkingDir        = cmd.String([]string{"w", "-workdir"}, "", "Working directory inside the container")
+		flCpuShares         = cmd.Int64([]string{"c", "-cpu-shares"}, 0, "CPU shares (relative weight)")
+		flCpuQuota          = cmd.Int64([]string{"-cpu-quota"}, 0, "Limit the CPU time in each CFS (Completely Fair Scheduler) period, in microseconds")
+		flCpuPeriod         = cmd.Int64([]string{"-cpu-period"}, 0, "Limit the CPU CFS (Completely Fair Scheduler) period, in microseconds")
+		flBlkioWeight       = cmd.Int64([]string{"-blkio-weight"}, 0, "Block IO weight (relative weight), between 10 and 1000")
+		flCpuset            = cmd.String([]string{"-cpuset"}, "", "CPUs in which to allow execution (0-3, 0,1)")
+		flNetMode           = cmd.String([]string{"-net"}, "bridge", "Set the Network mode for the container\n'bridge': creates a new network stack for the container on the docker bridge\n'none': no networking for this container\n'container:<name|id>': reuses another container network stack\n'host': use the host network stack inside the container.  Note: the host mode gives the container full access to local system services such as D-bus and is therefore considered insecure.")
+		flMacAddress        = cmd.String([]string{"-mac-address"}, "", "Container MAC address (e.g. 92:d0:c6:0a:29:33)")
+		flIpcMode           = cmd.String([]string{"-ipc"}, "", "Default is to create a private IPC namespace (POSIX SysV IPC) for the container\n'container:<name|id>': reuses another container shared memory, semaphores and message queues\n'host': use the host shared memory,semaphores and message queues inside the container.  Note: the host mode gives the container full access to local shared memory and is therefore considered insecure.")
+		flRestartPolicy     = cmd.String([]string{"-restart"}, "", "Restart policy to apply when a container exits (no, on-failure[:max-retry], always)")
+		flLogDriver         = cmd.String([]string{"-log-driver"}, "", "Logging driver for the container (json-file, syslog, none)")
+		flVolumeDriver      = cmd.String([]string{"-volume-driver"}, "", "Volume driver creating the volumes of the container")
+		flHealthCmd         = cmd.String([]string{"-health-cmd"}, "", "Command to run to check health")
+		flHealthInterval    = cmd.Duration([]string{"-health-interval"}, 0, "Time between running the check")
+		flHealthTimeout     = cmd.Duration([]string{"-health-timeout"}, 0, "Maximum time to allow one check to run")
+		flHealthRetries     = cmd.Int([]string{"-health-retries"}, 0, "Consecutive failures needed to report unhealthy")
+		flStopSignal        = cmd.String([]string{"-stop-signal"}, signal.DefaultStopSignal, "Signal to stop a container")
 	)
 
 	cmd.Var(&flAttach, []string{"a", "-attach"}, "Attach to STDIN, STDOUT or STDERR.")
-	cmd.Var(&flVolumes, []string{"v", "-volume"}, "Bind mount a volume (e.g., from the host: -v /host:/container, from Docker: -v /container)")
+	cmd.Var(&flVolumes, []string{"v", "-volume"}, "Bind mount a volume (e.g., from the host: -v /host:/container, a named volume: -v name:/container, from Docker: -v /container)")
 	cmd.Var(&flLinks, []string{"#link", "-link"}, "Add link to another container in the form of name:alias")
 	cmd.Var(&flDevices, []string{"-device"}, "Add a host device to the container (e.g. --device=/dev/sdc:/dev/xvdc:rwm)")
 
 	cmd.Var(&flEnv, []string{"e", "-env"}, "Set environment variables")
 	cmd.Var(&flEnvFile, []string{"-env-file"}, "Read in a line delimited file of environment variables")
+	cmd.Var(&flLabels, []string{"l", "-label"}, "Set meta data on a container (e.g. --label=com.example.key=value)")
 
 	cmd.Var(&flPublish, []string{"p", "-publish"}, fmt.Sprintf("Publish a container's port to the host\nformat: %s\n(use 'docker port' to see the actual mapping)", nat.PortSpecTemplateFormat))
 	cmd.Var(&flExpose, []string{"#expose", "-expose"}, "Expose a port or a range of ports (e.g. --expose=3300-3310) from the container without publishing it to your host")
@@ -82,6 +106,12 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 	cmd.Var(&flCapAdd, []string{"-cap-add"}, "Add Linux capabilities")
 	cmd.Var(&flCapDrop, []string{"-cap-drop"}, "Drop Linux capabilities")
 	cmd.Var(&flSecurityOpt, []string{"-security-opt"}, "Security Options")
+	cmd.Var(&flLogOpts, []string{"-log-opt"}, "Log driver options (e.g. max-size=10m, max-file=3)")
+	cmd.Var(flUlimits, []string{"-ulimit"}, "Ulimit options (e.g. nofile=1024:2048)")
+	cmd.Var(&flDeviceReadBps, []string{"-device-read-bps"}, "Limit the read rate from a device (e.g. --device-read-bps=/dev/sda:1mb)")
+	cmd.Var(&flDeviceWriteBps, []string{"-device-write-bps"}, "Limit the write rate to a device (e.g. --device-write-bps=/dev/sda:1mb)")
+	cmd.Var(&flDeviceReadIOps, []string{"-device-read-iops"}, "Limit the read operations per second from a device (e.g. --device-read-iops=/dev/sda:1000)")
+	cmd.Var(&flDeviceWriteIOps, []string{"-device-write-iops"}, "Limit the write operations per second to a device (e.g. --device-write-iops=/dev/sda:1000)")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil, nil, cmd, err
@@ -136,6 +166,24 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		flMemory = parsedMemory
 	}
 
+	var flMemoryReservationBytes int64
+	if *flMemoryReservation != "" {
+		parsedMemoryReservation, err := units.RAMInBytes(*flMemoryReservation)
+		if err != nil {
+			return nil, nil, cmd, err
+		}
+		flMemoryReservationBytes = parsedMemoryReservation
+	}
+
+	var flKernelMemoryBytes int64
+	if *flKernelMemory != "" {
+		parsedKernelMemory, err := units.RAMInBytes(*flKernelMemory)
+		if err != nil {
+			return nil, nil, cmd, err
+		}
+		flKernelMemoryBytes = parsedKernelMemory
+	}
+
 	var binds []string
 	// add any bind targets to the list of container volumes
 	for bind := range flVolumes.GetMap() {
@@ -224,6 +272,24 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		deviceMappings = append(deviceMappings, deviceMapping)
 	}
 
+	// parse the limits of block devices
+	deviceReadBps, err := parseThrottleDevices(flDeviceReadBps, true)
+	if err != nil {
+		return nil, nil, cmd, err
+	}
+	deviceWriteBps, err := parseThrottleDevices(flDeviceWriteBps, true)
+	if err != nil {
+		return nil, nil, cmd, err
+	}
+	deviceReadIOps, err := parseThrottleDevices(flDeviceReadIOps, false)
+	if err != nil {
+		return nil, nil, cmd, err
+	}
+	deviceWriteIOps, err := parseThrottleDevices(flDeviceWriteIOps, false)
+	if err != nil {
+		return nil, nil, cmd, err
+	}
+
 	// collect all the environment variables for the container
 	envVariables := []string{}
 	for _, ef := range flEnvFile.GetAll() {
@@ -246,54 +312,106 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		return nil, nil, cmd, fmt.Errorf("--net: invalid net mode: %v", err)
 	}
 
-	restartPolicy, err := parseRestartPolicy(*flRestartPolicy)
+	restartPolicy, err := ParseRestartPolicy(*flRestartPolicy)
+	if err != nil {
+		return nil, nil, cmd, err
+	}
+
+	logOpts, err := parseLogOpts(flLogOpts)
 	if err != nil {
 		return nil, nil, cmd, err
 	}
 
+	var healthConfig *HealthConfig
+	if *flHealthCmd != "" || *flHealthInterval != 0 || *flHealthTimeout != 0 || *flHealthRetries != 0 {
+		if *flHealthInterval < 0 {
+			return nil, nil, cmd, fmt.Errorf("--health-interval cannot be negative")
+		}
+		if *flHealthTimeout < 0 {
+			return nil, nil, cmd, fmt.Errorf("--health-timeout cannot be negative")
+		}
+		if *flHealthRetries < 0 {
+			return nil, nil, cmd, fmt.Errorf("--health-retries cannot be negative")
+		}
+		healthConfig = &HealthConfig{
+			Interval: *flHealthInterval,
+			Timeout:  *flHealthTimeout,
+			Retries:  *flHealthRetries,
+		}
+		if *flHealthCmd != "" {
+			healthConfig.Test = []string{"CMD-SHELL", *flHealthCmd}
+		}
+	}
+
+	// Keep the stop signal of the image unless one is given
+	var stopSignal string
+	if cmd.IsSet("-stop-signal") {
+		if _, err := signal.ParseSignal(*flStopSignal); err != nil {
+			return nil, nil, cmd, err
+		}
+		stopSignal = *flStopSignal
+	}
+
 	config := &Config{
-		Hostname:        hostname,
-		Domainname:      domainname,
-		PortSpecs:       nil, // Deprecated
-		ExposedPorts:    ports,
-		User:            *flUser,
-		Tty:             *flTty,
-		NetworkDisabled: !*flNetwork,
-		OpenStdin:       *flStdin,
-		Memory:          flMemory,
-		CpuShares:       *flCpuShares,
-		Cpuset:          *flCpuset,
-		AttachStdin:     attachStdin,
-		AttachStdout:    attachStdout,
-		AttachStderr:    attachStderr,
-		Env:             envVariables,
-		Cmd:             runCmd,
-		Image:           image,
-		Volumes:         flVolumes.GetMap(),
-		MacAddress:      *flMacAddress,
-		Entrypoint:      entrypoint,
-		WorkingDir:      *flWorkingDir,
+		Hostname:          hostname,
+		Domainname:        domainname,
+		PortSpecs:         nil, // Deprecated
+		ExposedPorts:      ports,
+		User:              *flUser,
+		Tty:               *flTty,
+		NetworkDisabled:   !*flNetwork,
+		OpenStdin:         *flStdin,
+		Memory:            flMemory,
+		MemoryReservation: flMemoryReservationBytes,
+		KernelMemory:      flKernelMemoryBytes,
+		OomKillDisable:    *flOomKillDisable,
+		CpuShares:         *flCpuShares,
+		CpuQuota:          *flCpuQuota,
+		CpuPeriod:         *flCpuPeriod,
+		Cpuset:            *flCpuset,
+		BlkioWeight:       *flBlkioWeight,
+		AttachStdin:       attachStdin,
+		AttachStdout:      attachStdout,
+		AttachStderr:      attachStderr,
+		Env:               envVariables,
+		Labels:            convertKVStringsToMap(flLabels.GetAll()),
+		Cmd:               runCmd,
+		Image:             image,
+		Volumes:           flVolumes.GetMap(),
+		MacAddress:        *flMacAddress,
+		Entrypoint:        entrypoint,
+		WorkingDir:        *flWorkingDir,
+		Healthcheck:       healthConfig,
+		StopSignal:        stopSignal,
 	}
 
 	hostConfig := &HostConfig{
-		Binds:           binds,
-		ContainerIDFile: *flContainerIDFile,
-		LxcConf:         lxcConf,
-		Privileged:      *flPrivileged,
-		PortBindings:    portBindings,
-		Links:           flLinks.GetAll(),
-		PublishAllPorts: *flPublishAll,
-		Dns:             flDns.GetAll(),
-		DnsSearch:       flDnsSearch.GetAll(),
-		ExtraHosts:      flExtraHosts.GetAll(),
-		VolumesFrom:     flVolumesFrom.GetAll(),
-		NetworkMode:     netMode,
-		IpcMode:         ipcMode,
-		Devices:         deviceMappings,
-		CapAdd:          flCapAdd.GetAll(),
-		CapDrop:         flCapDrop.GetAll(),
-		RestartPolicy:   restartPolicy,
-		SecurityOpt:     flSecurityOpt.GetAll(),
+		Binds:                binds,
+		ContainerIDFile:      *flContainerIDFile,
+		LxcConf:              lxcConf,
+		Privileged:           *flPrivileged,
+		PortBindings:         portBindings,
+		Links:                flLinks.GetAll(),
+		PublishAllPorts:      *flPublishAll,
+		Dns:                  flDns.GetAll(),
+		DnsSearch:            flDnsSearch.GetAll(),
+		ExtraHosts:           flExtraHosts.GetAll(),
+		VolumesFrom:          flVolumesFrom.GetAll(),
+		NetworkMode:          netMode,
+		IpcMode:              ipcMode,
+		Devices:              deviceMappings,
+		CapAdd:               flCapAdd.GetAll(),
+		CapDrop:              flCapDrop.GetAll(),
+		RestartPolicy:        restartPolicy,
+		SecurityOpt:          flSecurityOpt.GetAll(),
+		LogConfig:            LogConfig{Type: *flLogDriver, Config: logOpts},
+		VolumeDriver:         *flVolumeDriver,
+		Ulimits:              flUlimits.GetList(),
+		OomScoreAdj:          *flOomScoreAdj,
+		BlkioDeviceReadBps:   deviceReadBps,
+		BlkioDeviceWriteBps:  deviceWriteBps,
+		BlkioDeviceReadIOps:  deviceReadIOps,
+		BlkioDeviceWriteIOps: deviceWriteIOps,
 	}
 
 	// When allocating stdin in attached mode, close stdin at client disconnect
@@ -303,8 +421,8 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 	return config, hostConfig, cmd, nil
 }
 
-// parseRestartPolicy returns the parsed policy or an error indicating what is incorrect
-func parseRestartPolicy(policy string) (RestartPolicy, error) {
+// ParseRestartPolicy returns the parsed policy or an error indicating what is incorrect
+func ParseRestartPolicy(policy string) (RestartPolicy, error) {
 	p := RestartPolicy{}
 
 	if policy == "" {
@@ -343,6 +461,25 @@ func parseRestartPolicy(policy string) (RestartPolicy, error) {
 	return p, nil
 }
 
+// ValidateRestartPolicy checks a restart policy which was not parsed with
+// ParseRestartPolicy, like the ones given through the API. A maximum retry
+// count is only valid with the on-failure policy.
+func ValidateRestartPolicy(policy RestartPolicy) error {
+	switch policy.Name {
+	case "", "no", "always":
+		if policy.MaximumRetryCount != 0 {
+			return fmt.Errorf("maximum restart count not valid with restart policy of %q", policy.Name)
+		}
+	case "on-failure":
+		if policy.MaximumRetryCount < 0 {
+			return fmt.Errorf("maximum restart count must be a positive integer")
+		}
+	default:
+		return fmt.Errorf("invalid restart policy %s", policy.Name)
+	}
+	return nil
+}
+
 // options will come in the format of name.key=value or name.option
 func parseDriverOpts(opts opts.ListOpts) (map[string][]string, error) {
 	out := make(map[string][]string, len(opts.GetAll()))
@@ -374,6 +511,36 @@ func parseKeyValueOpts(opts opts.ListOpts) ([]utils.KeyValuePair, error) {
 	return out, nil
 }
 
+func parseLogOpts(opts opts.ListOpts) (map[string]string, error) {
+	out := make(map[string]string, opts.Len())
+	for _, o := range opts.GetAll() {
+		k, v, err := parsers.ParseKeyValueOpt(o)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// convertKVStringsToMap converts ["key=value"] to {"key":"value"}. A string
+// without "=" maps to an empty value.
+func convertKVStringsToMap(values []string) map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(values))
+	for _, value := range values {
+		kv := strings.SplitN(value, "=", 2)
+		if len(kv) == 1 {
+			result[kv[0]] = ""
+		} else {
+			result[kv[0]] = kv[1]
+		}
+	}
+	return result
+}
+
 func parseNetMode(netMode string) (NetworkMode, error) {
 	parts := strings.Split(netMode, ":")
 	switch mode := parts[0]; mode {
@@ -388,6 +555,40 @@ func parseNetMode(netMode string) (NetworkMode, error) {
 	return NetworkMode(netMode), nil
 }
 
+// ParseThrottleDevice parses a limit of a block device in the format
+// <device-path>:<rate>. A rate of bytes per second accepts a unit.
+func ParseThrottleDevice(val string, bytes bool) (ThrottleDevice, error) {
+	i := strings.LastIndex(val, ":")
+	if i == -1 || !path.IsAbs(val[:i]) {
+		return ThrottleDevice{}, fmt.Errorf("Invalid device rate: %s, the format is <device-path>:<rate>", val)
+	}
+	var (
+		rate int64
+		err  error
+	)
+	if bytes {
+		rate, err = units.RAMInBytes(val[i+1:])
+	} else {
+		rate, err = strconv.ParseInt(val[i+1:], 10, 64)
+	}
+	if err != nil || rate <= 0 {
+		return ThrottleDevice{}, fmt.Errorf("Invalid rate for device: %s, the rate must be a positive number", val)
+	}
+	return ThrottleDevice{Path: val[:i], Rate: uint64(rate)}, nil
+}
+
+func parseThrottleDevices(opts opts.ListOpts, bytes bool) ([]ThrottleDevice, error) {
+	var devices []ThrottleDevice
+	for _, val := range opts.GetAll() {
+		device, err := ParseThrottleDevice(val, bytes)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
 func ParseDevice(device string) (DeviceMapping, error) {
 	src := ""
 	dst := ""
@@ -3,6 +3,7 @@ package runconfig
 import (
 	"io/ioutil"
 	"testing"
+	"time"
 
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/parsers"
@@ -57,3 +58,147 @@ func TestNetHostname(t *testing.T) {
 		t.Fatalf("Expected error ErrConflictNetworkHostname, got: %s", err)
 	}
 }
+
+func TestParseLabels(t *testing.T) {
+	config, _, _, err := parseRun([]string{"--label", "com.example.key=value", "-l", "com.example.flag", "--label=com.example.eq=a=b", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"com.example.key":  "value",
+		"com.example.flag": "",
+		"com.example.eq":   "a=b",
+	}
+	if len(config.Labels) != len(expected) {
+		t.Fatalf("Expected labels %v, got %v", expected, config.Labels)
+	}
+	for k, v := range expected {
+		if config.Labels[k] != v {
+			t.Fatalf("Expected label %s=%s, got %v", k, v, config.Labels)
+		}
+	}
+}
+
+func TestParseHealth(t *testing.T) {
+	config, _, _, err := parseRun([]string{"img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Healthcheck != nil {
+		t.Fatalf("Expected no healthcheck, got %v", config.Healthcheck)
+	}
+
+	config, _, _, err = parseRun([]string{"--health-cmd=curl -f http://localhost/", "--health-interval=5s", "--health-timeout=2s", "--health-retries=4", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	health := config.Healthcheck
+	if len(health.Test) != 2 || health.Test[0] != "CMD-SHELL" || health.Test[1] != "curl -f http://localhost/" {
+		t.Fatalf("Unexpected health test %v", health.Test)
+	}
+	if health.Interval != 5*time.Second || health.Timeout != 2*time.Second || health.Retries != 4 {
+		t.Fatalf("Unexpected health settings %v", health)
+	}
+
+	// Settings can be changed without overriding the probe of the image
+	config, _, _, err = parseRun([]string{"--health-retries=2", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Healthcheck.Test) != 0 || config.Healthcheck.Retries != 2 {
+		t.Fatalf("Unexpected healthcheck %v", config.Healthcheck)
+	}
+
+	if _, _, _, err := parseRun([]string{"--health-interval=-1s", "img", "cmd"}); err == nil {
+		t.Fatal("Expected an error with a negative interval")
+	}
+}
+
+func TestParseStopSignal(t *testing.T) {
+	config, _, _, err := parseRun([]string{"img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.StopSignal != "" {
+		t.Fatalf("Expected the stop signal of the image to be kept, got %s", config.StopSignal)
+	}
+
+	config, _, _, err = parseRun([]string{"--stop-signal=SIGKILL", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.StopSignal != "SIGKILL" {
+		t.Fatalf("Expected the stop signal to be SIGKILL, got %s", config.StopSignal)
+	}
+
+	if _, _, _, err := parseRun([]string{"--stop-signal=SIGFOO", "img", "cmd"}); err == nil {
+		t.Fatal("Expected an error with an invalid signal")
+	}
+}
+
+func TestParseResources(t *testing.T) {
+	config, hostConfig, _, err := parseRun([]string{
+		"--memory-reservation=32m", "--kernel-memory=16m", "--oom-kill-disable", "--oom-score-adj=-500",
+		"--cpu-quota=50000", "--cpu-period=100000", "--blkio-weight=300",
+		"--device-read-bps=/dev/sda:1mb", "--device-write-iops=/dev/sdb:100",
+		"img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.MemoryReservation != 33554432 || config.KernelMemory != 16777216 || !config.OomKillDisable {
+		t.Fatalf("Unexpected memory settings: %d, %d, %v", config.MemoryReservation, config.KernelMemory, config.OomKillDisable)
+	}
+	if config.CpuQuota != 50000 || config.CpuPeriod != 100000 || config.BlkioWeight != 300 {
+		t.Fatalf("Unexpected CPU or block IO settings: %d, %d, %d", config.CpuQuota, config.CpuPeriod, config.BlkioWeight)
+	}
+	if hostConfig.OomScoreAdj != -500 {
+		t.Fatalf("Expected the OOM score adjustment to be -500, got %d", hostConfig.OomScoreAdj)
+	}
+	if len(hostConfig.BlkioDeviceReadBps) != 1 || hostConfig.BlkioDeviceReadBps[0] != (ThrottleDevice{Path: "/dev/sda", Rate: 1048576}) {
+		t.Fatalf("Unexpected read limit: %v", hostConfig.BlkioDeviceReadBps)
+	}
+	if len(hostConfig.BlkioDeviceWriteIOps) != 1 || hostConfig.BlkioDeviceWriteIOps[0] != (ThrottleDevice{Path: "/dev/sdb", Rate: 100}) {
+		t.Fatalf("Unexpected write limit: %v", hostConfig.BlkioDeviceWriteIOps)
+	}
+}
+
+func TestParseThrottleDevice(t *testing.T) {
+	invalids := map[string]bool{
+		"/dev/sda":       true,
+		"sda:1mb":        true,
+		"/dev/sda:":      true,
+		"/dev/sda:-1":    true,
+		"/dev/sda:0":     true,
+		"/dev/sda:1mb":   false,
+		"/dev/sda:12345": false,
+	}
+	for val, invalid := range invalids {
+		if _, err := ParseThrottleDevice(val, true); (err != nil) != invalid {
+			t.Fatalf("Unexpected result parsing %s: %v", val, err)
+		}
+	}
+	if _, err := ParseThrottleDevice("/dev/sda:1mb", false); err == nil {
+		t.Fatal("Expected an error with a unit in a rate of operations")
+	}
+}
+
+func TestValidateRestartPolicy(t *testing.T) {
+	for _, c := range []struct {
+		policy  RestartPolicy
+		invalid bool
+	}{
+		{RestartPolicy{}, false},
+		{RestartPolicy{Name: "no"}, false},
+		{RestartPolicy{Name: "always"}, false},
+		{RestartPolicy{Name: "on-failure"}, false},
+		{RestartPolicy{Name: "on-failure", MaximumRetryCount: 3}, false},
+		{RestartPolicy{Name: "on-failure", MaximumRetryCount: -1}, true},
+		{RestartPolicy{Name: "always", MaximumRetryCount: 3}, true},
+		{RestartPolicy{Name: "no", MaximumRetryCount: 3}, true},
+		{RestartPolicy{Name: "sometimes"}, true},
+	} {
+		if err := ValidateRestartPolicy(c.policy); (err != nil) != c.invalid {
+			t.Fatalf("Unexpected result validating %v: %v", c.policy, err)
+		}
+	}
+}
@@ -189,6 +189,21 @@ func GenerateRandomID() string {
 	}
 }
 
+// DigestReference returns whether the reference of an image in a repository
+// is the digest of its manifest rather than a tag.
+func DigestReference(ref string) bool {
+	return strings.Contains(ref, ":")
+}
+
+// ImageReference returns the name of the image with the reference ref in the
+// repository repo, either repo:tag or repo@digest.
+func ImageReference(repo, ref string) string {
+	if DigestReference(ref) {
+		return repo + "@" + ref
+	}
+	return repo + ":" + ref
+}
+
 func ValidateID(id string) error {
 	if id == "" {
 		return fmt.Errorf("Id can't be empty")
@@ -438,18 +453,49 @@ func ReadSymlinkedDirectory(path string) (string, error) {
 	return realPath, nil
 }
 
+// ReadDockerIgnore reads the patterns of the .dockerignore file at path.
+// Exceptions keep their leading "!". A missing file has no patterns.
+func ReadDockerIgnore(path string) ([]string, error) {
+	ignore, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Error reading .dockerignore: '%s'", err)
+	}
+	var excludes []string
+	for _, pattern := range strings.Split(string(ignore), "\n") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if strings.HasPrefix(pattern, "!") {
+			pattern = "!" + filepath.Clean(strings.TrimSpace(pattern[1:]))
+		} else {
+			pattern = filepath.Clean(pattern)
+		}
+		if _, err := fileutils.NewPatternMatcher([]string{pattern}); err != nil {
+			return nil, fmt.Errorf("Bad .dockerignore pattern: '%s', error: %s", pattern, err)
+		}
+		excludes = append(excludes, pattern)
+	}
+	return excludes, nil
+}
+
 // ValidateContextDirectory checks if all the contents of the directory
 // can be read and returns an error if some files can't be read
 // symlinks which point to non-existing files don't trigger an error
 func ValidateContextDirectory(srcPath string, excludes []string) error {
+	pm, err := fileutils.NewPatternMatcher(excludes)
+	if err != nil {
+		return err
+	}
 	return filepath.Walk(filepath.Join(srcPath, "."), func(filePath string, f os.FileInfo, err error) error {
 		// skip this directory/file if it's not in the path, it won't get added to the context
 		if relFilePath, err := filepath.Rel(srcPath, filePath); err != nil {
 			return err
-		} else if skip, err := fileutils.Matches(relFilePath, excludes); err != nil {
-			return err
-		} else if skip {
-			if f.IsDir() {
+		} else if pm.Matches(relFilePath) {
+			if f.IsDir() && !pm.HasExceptions() {
 				return filepath.SkipDir
 			}
 			return nil
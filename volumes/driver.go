@@ -0,0 +1,128 @@
+package volumes
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/docker/docker/pkg/plugins"
+)
+
+// DefaultDriverName is the name of the driver creating volumes on the
+// local graph driver.
+const DefaultDriverName = "local"
+
+// VolumeDriver creates volumes on storage other than the local graph
+// driver. Volumes are identified by name: the name of a named volume, or
+// the ID of an anonymous one.
+type VolumeDriver interface {
+	// Create creates the volume name.
+	Create(name string) error
+	// Remove removes the volume name and its data.
+	Remove(name string) error
+	// Path returns the host path at which the volume name is mounted.
+	Path(name string) (string, error)
+	// Mount makes the volume name available for a container and returns
+	// the host path at which it is mounted. Every call to Mount is
+	// balanced by a call to Unmount.
+	Mount(name string) (string, error)
+	// Unmount releases the volume name for a container.
+	Unmount(name string) error
+}
+
+var (
+	drivers     = make(map[string]VolumeDriver)
+	driversLock sync.Mutex
+)
+
+// RegisterDriver makes the volume driver d available as name.
+func RegisterDriver(name string, d VolumeDriver) error {
+	driversLock.Lock()
+	defer driversLock.Unlock()
+
+	if name == DefaultDriverName {
+		return fmt.Errorf("Volume driver name %s is reserved", name)
+	}
+	if _, exists := drivers[name]; exists {
+		return fmt.Errorf("Volume driver %s is already registered", name)
+	}
+	drivers[name] = d
+	return nil
+}
+
+// GetDriver returns the volume driver called name. If no driver was
+// registered with that name, it looks for a plugin implementing
+// VolumeDriver. The plugin is activated without holding the lock.
+func GetDriver(name string) (VolumeDriver, error) {
+	driversLock.Lock()
+	d, exists := drivers[name]
+	driversLock.Unlock()
+	if exists {
+		return d, nil
+	}
+
+	p, err := plugins.Get(name, "VolumeDriver")
+	if err != nil {
+		if err == plugins.ErrNotFound {
+			return nil, fmt.Errorf("No such volume driver: %s", name)
+		}
+		return nil, err
+	}
+
+	driversLock.Lock()
+	defer driversLock.Unlock()
+	if d, exists := drivers[name]; exists {
+		return d, nil
+	}
+	d = &volumeDriverProxy{p.Client}
+	drivers[name] = d
+	return d, nil
+}
+
+// volumeDriverProxy implements VolumeDriver by forwarding the calls to a
+// plugin.
+type volumeDriverProxy struct {
+	c *plugins.Client
+}
+
+type volumeDriverRequest struct {
+	Name string
+}
+
+type volumeDriverResponse struct {
+	Mountpoint string `json:",omitempty"`
+	Err        string `json:",omitempty"`
+}
+
+func (p *volumeDriverProxy) call(method, name string) (string, error) {
+	var ret volumeDriverResponse
+	if err := p.c.Call("VolumeDriver."+method, volumeDriverRequest{name}, &ret); err != nil {
+		return "", err
+	}
+	if ret.Err != "" {
+		return "", fmt.Errorf("VolumeDriver.%s: %s", method, ret.Err)
+	}
+	return ret.Mountpoint, nil
+}
+
+func (p *volumeDriverProxy) Create(name string) error {
+	_, err := p.call("Create", name)
+	return err
+}
+
+func (p *volumeDriverProxy) Remove(name string) error {
+	_, err := p.call("Remove", name)
+	return err
+}
+
+func (p *volumeDriverProxy) Path(name string) (string, error) {
+	return p.call("Path", name)
+}
+
+func (p *volumeDriverProxy) Mount(name string) (string, error) {
+	return p.call("Mount", name)
+}
+
+func (p *volumeDriverProxy) Unmount(name string) error {
+	_, err := p.call("Unmount", name)
+	return err
+}
@@ -0,0 +1,146 @@
+package volumes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/pkg/plugins"
+)
+
+// testPlugin is a stand-in for a volume driver plugin, storing its volumes
+// in a local directory.
+type testPlugin struct {
+	root  string
+	mu    sync.Mutex
+	calls []string
+}
+
+func (p *testPlugin) serve(l net.Listener) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/Plugin.Activate", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"Implements": ["VolumeDriver"]}`)
+	})
+	for _, method := range []string{"Create", "Remove", "Path", "Mount", "Unmount"} {
+		method := method
+		mux.HandleFunc("/VolumeDriver."+method, func(w http.ResponseWriter, r *http.Request) {
+			var req volumeDriverRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			p.mu.Lock()
+			p.calls = append(p.calls, method+" "+req.Name)
+			p.mu.Unlock()
+
+			path := filepath.Join(p.root, req.Name)
+			var resp volumeDriverResponse
+			switch method {
+			case "Create":
+				if err := os.MkdirAll(path, 0755); err != nil {
+					resp.Err = err.Error()
+				}
+			case "Remove":
+				if err := os.RemoveAll(path); err != nil {
+					resp.Err = err.Error()
+				}
+			case "Path", "Mount":
+				resp.Mountpoint = path
+			}
+			json.NewEncoder(w).Encode(resp)
+		})
+	}
+	http.Serve(l, mux)
+}
+
+func (p *testPlugin) popCalls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	calls := p.calls
+	p.calls = nil
+	return calls
+}
+
+func expectCalls(t *testing.T, p *testPlugin, expected ...string) {
+	calls := p.popCalls()
+	if fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Fatalf("Expected calls %v to the plugin, got %v", expected, calls)
+	}
+}
+
+func TestRepositoryVolumeDriverPlugin(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-volumes-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	oldSocketsPath := plugins.SocketsPath
+	plugins.SocketsPath = filepath.Join(root, "plugins")
+	defer func() { plugins.SocketsPath = oldSocketsPath }()
+	if err := os.MkdirAll(plugins.SocketsPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("unix", filepath.Join(plugins.SocketsPath, "teststore.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	p := &testPlugin{root: filepath.Join(root, "store")}
+	go p.serve(l)
+
+	repo := newTestRepository(t, root)
+	if _, err := repo.Create("data", "nosuchdriver"); err == nil {
+		t.Fatal("Expected an error creating a volume with a missing driver")
+	}
+
+	v, err := repo.Create("data", "teststore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectCalls(t, p, "Create data", "Path data")
+	if v.DriverName != "teststore" || v.Path != filepath.Join(p.root, "data") {
+		t.Fatalf("Unexpected volume driver %s and path %s", v.DriverName, v.Path)
+	}
+	if _, err := repo.FindOrCreateNamedVolume("data", DefaultDriverName); err == nil {
+		t.Fatal("Expected an error looking up a volume with another driver")
+	}
+
+	anon, err := repo.Create("", "teststore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectCalls(t, p, "Create "+anon.ID, "Path "+anon.ID)
+
+	if err := v.Mount(); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Unmount(); err != nil {
+		t.Fatal(err)
+	}
+	expectCalls(t, p, "Mount data", "Unmount data")
+
+	// The driver of the volume survives a restart of the repository
+	repo = newTestRepository(t, root)
+	restored, err := repo.Lookup("data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.DriverName != "teststore" || restored.Path != v.Path {
+		t.Fatalf("Unexpected restored volume driver %s and path %s", restored.DriverName, restored.Path)
+	}
+
+	if err := repo.Delete(restored.Path); err != nil {
+		t.Fatal(err)
+	}
+	expectCalls(t, p, "Remove data")
+	if _, err := os.Stat(v.Path); !os.IsNotExist(err) {
+		t.Fatalf("Expected the volume to be removed by the plugin, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sync"
 
 	log "github.com/Sirupsen/logrus"
@@ -12,10 +13,18 @@ import (
 	"github.com/docker/docker/utils"
 )
 
+var validVolumeName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// IsValidName returns whether name can be used as the name of a volume.
+func IsValidName(name string) bool {
+	return validVolumeName.MatchString(name)
+}
+
 type Repository struct {
 	configPath string
 	driver     graphdriver.Driver
 	volumes    map[string]*Volume
+	names      map[string]*Volume
 	lock       sync.Mutex
 }
 
@@ -34,12 +43,13 @@ func NewRepository(configPath string, driver graphdriver.Driver) (*Repository, e
 		driver:     driver,
 		configPath: abspath,
 		volumes:    make(map[string]*Volume),
+		names:      make(map[string]*Volume),
 	}
 
 	return repo, repo.restore()
 }
 
-func (r *Repository) newVolume(path string, writable bool) (*Volume, error) {
+func (r *Repository) newVolume(name, path, driverName string, writable bool) (*Volume, error) {
 	var (
 		isBindMount bool
 		err         error
@@ -47,23 +57,39 @@ func (r *Repository) newVolume(path string, writable bool) (*Volume, error) {
 	)
 	if path != "" {
 		isBindMount = true
+		driverName = ""
+	} else if driverName == "" {
+		driverName = DefaultDriverName
 	}
 
 	if path == "" {
-		path, err = r.createNewVolumePath(id)
+		if driverName == DefaultDriverName {
+			path, err = r.createNewVolumePath(id)
+		} else {
+			ref := name
+			if ref == "" {
+				ref = id
+			}
+			path, err = createDriverVolume(driverName, ref)
+		}
 		if err != nil {
 			return nil, err
 		}
 	}
 	path = filepath.Clean(path)
 
-	path, err = filepath.EvalSymlinks(path)
-	if err != nil {
-		return nil, err
+	// The path of a volume driver may only exist while the volume is mounted
+	if isBindMount || driverName == DefaultDriverName {
+		path, err = filepath.EvalSymlinks(path)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	v := &Volume{
 		ID:          id,
+		Name:        name,
+		DriverName:  driverName,
 		Path:        path,
 		repository:  r,
 		Writable:    writable,
@@ -87,22 +113,31 @@ func (r *Repository) restore() error {
 
 	for _, v := range dir {
 		id := v.Name()
-		path, err := r.driver.Get(id, "")
-		if err != nil {
-			log.Debugf("Could not find volume for %s: %v", id, err)
-			continue
-		}
 		vol := &Volume{
 			ID:         id,
 			configPath: r.configPath + "/" + id,
 			containers: make(map[string]struct{}),
-			Path:       path,
 		}
-		if err := vol.FromDisk(); err != nil {
-			if !os.IsNotExist(err) {
-				log.Debugf("Error restoring volume: %v", err)
+		err := vol.FromDisk()
+		if err != nil && !os.IsNotExist(err) {
+			log.Debugf("Error restoring volume: %v", err)
+			continue
+		}
+		// Volumes of a volume driver are not stored on the graph driver
+		if !vol.isDriverVolume() {
+			path, err := r.driver.Get(id, "")
+			if err != nil {
+				log.Debugf("Could not find volume for %s: %v", id, err)
 				continue
 			}
+			if vol.Path == "" {
+				vol.Path = path
+			}
+			if !vol.IsBindMount && vol.DriverName == "" {
+				vol.DriverName = DefaultDriverName
+			}
+		}
+		if os.IsNotExist(err) {
 			if err := vol.initialize(); err != nil {
 				log.Debugf("%s", err)
 				continue
@@ -123,6 +158,9 @@ func (r *Repository) Get(path string) *Volume {
 }
 
 func (r *Repository) get(path string) *Volume {
+	if v, exists := r.volumes[filepath.Clean(path)]; exists {
+		return v
+	}
 	path, err := filepath.EvalSymlinks(path)
 	if err != nil {
 		return nil
@@ -140,6 +178,12 @@ func (r *Repository) add(volume *Volume) error {
 	if vol := r.get(volume.Path); vol != nil {
 		return fmt.Errorf("Volume exists: %s", volume.ID)
 	}
+	if volume.Name != "" {
+		if _, exists := r.names[volume.Name]; exists {
+			return fmt.Errorf("Conflict: volume name %s is already in use", volume.Name)
+		}
+		r.names[volume.Name] = volume
+	}
 	r.volumes[volume.Path] = volume
 	return nil
 }
@@ -152,16 +196,15 @@ func (r *Repository) Remove(volume *Volume) {
 
 func (r *Repository) remove(volume *Volume) {
 	delete(r.volumes, volume.Path)
+	if volume.Name != "" {
+		delete(r.names, volume.Name)
+	}
 }
 
 func (r *Repository) Delete(path string) error {
 	r.lock.Lock()
 	defer r.lock.Unlock()
-	path, err := filepath.EvalSymlinks(path)
-	if err != nil {
-		return err
-	}
-	volume := r.get(filepath.Clean(path))
+	volume := r.get(path)
 	if volume == nil {
 		return fmt.Errorf("Volume %s does not exist", path)
 	}
@@ -179,7 +222,15 @@ func (r *Repository) Delete(path string) error {
 		return nil
 	}
 
-	if err := r.driver.Remove(volume.ID); err != nil {
+	if volume.isDriverVolume() {
+		d, err := GetDriver(volume.DriverName)
+		if err != nil {
+			return err
+		}
+		if err := d.Remove(volume.reference()); err != nil {
+			return err
+		}
+	} else if err := r.driver.Remove(volume.ID); err != nil {
 		if !os.IsNotExist(err) {
 			return err
 		}
@@ -202,17 +253,105 @@ func (r *Repository) createNewVolumePath(id string) (string, error) {
 	return path, nil
 }
 
+func createDriverVolume(driverName, name string) (string, error) {
+	d, err := GetDriver(driverName)
+	if err != nil {
+		return "", err
+	}
+	if err := d.Create(name); err != nil {
+		return "", err
+	}
+	path, err := d.Path(name)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", fmt.Errorf("Volume driver %s returned no path for volume %s", driverName, name)
+	}
+	return path, nil
+}
+
 func (r *Repository) FindOrCreateVolume(path string, writable bool) (*Volume, error) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
 	if path == "" {
-		return r.newVolume(path, writable)
+		return r.newVolume("", path, "", writable)
 	}
 
 	if v := r.get(path); v != nil {
 		return v, nil
 	}
 
-	return r.newVolume(path, writable)
+	return r.newVolume("", path, "", writable)
+}
+
+// Create creates a new volume managed by the repository with the volume
+// driver driverName, or the local driver if it is empty. If name is empty
+// the volume is anonymous and can only be referred to by its ID.
+func (r *Repository) Create(name, driverName string) (*Volume, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if name != "" {
+		if !IsValidName(name) {
+			return nil, fmt.Errorf("Invalid volume name (%s), only [a-zA-Z0-9][a-zA-Z0-9_.-] are allowed", name)
+		}
+		if _, exists := r.names[name]; exists {
+			return nil, fmt.Errorf("Conflict: volume name %s is already in use", name)
+		}
+	}
+	return r.newVolume(name, "", driverName, true)
+}
+
+// FindOrCreateNamedVolume returns the volume called name, creating it with
+// the volume driver driverName if it does not exist yet. An empty
+// driverName selects the local driver for new volumes and matches any
+// driver for existing ones.
+func (r *Repository) FindOrCreateNamedVolume(name, driverName string) (*Volume, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if v, exists := r.names[name]; exists {
+		if driverName != "" && driverName != v.DriverName {
+			return nil, fmt.Errorf("Conflict: volume %s already exists with driver %s", name, v.DriverName)
+		}
+		return v, nil
+	}
+	if !IsValidName(name) {
+		return nil, fmt.Errorf("Invalid volume name (%s), only [a-zA-Z0-9][a-zA-Z0-9_.-] are allowed", name)
+	}
+	return r.newVolume(name, "", driverName, true)
+}
+
+// Lookup returns the volume managed by the repository which is called or
+// has the ID ref. Bind mounts are not managed by the repository and are
+// never returned.
+func (r *Repository) Lookup(ref string) (*Volume, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if v, exists := r.names[ref]; exists {
+		return v, nil
+	}
+	for _, v := range r.volumes {
+		if v.ID == ref && !v.IsBindMount {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("No such volume: %s", ref)
+}
+
+// List returns all the volumes managed by the repository.
+func (r *Repository) List() []*Volume {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var vols []*Volume
+	for _, v := range r.volumes {
+		if !v.IsBindMount {
+			vols = append(vols, v)
+		}
+	}
+	return vols
 }
@@ -0,0 +1,87 @@
+package volumes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/daemon/graphdriver/vfs"
+)
+
+func newTestRepository(t *testing.T, root string) *Repository {
+	driver, err := vfs.Init(filepath.Join(root, "vfs"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := NewRepository(filepath.Join(root, "volumes"), driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestRepositoryNamedVolumes(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-volumes-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	repo := newTestRepository(t, root)
+	v, err := repo.Create("data", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Create("data", ""); err == nil {
+		t.Fatal("Expected an error when creating a volume with a name in use")
+	}
+	if _, err := repo.Create("/data", ""); err == nil {
+		t.Fatal("Expected an error when creating a volume with an invalid name")
+	}
+	if _, err := repo.Create("d", ""); err != nil {
+		t.Fatalf("Expected a one-character name to be valid: %s", err)
+	}
+	anon, err := repo.Create("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found, err := repo.FindOrCreateNamedVolume("data", ""); err != nil || found != v {
+		t.Fatalf("Expected to find volume %s, got %v (%v)", v.ID, found, err)
+	}
+	if found, err := repo.Lookup(v.ID); err != nil || found != v {
+		t.Fatalf("Expected to find volume %s by ID, got %v (%v)", v.ID, found, err)
+	}
+	if _, err := repo.Lookup("nothere"); err == nil {
+		t.Fatal("Expected an error looking up a missing volume")
+	}
+
+	// Bind mounts are not listed
+	if _, err := repo.FindOrCreateVolume(root, true); err != nil {
+		t.Fatal(err)
+	}
+	if vols := repo.List(); len(vols) != 3 {
+		t.Fatalf("Expected 3 volumes, got %d", len(vols))
+	}
+
+	// Names survive a restart of the repository
+	repo = newTestRepository(t, root)
+	restored, err := repo.Lookup("data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.ID != v.ID || restored.Path != v.Path {
+		t.Fatalf("Expected restored volume %s at %s, got %s at %s", v.ID, v.Path, restored.ID, restored.Path)
+	}
+
+	if err := repo.Delete(restored.Path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Lookup("data"); err == nil {
+		t.Fatal("Expected the volume to be removed")
+	}
+	if _, err := repo.Lookup(anon.ID); err != nil {
+		t.Fatal(err)
+	}
+}
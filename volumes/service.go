@@ -0,0 +1,142 @@
+package volumes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/engine"
+	"github.com/docker/docker/pkg/parsers/filters"
+)
+
+func (r *Repository) Install(eng *engine.Engine) error {
+	for name, handler := range map[string]engine.Handler{
+		"volume_create":  r.CmdCreate,
+		"volume_inspect": r.CmdInspect,
+		"volume_rm":      r.CmdRm,
+		"volumes":        r.CmdList,
+	} {
+		if err := eng.Register(name, handler); err != nil {
+			return fmt.Errorf("Could not register %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// CmdCreate creates a new volume and prints its name, or its ID when the
+// volume is anonymous. The volume driver is selected with the `Driver`
+// environment variable.
+//
+// Syntax: volume_create [NAME]
+func (r *Repository) CmdCreate(job *engine.Job) engine.Status {
+	if len(job.Args) > 1 {
+		return job.Errorf("Usage: %s [NAME]", job.Name)
+	}
+	var name string
+	if len(job.Args) == 1 {
+		name = job.Args[0]
+	}
+	v, err := r.Create(name, job.Getenv("Driver"))
+	if err != nil {
+		return job.Error(err)
+	}
+	job.Printf("%s\n", v.reference())
+	return engine.StatusOK
+}
+
+// CmdInspect writes the description of the volume called or with the ID
+// NAME to stdout as json.
+//
+// Syntax: volume_inspect NAME
+func (r *Repository) CmdInspect(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("Usage: %s NAME", job.Name)
+	}
+	v, err := r.Lookup(job.Args[0])
+	if err != nil {
+		return job.Error(err)
+	}
+	if _, err := v.env().WriteTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// CmdList writes the list of volumes to stdout. The `dangling` filter
+// restricts the list to the volumes which are (or are not) used by any
+// container.
+//
+// Syntax: volumes
+func (r *Repository) CmdList(job *engine.Job) engine.Status {
+	volFilters, err := filters.FromParam(job.Getenv("filters"))
+	if err != nil {
+		return job.Error(err)
+	}
+	var (
+		filtDangling bool
+		dangling     bool
+	)
+	if values, ok := volFilters["dangling"]; ok {
+		for _, value := range values {
+			switch strings.ToLower(value) {
+			case "true", "1":
+				dangling = true
+			case "false", "0":
+				dangling = false
+			default:
+				return job.Errorf("Invalid filter 'dangling=%s'", value)
+			}
+			filtDangling = true
+		}
+	}
+
+	outs := engine.NewTable("Name", 0)
+	for _, v := range r.List() {
+		if filtDangling && (len(v.Containers()) == 0) != dangling {
+			continue
+		}
+		outs.Add(v.env())
+	}
+	outs.Sort()
+	if _, err := outs.WriteListTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// CmdRm removes the volume called or with the ID NAME. Volumes still in use
+// by a container cannot be removed.
+//
+// Syntax: volume_rm NAME
+func (r *Repository) CmdRm(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("Usage: %s NAME", job.Name)
+	}
+	v, err := r.Lookup(job.Args[0])
+	if err != nil {
+		return job.Error(err)
+	}
+	if containers := v.Containers(); len(containers) > 0 {
+		sort.Strings(containers)
+		return job.Errorf("Conflict: volume %s is in use by containers %s", job.Args[0], strings.Join(containers, ", "))
+	}
+	if err := r.Delete(v.Path); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+func (v *Volume) env() *engine.Env {
+	containers := v.Containers()
+	if containers == nil {
+		containers = []string{}
+	}
+	sort.Strings(containers)
+	out := &engine.Env{}
+	out.Set("Name", v.reference())
+	out.Set("Id", v.ID)
+	out.Set("Driver", v.DriverName)
+	out.Set("Path", v.Path)
+	out.SetList("Containers", containers)
+	return out
+}
@@ -2,6 +2,7 @@ package volumes
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
@@ -15,6 +16,8 @@ import (
 
 type Volume struct {
 	ID          string
+	Name        string
+	DriverName  string
 	Path        string
 	IsBindMount bool
 	Writable    bool
@@ -86,6 +89,53 @@ func (v *Volume) AddContainer(containerId string) {
 	v.lock.Unlock()
 }
 
+// reference returns the name of the volume, or its ID if it has none.
+func (v *Volume) reference() string {
+	if v.Name != "" {
+		return v.Name
+	}
+	return v.ID
+}
+
+// isDriverVolume returns whether the volume was created by a volume driver
+// rather than on the local graph driver.
+func (v *Volume) isDriverVolume() bool {
+	return v.DriverName != "" && v.DriverName != DefaultDriverName
+}
+
+// Mount makes the volume available at its path for a container. It does
+// nothing for volumes which are not created by a volume driver.
+func (v *Volume) Mount() error {
+	if !v.isDriverVolume() {
+		return nil
+	}
+	d, err := GetDriver(v.DriverName)
+	if err != nil {
+		return err
+	}
+	path, err := d.Mount(v.reference())
+	if err != nil {
+		return err
+	}
+	if filepath.Clean(path) != v.Path {
+		d.Unmount(v.reference())
+		return fmt.Errorf("Volume driver %s mounted volume %s at %s instead of %s", v.DriverName, v.reference(), path, v.Path)
+	}
+	return nil
+}
+
+// Unmount releases the volume after it was mounted for a container.
+func (v *Volume) Unmount() error {
+	if !v.isDriverVolume() {
+		return nil
+	}
+	d, err := GetDriver(v.DriverName)
+	if err != nil {
+		return err
+	}
+	return d.Unmount(v.reference())
+}
+
 func (v *Volume) createIfNotExist() error {
 	if stat, err := os.Stat(v.Path); err != nil && os.IsNotExist(err) {
 		if stat.IsDir() {
@@ -108,8 +158,10 @@ func (v *Volume) initialize() error {
 	v.lock.Lock()
 	defer v.lock.Unlock()
 
-	if err := v.createIfNotExist(); err != nil {
-		return err
+	if !v.isDriverVolume() {
+		if err := v.createIfNotExist(); err != nil {
+			return err
+		}
 	}
 
 	if err := os.MkdirAll(v.configPath, 0755); err != nil {